@@ -1,52 +1,91 @@
 package main
 
 import (
+	"apigateway/config"
+	"apigateway/internal/auth"
 	"apigateway/internal/handler"
+	"apigateway/internal/middleware"
+	"foodstore/logging"
 
 	menuPB "apigateway/proto/menu"
 	orderPB "apigateway/proto/order"
+	paymentPB "apigateway/proto/payment"
 	userPB "apigateway/proto/user"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	"log"
+	"github.com/go-redis/redis/v8"
+	"log/slog"
 	"net/http"
 
 	"google.golang.org/grpc"
 )
 
 func main() {
-	r := gin.Default()
+	logging.Setup("apigateway")
+	cfg := config.LoadConfig()
+	if cfg.JWTHMACKeys == "" && cfg.JWTPublicKeysDir == "" {
+		slog.Warn("no JWT keys configured, using the insecure development key")
+	} else {
+		keys, err := auth.NewKeySet(auth.KeyConfig{
+			Algorithm:     cfg.JWTAlgorithm,
+			HMACKeys:      cfg.JWTHMACKeys,
+			PublicKeysDir: cfg.JWTPublicKeysDir,
+		})
+		if err != nil {
+			logging.Fatal("failed to load JWT keys", "err", err)
+		}
+		auth.SetKeySet(keys)
+	}
+
+	auth.SetRevocationList(&auth.RevocationList{
+		Cache: redis.NewClient(&redis.Options{Addr: cfg.RedisAddr}),
+	})
+
+	r := gin.New()
+	r.Use(gin.Recovery(), middleware.RequestLogger())
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		logging.Fatal("invalid TRUSTED_PROXIES", "err", err)
+	}
 
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:8082"},
 		AllowMethods:     []string{"GET", "POST", "PATCH", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Authorization", "Content-Type"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Authorization", "Content-Type", "X-Request-ID", "Idempotency-Key"},
+		ExposeHeaders:    []string{"Content-Length", "Retry-After", "X-Request-ID", "Idempotent-Replayed"},
 		AllowCredentials: true,
 	}))
-	menuConn, err := grpc.Dial("localhost:50051", grpc.WithInsecure())
+	clientInterceptor := grpc.WithUnaryInterceptor(logging.UnaryClientInterceptor())
+	menuConn, err := grpc.Dial("localhost:50051", grpc.WithInsecure(), clientInterceptor)
+	if err != nil {
+		logging.Fatal("failed to connect to MenuService", "err", err)
+	}
+	orderConn, err := grpc.Dial("localhost:50053", grpc.WithInsecure(), clientInterceptor)
 	if err != nil {
-		log.Fatalf("Failed to connect to MenuService: %v", err)
+		logging.Fatal("failed to connect to OrderService", "err", err)
 	}
-	orderConn, err := grpc.Dial("localhost:50053", grpc.WithInsecure())
+	userConn, err := grpc.Dial("localhost:50052", grpc.WithInsecure(), clientInterceptor)
 	if err != nil {
-		log.Fatalf("Failed to connect to OrderService: %v", err)
+		logging.Fatal("failed to connect to UserService", "err", err)
 	}
-	userConn, err := grpc.Dial("localhost:50052", grpc.WithInsecure())
+	paymentConn, err := grpc.Dial("localhost:50054", grpc.WithInsecure(), clientInterceptor)
 	if err != nil {
-		log.Fatalf("Failed to connect to UserService: %v", err)
+		logging.Fatal("failed to connect to PaymentService", "err", err)
 	}
 
 	menuClient := menuPB.NewMenuServiceClient(menuConn)
 	orderClient := orderPB.NewOrderServiceClient(orderConn)
 	userClient := userPB.NewUserServiceClient(userConn)
+	paymentClient := paymentPB.NewPaymentServiceClient(paymentConn)
 
 	handler.InitMenuRoutes(r, menuClient)
 	handler.InitOrderRoutes(r, orderClient)
+	handler.InitCartRoutes(r, orderPB.NewCartServiceClient(orderConn))
 	handler.InitUserRoutes(r, userClient)
+	handler.InitPaymentRoutes(r, paymentClient)
+	handler.InitJWKSRoutes(r)
 
-	log.Println("API Gateway started on :8080")
+	slog.Info("API Gateway started", "addr", ":8080")
 	if err := http.ListenAndServe(":8080", r); err != nil {
-		log.Fatalf("API Gateway failed: %v", err)
+		logging.Fatal("API Gateway failed", "err", err)
 	}
 }
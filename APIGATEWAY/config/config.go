@@ -0,0 +1,43 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+type Config struct {
+	JWTAlgorithm     string
+	JWTHMACKeys      string
+	JWTPublicKeysDir string
+	RedisAddr        string
+	// TrustedProxies may set X-Forwarded-For; without any the client IP is
+	// the address of the connection.
+	TrustedProxies []string
+}
+
+func LoadConfig() *Config {
+	return &Config{
+		JWTAlgorithm:     getEnv("JWT_ALG", "HS256"),
+		JWTHMACKeys:      getEnv("JWT_HMAC_KEYS", ""),
+		JWTPublicKeysDir: getEnv("JWT_PUBLIC_KEYS_DIR", ""),
+		RedisAddr:        getEnv("REDIS_ADDR", "localhost:6379"),
+		TrustedProxies:   getList("TRUSTED_PROXIES"),
+	}
+}
+
+func getList(key string) []string {
+	var values []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+func getEnv(key, defaultValue string) string {
+	if val, exists := os.LookupEnv(key); exists {
+		return val
+	}
+	return defaultValue
+}
@@ -2,10 +2,20 @@ package auth
 
 import (
 	"github.com/golang-jwt/jwt/v5"
-	"time"
 )
 
-var jwtKey = []byte("super_secret_key")
+var keys = NewDevKeySet()
+
+// SetKeySet replaces the development key set used by ParseToken with the
+// configured one.
+func SetKeySet(ks *KeySet) {
+	keys = ks
+}
+
+// Keys returns the key set tokens are currently verified with.
+func Keys() *KeySet {
+	return keys
+}
 
 type Claims struct {
 	UserID string `json:"user_id"`
@@ -13,24 +23,9 @@ type Claims struct {
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID, role string) (string, error) {
-	claims := &Claims{
-		UserID: userID,
-		Role:   role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-		},
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtKey)
-}
-
 func ParseToken(tokenStr string) (*Claims, error) {
 	claims := &Claims{}
-	token, err := jwt.ParseWithClaims(tokenStr, claims, func(t *jwt.Token) (interface{}, error) {
-		return jwtKey, nil
-	})
+	token, err := keys.Parse(tokenStr, claims)
 
 	if err != nil || !token.Valid {
 		return nil, err
@@ -0,0 +1,182 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DefaultKeyID is the kid assumed for tokens issued before kid headers were
+// added, and for the development key used when nothing is configured.
+const DefaultKeyID = "default"
+
+const devSecret = "super_secret_key"
+
+// KeyConfig describes where verification keys come from. The gateway never
+// signs tokens, so for RS256 and EdDSA it only needs the public keys
+// User_service signs with.
+//
+// For HS256, HMACKeys holds comma separated kid=secret pairs. For RS256 and
+// EdDSA, PublicKeysDir holds one <kid>.pem public key per active key; keep the
+// previous key there until the tokens it signed have expired.
+type KeyConfig struct {
+	Algorithm     string
+	HMACKeys      string
+	PublicKeysDir string
+}
+
+// KeySet verifies tokens with any key it knows, selected by the kid header.
+type KeySet struct {
+	method     jwt.SigningMethod
+	verifyKeys map[string]interface{}
+}
+
+func NewKeySet(cfg KeyConfig) (*KeySet, error) {
+	alg := strings.ToUpper(cfg.Algorithm)
+	if alg == "" {
+		alg = "HS256"
+	}
+
+	ks := &KeySet{verifyKeys: map[string]interface{}{}}
+	switch alg {
+	case "HS256":
+		ks.method = jwt.SigningMethodHS256
+		if err := ks.loadHMACKeys(cfg); err != nil {
+			return nil, err
+		}
+	case "RS256":
+		ks.method = jwt.SigningMethodRS256
+		if err := ks.loadPublicKeys(cfg.PublicKeysDir); err != nil {
+			return nil, err
+		}
+	case "EDDSA":
+		ks.method = jwt.SigningMethodEdDSA
+		if err := ks.loadPublicKeys(cfg.PublicKeysDir); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", cfg.Algorithm)
+	}
+	return ks, nil
+}
+
+// NewDevKeySet returns the HS256 development key set that matches the secret
+// the services used before keys became configurable.
+func NewDevKeySet() *KeySet {
+	return &KeySet{
+		method:     jwt.SigningMethodHS256,
+		verifyKeys: map[string]interface{}{DefaultKeyID: []byte(devSecret)},
+	}
+}
+
+func (k *KeySet) loadHMACKeys(cfg KeyConfig) error {
+	if cfg.HMACKeys == "" {
+		return errors.New("JWT_HMAC_KEYS is required for HS256")
+	}
+	for _, pair := range strings.Split(cfg.HMACKeys, ",") {
+		kid, secret, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || kid == "" || secret == "" {
+			return fmt.Errorf("invalid JWT_HMAC_KEYS entry %q, want kid=secret", pair)
+		}
+		k.verifyKeys[kid] = []byte(secret)
+	}
+	return nil
+}
+
+// loadPublicKeys reads every <kid>.pem file in dir as a verification key.
+func (k *KeySet) loadPublicKeys(dir string) error {
+	if dir == "" {
+		return fmt.Errorf("JWT_PUBLIC_KEYS_DIR is required for %s", k.method.Alg())
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no public keys found in %s", dir)
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("read public key %s: %w", file, err)
+		}
+		var key interface{}
+		if k.method == jwt.SigningMethodRS256 {
+			key, err = jwt.ParseRSAPublicKeyFromPEM(data)
+		} else {
+			key, err = jwt.ParseEdPublicKeyFromPEM(data)
+		}
+		if err != nil {
+			return fmt.Errorf("parse public key %s: %w", file, err)
+		}
+		k.verifyKeys[strings.TrimSuffix(filepath.Base(file), ".pem")] = key
+	}
+	return nil
+}
+
+func (k *KeySet) Parse(tokenStr string, claims jwt.Claims) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenStr, claims, k.keyFunc, jwt.WithValidMethods([]string{k.method.Alg()}))
+}
+
+func (k *KeySet) keyFunc(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+	if kid == "" {
+		kid = DefaultKeyID
+	}
+	key, ok := k.verifyKeys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	return key, nil
+}
+
+// JWK is a public key in JSON Web Key form.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS lists the public verification keys, sorted by kid. Shared HMAC secrets
+// are never published.
+func (k *KeySet) JWKS() []JWK {
+	jwks := []JWK{}
+	for kid, key := range k.verifyKeys {
+		switch key := key.(type) {
+		case *rsa.PublicKey:
+			jwks = append(jwks, JWK{
+				Kty: "RSA",
+				Kid: kid,
+				Use: "sig",
+				Alg: k.method.Alg(),
+				N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			jwks = append(jwks, JWK{
+				Kty: "OKP",
+				Kid: kid,
+				Use: "sig",
+				Alg: k.method.Alg(),
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(key),
+			})
+		}
+	}
+	sort.Slice(jwks, func(i, j int) bool { return jwks[i].Kid < jwks[j].Kid })
+	return jwks
+}
@@ -0,0 +1,93 @@
+package auth_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"apigateway/internal/auth"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func writePublicKey(t *testing.T, dir, kid string, key *rsa.PrivateKey) {
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, kid+".pem"), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func sign(t *testing.T, kid string, key *rsa.PrivateKey) string {
+	claims := &auth.Claims{
+		UserID: "user123",
+		Role:   "user",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestKeySet_RS256Rotation(t *testing.T) {
+	oldKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	newKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	retiredKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	dir := t.TempDir()
+	writePublicKey(t, dir, "2026-01", oldKey)
+	writePublicKey(t, dir, "2026-07", newKey)
+
+	keys, err := auth.NewKeySet(auth.KeyConfig{Algorithm: "RS256", PublicKeysDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth.SetKeySet(keys)
+
+	for _, kid := range []string{"2026-01", "2026-07"} {
+		key := oldKey
+		if kid == "2026-07" {
+			key = newKey
+		}
+		claims, err := auth.ParseToken(sign(t, kid, key))
+		if err != nil || claims.UserID != "user123" {
+			t.Errorf("token signed with %s: claims %v, err %v", kid, claims, err)
+		}
+	}
+
+	if _, err := auth.ParseToken(sign(t, "2025-07", retiredKey)); err == nil {
+		t.Error("token signed with a retired key was accepted")
+	}
+	if _, err := auth.ParseToken(sign(t, "2026-07", oldKey)); err == nil {
+		t.Error("token whose kid does not match its signing key was accepted")
+	}
+
+	jwks := keys.JWKS()
+	if len(jwks) != 2 || jwks[0].Kid != "2026-01" || jwks[1].Kid != "2026-07" || jwks[0].Kty != "RSA" {
+		t.Errorf("unexpected JWKS: %+v", jwks)
+	}
+}
+
+func TestKeySet_HMACKeysAreNotPublished(t *testing.T) {
+	keys, err := auth.NewKeySet(auth.KeyConfig{HMACKeys: "a=first_secret,b=second_secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jwks := keys.JWKS(); len(jwks) != 0 {
+		t.Errorf("HMAC secrets published: %+v", jwks)
+	}
+}
@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// RevocationList reads the revoked:* keys User_service writes on logout.
+type RevocationList struct {
+	Cache *redis.Client
+}
+
+var revocations *RevocationList
+
+// SetRevocationList makes IsRevoked consult r. Without one, no token is
+// considered revoked.
+func SetRevocationList(r *RevocationList) {
+	revocations = r
+}
+
+// IsRevoked reports whether the token was revoked by id, or issued before the
+// user last logged out of all sessions.
+func IsRevoked(ctx context.Context, claims *Claims) (bool, error) {
+	if revocations == nil {
+		return false, nil
+	}
+
+	if claims.ID != "" {
+		n, err := revocations.Cache.Exists(ctx, "revoked:jti:"+claims.ID).Result()
+		if err != nil {
+			return false, err
+		}
+		if n > 0 {
+			return true, nil
+		}
+	}
+
+	revokedAt, err := revocations.Cache.Get(ctx, "revoked:user:"+claims.UserID).Result()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	ts, err := strconv.ParseInt(revokedAt, 10, 64)
+	if err != nil {
+		return false, err
+	}
+	return claims.IssuedAt == nil || claims.IssuedAt.Unix() <= ts, nil
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"apigateway/internal/middleware"
+	"net/http"
+
+	orderPB "apigateway/proto/order"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/status"
+)
+
+type cartItemRequest struct {
+	MenuItemID string `json:"menu_item_id" binding:"required"`
+	Quantity   int32  `json:"quantity"`
+}
+
+type cartQuantityRequest struct {
+	Quantity *int32 `json:"quantity" binding:"required"`
+}
+
+// InitCartRoutes exposes the cart of the signed in user. Every change
+// responds with the whole cart priced at current menu prices.
+func InitCartRoutes(r *gin.Engine, client orderPB.CartServiceClient) {
+	protected := r.Group("/cart")
+	protected.Use(middleware.JWTAuthMiddleware())
+
+	protected.GET("", func(c *gin.Context) {
+		res, err := client.GetCart(middleware.OutgoingContext(c), &orderPB.GetCartRequest{})
+		respondCart(c, res, err)
+	})
+
+	protected.POST("/items", func(c *gin.Context) {
+		var req cartItemRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		res, err := client.AddCartItem(middleware.OutgoingContext(c), &orderPB.AddCartItemRequest{
+			MenuItemId: req.MenuItemID,
+			Quantity:   req.Quantity,
+		})
+		respondCart(c, res, err)
+	})
+
+	protected.PUT("/items/:itemId", func(c *gin.Context) {
+		var req cartQuantityRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		res, err := client.UpdateCartItem(middleware.OutgoingContext(c), &orderPB.UpdateCartItemRequest{
+			MenuItemId: c.Param("itemId"),
+			Quantity:   *req.Quantity,
+		})
+		respondCart(c, res, err)
+	})
+
+	protected.DELETE("/items/:itemId", func(c *gin.Context) {
+		res, err := client.RemoveCartItem(middleware.OutgoingContext(c), &orderPB.RemoveCartItemRequest{
+			MenuItemId: c.Param("itemId"),
+		})
+		respondCart(c, res, err)
+	})
+
+	protected.DELETE("", func(c *gin.Context) {
+		res, err := client.ClearCart(middleware.OutgoingContext(c), &orderPB.ClearCartRequest{})
+		respondCart(c, res, err)
+	})
+
+	protected.POST("/checkout", func(c *gin.Context) {
+		// The body with the checkout details is optional for older clients.
+		var req orderPB.CheckoutCartRequest
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		res, err := client.CheckoutCart(middleware.OutgoingContext(c), &req)
+		if err != nil {
+			cartError(c, err)
+			return
+		}
+		markReplayed(c, res.Replayed)
+		c.JSON(http.StatusOK, gin.H{"order_id": res.OrderId})
+	})
+}
+
+func respondCart(c *gin.Context, res *orderPB.CartResponse, err error) {
+	if err != nil {
+		cartError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res.Cart)
+}
+
+// cartError reports items that cannot be ordered and invalid checkout
+// details like order creation does.
+func cartError(c *gin.Context, err error) {
+	st := status.Convert(err)
+	if len(fieldViolations(st)) > 0 {
+		orderRejected(c, st)
+		return
+	}
+	c.JSON(httpStatus(err), gin.H{"error": st.Message()})
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// httpStatus picks the HTTP status for a gRPC error, falling back to 500 for
+// codes without a more specific meaning.
+func httpStatus(err error) int {
+	switch status.Code(err) {
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.FailedPrecondition, codes.Aborted, codes.AlreadyExists:
+		return http.StatusConflict
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+type fieldViolation struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// fieldViolations collects the BadRequest details attached to a gRPC status.
+func fieldViolations(st *status.Status) []fieldViolation {
+	var violations []fieldViolation
+	for _, detail := range st.Details() {
+		br, ok := detail.(*errdetails.BadRequest)
+		if !ok {
+			continue
+		}
+		for _, v := range br.FieldViolations {
+			violations = append(violations, fieldViolation{Field: v.Field, Reason: v.Description})
+		}
+	}
+	return violations
+}
+
+// retryAfter returns the delay from the RetryInfo detail of a gRPC status,
+// rounded up to whole seconds as the Retry-After header wants.
+func retryAfter(st *status.Status) (int64, bool) {
+	for _, detail := range st.Details() {
+		if ri, ok := detail.(*errdetails.RetryInfo); ok && ri.RetryDelay != nil {
+			d := ri.RetryDelay.AsDuration()
+			return int64((d + time.Second - 1) / time.Second), true
+		}
+	}
+	return 0, false
+}
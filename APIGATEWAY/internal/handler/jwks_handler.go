@@ -0,0 +1,16 @@
+package handler
+
+import (
+	"apigateway/internal/auth"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InitJWKSRoutes publishes the public keys tokens are verified with, so other
+// consumers can verify User_service tokens without sharing a secret.
+func InitJWKSRoutes(r *gin.Engine) {
+	r.GET("/.well-known/jwks.json", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"keys": auth.Keys().JWKS()})
+	})
+}
@@ -23,7 +23,9 @@ func InitMenuRoutes(r *gin.Engine, client menuPB.MenuServiceClient) {
 		c.JSON(http.StatusOK, res.Items)
 	})
 
-	protected.POST("", func(c *gin.Context) {
+	adminOnly := middleware.RequireRole(middleware.RoleAdmin)
+
+	protected.POST("", adminOnly, func(c *gin.Context) {
 		var req menuPB.CreateMenuItemRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -81,7 +83,7 @@ func InitMenuRoutes(r *gin.Engine, client menuPB.MenuServiceClient) {
 		})
 	})
 
-	protected.PATCH("/:id", func(c *gin.Context) {
+	protected.PATCH("/:id", adminOnly, func(c *gin.Context) {
 		id := c.Param("id")
 		var req menuPB.UpdateMenuItemRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
@@ -97,7 +99,7 @@ func InitMenuRoutes(r *gin.Engine, client menuPB.MenuServiceClient) {
 		c.JSON(http.StatusOK, gin.H{"message": res.Message})
 	})
 
-	protected.DELETE("/:id", func(c *gin.Context) {
+	protected.DELETE("/:id", adminOnly, func(c *gin.Context) {
 		id := c.Param("id")
 		res, err := client.DeleteMenuItem(c, &menuPB.DeleteMenuItemRequest{Id: id})
 		if err != nil {
@@ -3,11 +3,75 @@ package handler
 import (
 	"apigateway/internal/middleware"
 	"net/http"
+	"strings"
 
 	orderPB "apigateway/proto/order"
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+type invalidItem struct {
+	ItemID string `json:"item_id"`
+	Reason string `json:"reason"`
+}
+
+// fulfillmentPrefix starts the violations Order_service reports for checkout
+// details rather than order lines; promoCodeField is the one it reports for
+// an unknown promo code.
+const (
+	fulfillmentPrefix = "fulfillment."
+	promoCodeField    = "promo_code"
+)
+
+// invalidItems lists the order lines Order_service refused, keyed by menu item id.
+func invalidItems(st *status.Status) []invalidItem {
+	items := []invalidItem{}
+	for _, v := range fieldViolations(st) {
+		if !strings.HasPrefix(v.Field, fulfillmentPrefix) && v.Field != promoCodeField {
+			items = append(items, invalidItem{ItemID: v.Field, Reason: v.Reason})
+		}
+	}
+	return items
+}
+
+// invalidFields lists the checkout details Order_service refused, such as
+// "phone", "address" or "promo_code".
+func invalidFields(st *status.Status) []fieldViolation {
+	fields := []fieldViolation{}
+	for _, v := range fieldViolations(st) {
+		if field, ok := strings.CutPrefix(v.Field, fulfillmentPrefix); ok {
+			fields = append(fields, fieldViolation{Field: field, Reason: v.Reason})
+		} else if v.Field == promoCodeField {
+			fields = append(fields, v)
+		}
+	}
+	return fields
+}
+
+// orderRejected answers 422 for an order refused because of its lines or its
+// checkout details.
+func orderRejected(c *gin.Context, st *status.Status) {
+	c.JSON(http.StatusUnprocessableEntity, gin.H{
+		"error":          st.Message(),
+		"invalid_items":  invalidItems(st),
+		"invalid_fields": invalidFields(st),
+	})
+}
+
+// Clients send idempotencyKeyHeader to make retries of a create request safe;
+// replayedHeader tells them the response belongs to an earlier request.
+const (
+	idempotencyKeyHeader = "Idempotency-Key"
+	replayedHeader       = "Idempotent-Replayed"
+)
+
+func markReplayed(c *gin.Context, replayed bool) {
+	if replayed {
+		c.Header(replayedHeader, "true")
+	}
+}
+
 func InitOrderRoutes(r *gin.Engine, client orderPB.OrderServiceClient) {
 	protected := r.Group("/orders")
 	protected.Use(middleware.JWTAuthMiddleware())
@@ -18,26 +82,45 @@ func InitOrderRoutes(r *gin.Engine, client orderPB.OrderServiceClient) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		res, err := client.CreateOrder(c, &req)
+		if !middleware.IsAdmin(c) {
+			req.UserId = c.GetString("user_id")
+		}
+		req.IdempotencyKey = c.GetHeader(idempotencyKeyHeader)
+		res, err := client.CreateOrder(middleware.OutgoingContext(c), &req)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			st := status.Convert(err)
+			switch st.Code() {
+			case codes.InvalidArgument, codes.FailedPrecondition:
+				orderRejected(c, st)
+			case codes.AlreadyExists, codes.Aborted:
+				c.JSON(http.StatusConflict, gin.H{"error": st.Message()})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			}
 			return
 		}
+		markReplayed(c, res.Replayed)
 		c.JSON(http.StatusOK, gin.H{"order_id": res.Id})
 	})
 
+	adminOnly := middleware.RequireRole(middleware.RoleAdmin)
+
 	protected.GET("/:id", func(c *gin.Context) {
 		id := c.Param("id")
-		res, err := client.GetOrder(c, &orderPB.GetOrderRequest{Id: id})
+		res, err := client.GetOrder(middleware.OutgoingContext(c), &orderPB.GetOrderRequest{Id: id})
 		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			c.JSON(httpStatus(err), gin.H{"error": status.Convert(err).Message()})
+			return
+		}
+		if res.Order.UserId != c.GetString("user_id") && !middleware.IsAdmin(c) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
 			return
 		}
 		c.JSON(http.StatusOK, res.Order)
 	})
 
-	protected.GET("", func(c *gin.Context) {
-		res, err := client.ListOrders(c, &orderPB.ListOrdersRequest{
+	protected.GET("", adminOnly, func(c *gin.Context) {
+		res, err := client.ListOrders(middleware.OutgoingContext(c), &orderPB.ListOrdersRequest{
 			Limit: 10,
 			Skip:  0,
 		})
@@ -47,10 +130,10 @@ func InitOrderRoutes(r *gin.Engine, client orderPB.OrderServiceClient) {
 		}
 		c.JSON(http.StatusOK, res.Orders)
 	})
-	protected.GET("/user/:userId", func(c *gin.Context) {
+	protected.GET("/user/:userId", middleware.RequireSelfOrAdmin("userId"), func(c *gin.Context) {
 		userId := c.Param("userId")
 
-		res, err := client.ListOrdersByUser(c, &orderPB.ListOrdersByUserRequest{
+		res, err := client.ListOrdersByUser(middleware.OutgoingContext(c), &orderPB.ListOrdersByUserRequest{
 			UserId: userId,
 			Limit:  100,
 			Skip:   0,
@@ -62,7 +145,7 @@ func InitOrderRoutes(r *gin.Engine, client orderPB.OrderServiceClient) {
 
 		c.JSON(http.StatusOK, res.Orders)
 	})
-	protected.PUT("/:id", func(c *gin.Context) {
+	protected.PUT("/:id", adminOnly, func(c *gin.Context) {
 		id := c.Param("id")
 		var req orderPB.UpdateOrderRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
@@ -70,15 +153,15 @@ func InitOrderRoutes(r *gin.Engine, client orderPB.OrderServiceClient) {
 			return
 		}
 		req.Id = id
-		res, err := client.UpdateOrder(c, &req)
+		res, err := client.UpdateOrder(middleware.OutgoingContext(c), &req)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(httpStatus(err), gin.H{"error": status.Convert(err).Message()})
 			return
 		}
 		c.JSON(http.StatusOK, gin.H{"message": res.Message})
 	})
 
-	protected.PATCH("/:id/status", func(c *gin.Context) {
+	protected.PATCH("/:id/status", adminOnly, func(c *gin.Context) {
 		id := c.Param("id")
 		var req orderPB.PatchOrderStatusRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
@@ -86,17 +169,17 @@ func InitOrderRoutes(r *gin.Engine, client orderPB.OrderServiceClient) {
 			return
 		}
 		req.Id = id
-		res, err := client.PatchOrderStatus(c, &req)
+		res, err := client.PatchOrderStatus(middleware.OutgoingContext(c), &req)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(httpStatus(err), gin.H{"error": status.Convert(err).Message()})
 			return
 		}
 		c.JSON(http.StatusOK, gin.H{"message": res.Message})
 	})
 
-	protected.DELETE("/:id", func(c *gin.Context) {
+	protected.DELETE("/:id", adminOnly, func(c *gin.Context) {
 		id := c.Param("id")
-		res, err := client.DeleteOrder(c, &orderPB.DeleteOrderRequest{Id: id})
+		res, err := client.DeleteOrder(middleware.OutgoingContext(c), &orderPB.DeleteOrderRequest{Id: id})
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"apigateway/internal/middleware"
+	"net/http"
+
+	paymentPB "apigateway/proto/payment"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/status"
+)
+
+// paymentDeclined is the status PaymentService gives payments the provider
+// refused.
+const paymentDeclined = "Declined"
+
+func InitPaymentRoutes(r *gin.Engine, client paymentPB.PaymentServiceClient) {
+	protected := r.Group("/payments")
+	protected.Use(middleware.JWTAuthMiddleware())
+
+	// POST /payments pays for one of the caller's pending orders. A declined
+	// payment method answers 402 with the declined payment, so the client
+	// can show the reason and let the user try another method.
+	protected.POST("", func(c *gin.Context) {
+		var req paymentPB.AuthorizePaymentRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		req.IdempotencyKey = c.GetHeader(idempotencyKeyHeader)
+		res, err := client.AuthorizePayment(middleware.OutgoingContext(c), &req)
+		if err != nil {
+			c.JSON(httpStatus(err), gin.H{"error": status.Convert(err).Message()})
+			return
+		}
+		markReplayed(c, res.Replayed)
+		if res.Payment.Status == paymentDeclined {
+			c.JSON(http.StatusPaymentRequired, gin.H{
+				"error":   "payment declined: " + res.Payment.DeclineReason,
+				"payment": res.Payment,
+			})
+			return
+		}
+		c.JSON(http.StatusOK, res.Payment)
+	})
+
+	protected.GET("/:id", func(c *gin.Context) {
+		res, err := client.GetPayment(middleware.OutgoingContext(c), &paymentPB.GetPaymentRequest{Id: c.Param("id")})
+		if err != nil {
+			c.JSON(httpStatus(err), gin.H{"error": status.Convert(err).Message()})
+			return
+		}
+		c.JSON(http.StatusOK, res.Payment)
+	})
+
+	protected.POST("/:id/capture", func(c *gin.Context) {
+		res, err := client.CapturePayment(middleware.OutgoingContext(c), &paymentPB.CapturePaymentRequest{Id: c.Param("id")})
+		if err != nil {
+			c.JSON(httpStatus(err), gin.H{"error": status.Convert(err).Message()})
+			return
+		}
+		c.JSON(http.StatusOK, res.Payment)
+	})
+
+	protected.POST("/:id/refund", middleware.RequireRole(middleware.RoleAdmin), func(c *gin.Context) {
+		var req paymentPB.RefundPaymentRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		req.Id = c.Param("id")
+		res, err := client.RefundPayment(middleware.OutgoingContext(c), &req)
+		if err != nil {
+			c.JSON(httpStatus(err), gin.H{"error": status.Convert(err).Message()})
+			return
+		}
+		c.JSON(http.StatusOK, res.Payment)
+	})
+
+	// GET /orders/:id/receipt.pdf regenerates the PDF receipt emailed when
+	// the order was placed. It is served by PaymentService, which renders
+	// the receipts, so it lives next to the payment routes.
+	orders := r.Group("/orders")
+	orders.Use(middleware.JWTAuthMiddleware())
+	orders.GET("/:id/receipt.pdf", func(c *gin.Context) {
+		res, err := client.GetReceipt(middleware.OutgoingContext(c), &paymentPB.GetReceiptRequest{OrderId: c.Param("id")})
+		if err != nil {
+			c.JSON(httpStatus(err), gin.H{"error": status.Convert(err).Message()})
+			return
+		}
+		c.Header("Content-Disposition", `inline; filename="`+res.Filename+`"`)
+		c.Data(http.StatusOK, "application/pdf", res.Pdf)
+	})
+
+	// GET /admin/notifications lists the emails PaymentService sent or is
+	// trying to send (?status=Failed&limit=50&offset=0), newest first;
+	// POST /admin/notifications/:id/resend sends a failed one again.
+	notifications := r.Group("/admin/notifications")
+	notifications.Use(middleware.JWTAuthMiddleware(), middleware.RequireRole(middleware.RoleAdmin))
+	notifications.GET("", func(c *gin.Context) {
+		var query struct {
+			Status string `form:"status"`
+			Limit  int64  `form:"limit"`
+			Offset int64  `form:"offset"`
+		}
+		if err := c.ShouldBindQuery(&query); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		res, err := client.ListNotifications(middleware.OutgoingContext(c), &paymentPB.ListNotificationsRequest{
+			Status: query.Status,
+			Limit:  query.Limit,
+			Offset: query.Offset,
+		})
+		if err != nil {
+			c.JSON(httpStatus(err), gin.H{"error": status.Convert(err).Message()})
+			return
+		}
+		c.JSON(http.StatusOK, res.Notifications)
+	})
+	notifications.POST("/:id/resend", func(c *gin.Context) {
+		res, err := client.ResendNotification(middleware.OutgoingContext(c), &paymentPB.ResendNotificationRequest{Id: c.Param("id")})
+		if err != nil {
+			c.JSON(httpStatus(err), gin.H{"error": status.Convert(err).Message()})
+			return
+		}
+		c.JSON(http.StatusOK, res.Notification)
+	})
+}
@@ -3,10 +3,15 @@ package handler
 import (
 	"apigateway/internal/middleware"
 	"context"
+	"errors"
+	"io"
 	"net/http"
+	"strconv"
 
 	userPB "apigateway/proto/user"
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func InitUserRoutes(r *gin.Engine, client userPB.UserServiceClient) {
@@ -19,7 +24,7 @@ func InitUserRoutes(r *gin.Engine, client userPB.UserServiceClient) {
 
 		res, err := client.Register(context.Background(), &req)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			userError(c, err)
 			return
 		}
 
@@ -33,24 +38,159 @@ func InitUserRoutes(r *gin.Engine, client userPB.UserServiceClient) {
 			return
 		}
 
-		res, err := client.Login(context.Background(), &req)
+		res, err := client.Login(middleware.ClientContext(c), &req)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			st := status.Convert(err)
+			switch st.Code() {
+			case codes.ResourceExhausted:
+				body := gin.H{"error": st.Message()}
+				if seconds, ok := retryAfter(st); ok {
+					c.Header("Retry-After", strconv.FormatInt(seconds, 10))
+					body["retry_after"] = seconds
+				}
+				c.JSON(http.StatusTooManyRequests, body)
+			case codes.Unauthenticated:
+				c.JSON(http.StatusUnauthorized, gin.H{"error": st.Message()})
+			default:
+				c.JSON(httpStatus(err), gin.H{"error": st.Message()})
+			}
 			return
 		}
 
 		c.JSON(http.StatusOK, res)
 	})
+	r.POST("/refresh", func(c *gin.Context) {
+		var req userPB.RefreshTokenRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		res, err := client.RefreshToken(c, &req)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": status.Convert(err).Message()})
+			return
+		}
+
+		c.JSON(http.StatusOK, res)
+	})
+
+	r.POST("/logout", middleware.JWTAuthMiddleware(), func(c *gin.Context) {
+		var req userPB.LogoutRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		req.AccessToken = c.GetString("token")
+
+		res, err := client.Logout(c, &req)
+		if err != nil {
+			c.JSON(httpStatus(err), gin.H{"error": status.Convert(err).Message()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": res.Message})
+	})
+
+	r.POST("/password/forgot", func(c *gin.Context) {
+		var req userPB.RequestPasswordResetRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		res, err := client.RequestPasswordReset(c, &req)
+		if err != nil {
+			userError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": res.Message})
+	})
+
+	r.POST("/password/reset", func(c *gin.Context) {
+		var req userPB.ResetPasswordRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		res, err := client.ResetPassword(c, &req)
+		if err != nil {
+			userError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": res.Message})
+	})
+
 	protected := r.Group("/users")
-	protected.Use(middleware.JWTAuthMiddleware())
+	protected.Use(middleware.JWTAuthMiddleware(), middleware.RequireSelfOrAdmin("id"))
 
 	protected.GET("/:id", func(c *gin.Context) {
 		id := c.Param("id")
-		res, err := client.GetUser(c, &userPB.GetUserRequest{Id: id})
+		res, err := client.GetUser(middleware.OutgoingContext(c), &userPB.GetUserRequest{Id: id})
 		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			userError(c, err)
 			return
 		}
 		c.JSON(http.StatusOK, res.User)
 	})
+
+	protected.PUT("/:id", func(c *gin.Context) {
+		var req userPB.UpdateProfileRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		req.Id = c.Param("id")
+
+		res, err := client.UpdateProfile(middleware.OutgoingContext(c), &req)
+		if err != nil {
+			userError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, res.User)
+	})
+
+	protected.PUT("/:id/password", func(c *gin.Context) {
+		var req userPB.ChangePasswordRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		req.Id = c.Param("id")
+
+		res, err := client.ChangePassword(middleware.OutgoingContext(c), &req)
+		if err != nil {
+			userError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": res.Message})
+	})
+
+	protected.DELETE("/:id", func(c *gin.Context) {
+		// Admins deleting another user's account send no body.
+		var req userPB.DeleteAccountRequest
+		if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		req.Id = c.Param("id")
+
+		res, err := client.DeleteAccount(middleware.OutgoingContext(c), &req)
+		if err != nil {
+			userError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": res.Message})
+	})
+}
+
+// userError writes a User_service error with its field violations, if any.
+func userError(c *gin.Context, err error) {
+	st := status.Convert(err)
+	body := gin.H{"error": st.Message()}
+	if violations := fieldViolations(st); len(violations) > 0 {
+		body["fields"] = violations
+	}
+	c.JSON(httpStatus(err), body)
 }
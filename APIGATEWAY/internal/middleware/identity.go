@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/metadata"
+)
+
+// Metadata keys the backend services read the authenticated caller from.
+const (
+	UserIDMetadataKey   = "x-user-id"
+	RoleMetadataKey     = "x-user-role"
+	ClientIPMetadataKey = "x-client-ip"
+)
+
+// OutgoingContext returns the request context with the caller set by
+// JWTAuthMiddleware attached as gRPC metadata, so downstream services never
+// have to trust ids sent in the request body.
+func OutgoingContext(c *gin.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ClientContext(c),
+		UserIDMetadataKey, c.GetString("user_id"),
+		RoleMetadataKey, c.GetString("role"),
+	)
+}
+
+// ClientContext returns the request context with the client IP attached as
+// gRPC metadata, for calls made before the caller is authenticated.
+func ClientContext(c *gin.Context) context.Context {
+	return metadata.AppendToOutgoingContext(c.Request.Context(), ClientIPMetadataKey, c.ClientIP())
+}
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"apigateway/internal/auth"
+	"log/slog"
 	"net/http"
 	"strings"
 
@@ -24,7 +25,20 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// Redis being down must not lock everyone out; access tokens are
+		// short-lived, so a missed revocation expires on its own.
+		revoked, err := auth.IsRevoked(c.Request.Context(), claims)
+		if err != nil {
+			slog.WarnContext(c.Request.Context(), "failed to check token revocation", "err", err)
+		}
+		if revoked {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
+			return
+		}
+
 		c.Set("user_id", claims.UserID)
+		c.Set("role", claims.Role)
+		c.Set("token", tokenString)
 		c.Next()
 	}
 }
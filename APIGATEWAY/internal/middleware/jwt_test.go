@@ -0,0 +1,71 @@
+package middleware_test
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"apigateway/internal/auth"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func useRevocationList(t *testing.T) *miniredis.Miniredis {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	auth.SetRevocationList(&auth.RevocationList{Cache: client})
+	t.Cleanup(func() {
+		auth.SetRevocationList(nil)
+		client.Close()
+	})
+	return mr
+}
+
+func claimsAt(userID, jti string, issuedAt time.Time) *auth.Claims {
+	return &auth.Claims{
+		UserID: userID,
+		Role:   "user",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+			ExpiresAt: jwt.NewNumericDate(issuedAt.Add(time.Hour)),
+		},
+	}
+}
+
+func TestJWTAuthMiddleware_RejectsRevokedToken(t *testing.T) {
+	mr := useRevocationList(t)
+	r := newRouter()
+	now := time.Now()
+
+	mr.Set("revoked:jti:logged-out", "1")
+
+	if code := doWithClaims(t, r, http.MethodGet, "/orders/user/u1", claimsAt("u1", "logged-out", now)); code != http.StatusUnauthorized {
+		t.Errorf("revoked token: got %d, want 401", code)
+	}
+	if code := doWithClaims(t, r, http.MethodGet, "/orders/user/u1", claimsAt("u1", "other", now)); code != http.StatusOK {
+		t.Errorf("other token of the same user: got %d, want 200", code)
+	}
+}
+
+func TestJWTAuthMiddleware_LogoutAllRejectsEarlierTokens(t *testing.T) {
+	mr := useRevocationList(t)
+	r := newRouter()
+	logoutAt := time.Now().Add(-time.Minute)
+
+	mr.Set("revoked:user:u1", strconv.FormatInt(logoutAt.Unix(), 10))
+
+	if code := doWithClaims(t, r, http.MethodGet, "/orders/user/u1", claimsAt("u1", "before", logoutAt.Add(-time.Minute))); code != http.StatusUnauthorized {
+		t.Errorf("token issued before logout-all: got %d, want 401", code)
+	}
+	if code := doWithClaims(t, r, http.MethodGet, "/orders/user/u1", claimsAt("u1", "after", logoutAt.Add(time.Second))); code != http.StatusOK {
+		t.Errorf("token issued after logout-all: got %d, want 200", code)
+	}
+	if code := doWithClaims(t, r, http.MethodGet, "/orders/user/u2", claimsAt("u2", "elsewhere", logoutAt.Add(-time.Minute))); code != http.StatusOK {
+		t.Errorf("token of another user: got %d, want 200", code)
+	}
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"foodstore/logging"
+	"log/slog"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestLogger gives every request an id, taken from the X-Request-ID header
+// when the client sent one, returns it in the response and logs the request
+// once it is done. The id reaches the backend services through the request
+// context. Query strings and bodies are not logged.
+func RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(logging.RequestIDKey)
+		if id == "" || len(id) > 64 {
+			id = logging.NewRequestID()
+		}
+		ctx := logging.WithRequestID(c.Request.Context(), id)
+		c.Request = c.Request.WithContext(ctx)
+		c.Header(logging.RequestIDKey, id)
+
+		start := time.Now()
+		c.Next()
+
+		level := slog.LevelInfo
+		switch {
+		case c.Writer.Status() >= 500:
+			level = slog.LevelError
+		case c.Writer.Status() >= 400:
+			level = slog.LevelWarn
+		}
+		slog.Log(ctx, level, "http request",
+			"method", c.Request.Method,
+			"path", c.FullPath(),
+			"status", c.Writer.Status(),
+			"duration_ms", time.Since(start).Milliseconds(),
+			"client_ip", c.ClientIP(),
+			"user_id", c.GetString("user_id"),
+		)
+	}
+}
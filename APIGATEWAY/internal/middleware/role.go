@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const RoleAdmin = "admin"
+
+// RequireRole lets the request through only if the role set by
+// JWTAuthMiddleware is one of roles.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+	}
+}
+
+// RequireSelfOrAdmin lets the request through if the path parameter param is
+// the caller's own user id, or if the caller is an admin.
+func RequireSelfOrAdmin(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if IsAdmin(c) || c.Param(param) == c.GetString("user_id") {
+			c.Next()
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+	}
+}
+
+func IsAdmin(c *gin.Context) bool {
+	return c.GetString("role") == RoleAdmin
+}
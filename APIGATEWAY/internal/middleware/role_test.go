@@ -0,0 +1,79 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"apigateway/internal/auth"
+	"apigateway/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func newRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	protected := r.Group("/", middleware.JWTAuthMiddleware())
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	protected.DELETE("/menu/:id", middleware.RequireRole(middleware.RoleAdmin), ok)
+	protected.GET("/orders/user/:userId", middleware.RequireSelfOrAdmin("userId"), ok)
+	return r
+}
+
+func do(t *testing.T, r *gin.Engine, method, path, userID, role string) int {
+	return doWithClaims(t, r, method, path, &auth.Claims{
+		UserID: userID,
+		Role:   role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	})
+}
+
+func doWithClaims(t *testing.T, r *gin.Engine, method, path string, claims *auth.Claims) int {
+	keys, err := auth.NewKeySet(auth.KeyConfig{HMACKeys: "test=test_secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth.SetKeySet(keys)
+
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	jwtToken.Header["kid"] = "test"
+	token, err := jwtToken.SignedString([]byte("test_secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestRequireRole(t *testing.T) {
+	r := newRouter()
+
+	if code := do(t, r, http.MethodDelete, "/menu/1", "u1", "user"); code != http.StatusForbidden {
+		t.Errorf("customer deleting menu item: got %d, want 403", code)
+	}
+	if code := do(t, r, http.MethodDelete, "/menu/1", "a1", "admin"); code != http.StatusOK {
+		t.Errorf("admin deleting menu item: got %d, want 200", code)
+	}
+}
+
+func TestRequireSelfOrAdmin(t *testing.T) {
+	r := newRouter()
+
+	if code := do(t, r, http.MethodGet, "/orders/user/u1", "u1", "user"); code != http.StatusOK {
+		t.Errorf("owner listing own orders: got %d, want 200", code)
+	}
+	if code := do(t, r, http.MethodGet, "/orders/user/u2", "u1", "user"); code != http.StatusForbidden {
+		t.Errorf("customer listing someone else's orders: got %d, want 403", code)
+	}
+	if code := do(t, r, http.MethodGet, "/orders/user/u2", "a1", "admin"); code != http.StatusOK {
+		t.Errorf("admin listing someone else's orders: got %d, want 200", code)
+	}
+}
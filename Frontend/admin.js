@@ -75,8 +75,11 @@ function loadAdminPanel(token, userId) {
         <p><strong>Order ID:</strong> ${order.id}</p>
         <p><strong>Status:</strong> ${order.status}</p>
         <p><strong>Total:</strong> $${Number(order.total_price || 0).toFixed(2)}</p>
+        ${order.discount ? `<p><strong>Discount:</strong> -$${order.discount.toFixed(2)} (${order.promo_code})</p>` : ""}
+        ${order.delivery_fee ? `<p><strong>Delivery fee:</strong> $${order.delivery_fee.toFixed(2)}</p>` : ""}
         <p><strong>Items:</strong> ${itemNames}</p>
         <p><strong>Date:</strong> ${new Date(order.created_at).toLocaleString()}</p>
+        ${fulfillmentDetails(order.fulfillment)}
         <button onclick="updateOrderStatus('${order.id}')">Update Status</button>
         <button onclick="deleteOrder('${order.id}')">Delete Order</button>
         <hr>
@@ -93,6 +96,26 @@ function loadAdminPanel(token, userId) {
     `;
     }
 
+    // fulfillmentDetails shows what the customer entered at checkout. The
+    // values are typed by customers, so they are escaped.
+    function fulfillmentDetails(f) {
+        if (!f) return "";
+        const when = f.requested_time ? new Date(f.requested_time).toLocaleString() : "As soon as possible";
+        const rows = [
+            ["Fulfillment", f.type === "delivery" ? `Delivery to ${f.address}` : "Pickup"],
+            ["Requested", when],
+            ["Contact", [f.contact_name, f.phone].filter(Boolean).join(", ")],
+        ];
+        if (f.notes) rows.push(["Notes", f.notes]);
+        return rows.map(([label, value]) => `<p><strong>${label}:</strong> ${escapeHTML(value)}</p>`).join("");
+    }
+
+    function escapeHTML(s) {
+        const div = document.createElement("div");
+        div.textContent = s;
+        return div.innerHTML;
+    }
+
     window.prevPageOrders = () => {
         if (currentPage > 1) {
             currentPage--;
@@ -124,7 +147,10 @@ function loadAdminPanel(token, userId) {
     };
 
     window.updateOrderStatus = async (orderId) => {
-        const newStatus = prompt("Enter new status for order:", "Pending");
+        const newStatus = prompt(
+            "Enter new status for order (Preparing, ReadyForPickup, OutForDelivery, Delivered, Cancelled, Refunded). Orders become Confirmed when their payment is captured:",
+            "Preparing"
+        );
         if (!newStatus) return;
         try {
             const res = await fetch(`${API_URL}/orders/${orderId}/status`, {
@@ -135,12 +161,15 @@ function loadAdminPanel(token, userId) {
                 },
                 body: JSON.stringify({ status: newStatus }),
             });
-            if (!res.ok) throw new Error("Failed to update order");
+            if (!res.ok) {
+                const data = await res.json().catch(() => ({}));
+                throw new Error(data.error || "Failed to update order");
+            }
             alert("Order updated");
             await window.loadOrders();
         } catch (err) {
             console.error(err);
-            alert("Error updating order");
+            alert("Error updating order: " + err.message);
         }
     };
 
@@ -6,7 +6,6 @@ document.getElementById("loginForm").addEventListener("submit", async (e) => {
 
     const email = document.getElementById("loginEmail").value;
     const password = document.getElementById("loginPassword").value;
-    console.log(email, password); // ← вот оно
   const res = await fetch(`${API_URL}/login`, {
     method: "POST",
     headers: { "Content-Type": "application/json" },
@@ -17,13 +16,16 @@ document.getElementById("loginForm").addEventListener("submit", async (e) => {
 
   if (res.ok && data.token) {
     localStorage.setItem("token", data.token);
+    localStorage.setItem("refreshToken", data.refresh_token);
     localStorage.setItem("userId", data.user_id);
     window.location.href = "index.html";
 
+  } else if (res.status === 429) {
+    const seconds = Number(res.headers.get("Retry-After") || data.retry_after || 0);
+    alert(`Too many failed attempts. Try again in ${seconds} seconds.`);
   } else {
     alert("Login failed: " + (data.error || data.message));
   }
-    console.log("Login response data:", data);
 });
 document.querySelectorAll('.auth-tab').forEach(tab => {
     tab.addEventListener('click', () => {
@@ -53,6 +55,7 @@ document
         const name = document.getElementById("registerName").value;
         const email = document.getElementById("registerEmail").value;
         const password = document.getElementById("registerPassword").value;
+        const locale = document.getElementById("registerLocale").value;
         const confirmPassword = document.getElementById(
             "registerConfirmPassword"
         ).value;
@@ -64,7 +67,7 @@ document
         const res = await fetch(`${API_URL}/register`, {
             method: "POST",
             headers: { "Content-Type": "application/json" },
-            body: JSON.stringify({ username: name, email, password }),
+            body: JSON.stringify({ username: name, email, password, locale }),
         });
 
         const data = await res.json();
@@ -72,7 +75,28 @@ document
         if (res.ok) {
             alert("Registration successful! Please log in.");
             document.querySelector('[data-tab="login"]').click();
+        } else if (res.status === 409) {
+            alert("An account with this email already exists.");
+        } else if (data.fields) {
+            alert("Registration failed:\n" + data.fields.map((f) => `${f.field}: ${f.reason}`).join("\n"));
         } else {
             alert("Registration failed: " + (data.error || data.message));
         }
     });
+
+document
+    .getElementById("forgotPasswordLink")
+    .addEventListener("click", async (e) => {
+        e.preventDefault();
+
+        const email = prompt("Enter the email of your account:");
+        if (!email) return;
+
+        const res = await fetch(`${API_URL}/password/forgot`, {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({ email }),
+        });
+        const data = await res.json();
+        alert(res.ok ? data.message : "Request failed: " + data.error);
+    });
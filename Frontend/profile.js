@@ -3,7 +3,8 @@ let menuMap = {};
 let currentPage1 = 1;
 const pageSize1 = 4;
 
-document.addEventListener("DOMContentLoaded", () => {
+document.addEventListener("DOMContentLoaded", async () => {
+  await sessionReady;
   const token = localStorage.getItem("token");
   const userId = localStorage.getItem("userId");
 
@@ -15,10 +16,7 @@ document.addEventListener("DOMContentLoaded", () => {
 
   const logoutBtn = document.getElementById("logoutButton");
   if (logoutBtn) {
-    logoutBtn.addEventListener("click", () => {
-      localStorage.clear();
-      window.location.href = "auth.html";
-    });
+    logoutBtn.addEventListener("click", logout);
   }
 
 
@@ -40,6 +38,17 @@ document.addEventListener("DOMContentLoaded", () => {
       const profilePhone = document.getElementById("profilePhone");
       if (profilePhone) profilePhone.innerText = `Phone: ${data.phone || "N/A"}`;
 
+      const address = (data.addresses || [])[0];
+      const profileAddress = document.getElementById("profileAddress");
+      if (profileAddress) {
+        profileAddress.innerText = `Address: ${address ? formatAddress(address) : "N/A"}`;
+      }
+
+      const profileLocale = document.getElementById("profileLocale");
+      if (profileLocale) profileLocale.innerText = `Email language: ${data.locale === "ru" ? "Русский" : "English"}`;
+
+      profile = data;
+
       if (data.role === "admin") {
         if (typeof loadAdminPanel === "function") {
           loadAdminPanel(token, userId);
@@ -143,6 +152,114 @@ document.addEventListener("DOMContentLoaded", () => {
     }
   });
 
+  let profile = {};
+
+  function formatAddress(a) {
+    return [a.street, a.city, a.postal_code].filter(Boolean).join(", ");
+  }
+
+  function fieldErrors(data) {
+    if (!data.fields) return data.error || "Request failed";
+    return data.fields.map((f) => `${f.field}: ${f.reason}`).join("\n");
+  }
+
+  function toggleForm(id, visible) {
+    document.getElementById(id).style.display = visible ? "block" : "none";
+  }
+
+  document.getElementById("editProfileButton").addEventListener("click", () => {
+    const address = (profile.addresses || [])[0] || {};
+    document.getElementById("editUsername").value = profile.username || "";
+    document.getElementById("editEmail").value = profile.email || "";
+    document.getElementById("editPhone").value = profile.phone || "";
+    document.getElementById("editStreet").value = address.street || "";
+    document.getElementById("editCity").value = address.city || "";
+    document.getElementById("editPostalCode").value = address.postal_code || "";
+    document.getElementById("editLocale").value = profile.locale || "en";
+    toggleForm("editProfileForm", true);
+  });
+  document.getElementById("cancelEditProfile").addEventListener("click", () => toggleForm("editProfileForm", false));
+
+  document.getElementById("editProfileForm").addEventListener("submit", async (e) => {
+    e.preventDefault();
+    const street = document.getElementById("editStreet").value.trim();
+    const city = document.getElementById("editCity").value.trim();
+    const postalCode = document.getElementById("editPostalCode").value.trim();
+    // Only the first address is editable here; the others are kept as they are.
+    const addresses = [...(profile.addresses || [])];
+    if (street || city || postalCode) {
+      addresses[0] = { label: "Home", street, city, postal_code: postalCode };
+    } else {
+      addresses.shift();
+    }
+
+    const res = await fetch(`${API_URL}/users/${userId}`, {
+      method: "PUT",
+      headers: { "Content-Type": "application/json", Authorization: `Bearer ${token}` },
+      body: JSON.stringify({
+        username: document.getElementById("editUsername").value,
+        email: document.getElementById("editEmail").value,
+        phone: document.getElementById("editPhone").value,
+        addresses,
+        locale: document.getElementById("editLocale").value,
+      }),
+    });
+    const data = await res.json();
+    if (!res.ok) {
+      alert("Failed to update profile:\n" + fieldErrors(data));
+      return;
+    }
+    toggleForm("editProfileForm", false);
+    loadProfile();
+  });
+
+  document.getElementById("changePasswordButton").addEventListener("click", () => toggleForm("changePasswordForm", true));
+  document.getElementById("cancelChangePassword").addEventListener("click", () => toggleForm("changePasswordForm", false));
+
+  document.getElementById("changePasswordForm").addEventListener("submit", async (e) => {
+    e.preventDefault();
+    const newPassword = document.getElementById("newPassword").value;
+    if (newPassword !== document.getElementById("confirmNewPassword").value) {
+      alert("Passwords do not match.");
+      return;
+    }
+
+    const res = await fetch(`${API_URL}/users/${userId}/password`, {
+      method: "PUT",
+      headers: { "Content-Type": "application/json", Authorization: `Bearer ${token}` },
+      body: JSON.stringify({
+        current_password: document.getElementById("currentPassword").value,
+        new_password: newPassword,
+      }),
+    });
+    const data = await res.json();
+    if (!res.ok) {
+      alert("Failed to change password:\n" + fieldErrors(data));
+      return;
+    }
+    alert(data.message);
+    localStorage.clear();
+    window.location.href = "auth.html";
+  });
+
+  document.getElementById("deleteAccountButton").addEventListener("click", async () => {
+    const password = prompt("This deletes your account for good. Enter your password to confirm:");
+    if (!password) return;
+
+    const res = await fetch(`${API_URL}/users/${userId}`, {
+      method: "DELETE",
+      headers: { "Content-Type": "application/json", Authorization: `Bearer ${token}` },
+      body: JSON.stringify({ password }),
+    });
+    const data = await res.json();
+    if (!res.ok) {
+      alert("Failed to delete account: " + (data.error || "unknown error"));
+      return;
+    }
+    localStorage.clear();
+    window.location.href = "index.html";
+  });
+
   loadProfile();
   loadAllOrders();
 });
@@ -1,13 +1,67 @@
 const API_URL = "http://localhost:8080";
-const token = localStorage.getItem("token");
+let token = localStorage.getItem("token");
 const userId = localStorage.getItem("userId");
-console.log("Token:", token);
 console.log("User ID:", userId);
 
 if (!token || !userId) {
   window.location.href = "auth.html";
 }
 
+function tokenExpiresAt(jwt) {
+  try {
+    const payload = jwt.split(".")[1].replace(/-/g, "+").replace(/_/g, "/");
+    return JSON.parse(atob(payload)).exp * 1000;
+  } catch {
+    return 0;
+  }
+}
+
+async function refreshSession() {
+  const refreshToken = localStorage.getItem("refreshToken");
+  if (!refreshToken) return false;
+
+  const res = await fetch(`${API_URL}/refresh`, {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ refresh_token: refreshToken }),
+  });
+  if (!res.ok) return false;
+
+  const data = await res.json();
+  localStorage.setItem("token", data.token);
+  localStorage.setItem("refreshToken", data.refresh_token);
+  token = data.token;
+  return true;
+}
+
+// Access tokens are short-lived; renew them shortly before they expire.
+async function keepSessionFresh() {
+  if (!token || tokenExpiresAt(token) - Date.now() > 2 * 60 * 1000) return;
+  if (!(await refreshSession())) {
+    localStorage.clear();
+    window.location.href = "auth.html";
+  }
+}
+
+const sessionReady = keepSessionFresh();
+setInterval(keepSessionFresh, 60 * 1000);
+
+async function logout() {
+  try {
+    await fetch(`${API_URL}/logout`, {
+      method: "POST",
+      headers: {
+        "Content-Type": "application/json",
+        Authorization: `Bearer ${token}`,
+      },
+      body: JSON.stringify({ refresh_token: localStorage.getItem("refreshToken") }),
+    });
+  } finally {
+    localStorage.clear();
+    window.location.href = "auth.html";
+  }
+}
+
 const categoryGrids = {
   recommended: document.getElementById("recommendedGrid"),
   appetizers: document.getElementById("appetizersGrid"),
@@ -19,7 +73,10 @@ const categoryGrids = {
 let currentPage = 1;
 const pageSize = 5;
 let totalItems = 0;
-let cart = [];
+// cart is the cart kept by the server for the signed in user, priced at
+// current menu prices.
+let cart = { items: [], total: 0 };
+let invalidCartItems = {};
 
 let currentSearch = "";
 let currentCategory = "";
@@ -164,62 +221,194 @@ function showDishModal(item) {
 }
 
 
-function addToCart(item) {
-  cart.push(item);
+// cartRequest calls the cart API and shows the cart it answers with.
+async function cartRequest(method, path, body) {
+  const res = await fetch(`${API_URL}/cart${path}`, {
+    method,
+    headers: {
+      "Content-Type": "application/json",
+      Authorization: `Bearer ${token}`,
+    },
+    body: body ? JSON.stringify(body) : undefined,
+  });
+  const data = await res.json();
+  if (!res.ok) {
+    if (res.status === 422 && data.invalid_items) {
+      alert("This item can't be ordered right now.");
+    } else {
+      alert("Cart update failed: " + data.error);
+    }
+    return;
+  }
+  cart = { items: data.items || [], total: data.total || 0 };
   updateCartUI();
+}
+
+function loadCart() {
+  return cartRequest("GET", "");
+}
+
+async function addToCart(item) {
   closeModal("dishModal");
+  delete invalidCartItems[item.id];
+  await cartRequest("POST", "/items", { menu_item_id: item.id, quantity: 1 });
+}
+
+
+async function removeFromCart(itemId) {
+  delete invalidCartItems[itemId];
+  await cartRequest("DELETE", `/items/${encodeURIComponent(itemId)}`);
 }
 
 
 function updateCartUI() {
   cartItems.innerHTML = "";
-  let total = 0;
-  for (const item of cart) {
-    total += item.price;
+  for (const item of cart.items) {
     const div = document.createElement("div");
     div.className = "cart-item";
-    div.innerHTML = `<span>${item.name}</span><span>$${item.price.toFixed(
+    div.innerHTML = `<span>${item.name} x ${item.quantity}</span><span>$${(item.line_total || 0).toFixed(
       2
     )}</span>`;
+    const reason = invalidCartItems[item.menu_item_id] || (item.available ? "" : "unavailable");
+    if (reason) {
+      div.classList.add("cart-item-invalid");
+      div.title = (reason === "unavailable" ? "Currently unavailable" : "No longer on the menu") + " - click to remove";
+      div.addEventListener("click", () => removeFromCart(item.menu_item_id));
+    }
     cartItems.appendChild(div);
   }
-  cartTotal.innerText = `$${total.toFixed(2)}`;
+  cartTotal.innerText = `$${cart.total.toFixed(2)}`;
 }
 
 
 document.getElementById("checkoutButton").addEventListener("click", () => {
-  if (cart.length === 0) return alert("Cart is empty.");
-  document.getElementById("checkoutItems").innerHTML = cart
-    .map((item) => `<p>${item.name} - $${item.price.toFixed(2)}</p>`)
+  if (cart.items.length === 0) return alert("Cart is empty.");
+  document.getElementById("checkoutItems").innerHTML = cart.items
+    .filter((item) => item.available)
+    .map((item) => `<p>${item.name} x ${item.quantity} - $${(item.line_total || 0).toFixed(2)}</p>`)
     .join("");
-  document.getElementById("checkoutTotal").innerText = `$${cart
-    .reduce((sum, item) => sum + item.price, 0)
-    .toFixed(2)}`;
+  document.getElementById("checkoutTotal").innerText = `$${cart.total.toFixed(2)}`;
   document.getElementById("checkoutModal").style.display = "flex";
 });
 
+// unpaidOrderId remembers an order whose payment was declined, so confirming
+// again retries the payment instead of placing a second order. Checking out
+// the same cart twice returns the order already placed, so only payments need
+// an idempotency key.
+let unpaidOrderId = null;
+let paymentKey = null;
+
+async function payOrder(orderId) {
+  const method = document.getElementById("paymentMethod").value;
+  if (!paymentKey || paymentKey.method !== method) {
+    paymentKey = { method, key: crypto.randomUUID() };
+  }
+  const res = await fetch(`${API_URL}/payments`, {
+    method: "POST",
+    headers: {
+      "Content-Type": "application/json",
+      Authorization: `Bearer ${token}`,
+      "Idempotency-Key": paymentKey.key,
+    },
+    body: JSON.stringify({
+      order_id: orderId,
+      payment_method: method,
+      capture: true,
+    }),
+  });
+  const data = await res.json();
+  if (res.ok) {
+    unpaidOrderId = null;
+    paymentKey = null;
+    alert(`Order paid! Order ID: ${orderId}`);
+    invalidCartItems = {};
+    closeModal("checkoutModal");
+    return;
+  }
+  unpaidOrderId = orderId;
+  if (res.status === 402) {
+    // A declined card is final for this key; trying again needs a new one.
+    paymentKey = null;
+    alert("Your card was declined (" + data.payment.decline_reason + "). Choose another card and confirm again.");
+  } else {
+    alert("Payment failed: " + data.error);
+  }
+}
+
+document.getElementById("fulfillmentType").addEventListener("change", (e) => {
+  const address = document.getElementById("customerAddress");
+  address.disabled = e.target.value === "pickup";
+  address.required = !address.disabled;
+});
+
+// fulfillment collects the checkout details sent with the order.
+function fulfillment() {
+  const type = document.getElementById("fulfillmentType").value;
+  const time = document.getElementById("requestedTime").value;
+  return {
+    type,
+    contact_name: document.getElementById("customerName").value,
+    address: type === "delivery" ? document.getElementById("customerAddress").value : "",
+    phone: document.getElementById("customerPhone").value,
+    notes: document.getElementById("customerNotes").value,
+    requested_time: time ? new Date(time).toISOString() : "",
+  };
+}
+
+const checkoutFieldNames = {
+  type: "Delivery or pickup",
+  contact_name: "Name",
+  address: "Address",
+  phone: "Phone number",
+  notes: "Notes",
+  requested_time: "Time",
+  promo_code: "Promo code",
+};
+
+// showCheckoutErrors lists the checkout details the server did not accept.
+function showCheckoutErrors(fields) {
+  const box = document.getElementById("checkoutErrors");
+  box.textContent = fields
+    .map((f) => `${checkoutFieldNames[f.field] || f.field}: ${f.reason.replace(/_/g, " ")}`)
+    .join("; ");
+}
+
 document
   .getElementById("confirmOrderButton")
   .addEventListener("click", async () => {
-    const itemIds = cart.map((item) => item.id);
-    const res = await fetch(`${API_URL}/orders`, {
+    if (unpaidOrderId) {
+      await payOrder(unpaidOrderId);
+      return;
+    }
+
+    const res = await fetch(`${API_URL}/cart/checkout`, {
       method: "POST",
       headers: {
         "Content-Type": "application/json",
         Authorization: `Bearer ${token}`,
       },
       body: JSON.stringify({
-        user_id: userId,
-        item_ids: itemIds,
+        fulfillment: fulfillment(),
+        promo_code: document.getElementById("promoCode").value,
       }),
     });
 
     const data = await res.json();
+    showCheckoutErrors([]);
     if (res.ok) {
-      alert(`Order placed! Order ID: ${data.order_id}`);
-      cart = [];
-      updateCartUI();
+      // The order is placed and the server has emptied the cart.
+      await loadCart();
+      await payOrder(data.order_id);
+    } else if (res.status === 422 && data.invalid_fields && data.invalid_fields.length > 0) {
+      showCheckoutErrors(data.invalid_fields);
+    } else if (res.status === 422 && data.invalid_items) {
+      invalidCartItems = {};
+      data.invalid_items.forEach((bad) => {
+        invalidCartItems[bad.item_id] = bad.reason;
+      });
+      await loadCart();
       closeModal("checkoutModal");
+      alert("Some items in your cart can't be ordered. Click the highlighted items to remove them.");
     } else {
       alert("Order failed: " + (data.error || data.message));
     }
@@ -238,13 +427,10 @@ function closeModal(id) {
   document.getElementById(id).style.display = "none";
 }
 
-document.getElementById("logoutButton").addEventListener("click", () => {
-  localStorage.clear();
-  window.location.href = "auth.html";
-});
+document.getElementById("logoutButton").addEventListener("click", logout);
 
 const authLink = document.getElementById("authLink");
 authLink.href = "profile.html";
 document.getElementById("authLinkText").innerText = "My Profile";
 
-loadMenu();
+sessionReady.then(() => Promise.all([loadMenu(), loadCart()]));
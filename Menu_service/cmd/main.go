@@ -1,9 +1,9 @@
 package main
 
 import (
-	"fmt"
+	"foodstore/logging"
 	"github.com/go-redis/redis/v8"
-	"log"
+	"log/slog"
 	"net"
 
 	"foodstore/menu/config"
@@ -21,6 +21,7 @@ type server struct {
 }
 
 func main() {
+	logging.Setup("menu")
 	cfg := config.LoadConfig()
 	db := config.ConnectToMongo(cfg.MongoURI, cfg.DatabaseName)
 	cache := redis.NewClient(&redis.Options{
@@ -30,13 +31,13 @@ func main() {
 	menuService := service.NewMenuService(menuRepo)
 	lis, err := net.Listen("tcp", ":50051")
 	if err != nil {
-		log.Fatalf("Failed to start the server: %v", err)
+		logging.Fatal("failed to start the server", "err", err)
 	}
 
-	grpcServer := grpc.NewServer()
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(logging.UnaryServerInterceptor()))
 	pb.RegisterMenuServiceServer(grpcServer, handler.NewMenuHandler(menuService))
-	fmt.Println("gRPC server started on port :50051")
+	slog.Info("MenuService started", "addr", ":50051")
 	if err := grpcServer.Serve(lis); err != nil {
-		log.Fatalf("gRPC server error: %v", err)
+		logging.Fatal("gRPC server error", "err", err)
 	}
 }
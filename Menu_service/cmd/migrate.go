@@ -1,15 +1,17 @@
 package main
 
 import (
-	"log"
+	"foodstore/logging"
+	"log/slog"
 	"menu/config"
 	"menu/internal/migration"
 )
 
 func main() {
+	logging.Setup("menu-migrate")
 	cfg := config.LoadConfig()
 	db := config.ConnectToMongo(cfg.MongoURI, cfg.DatabaseName)
 
-	log.Println("🚀 Running migrations...")
+	slog.Info("running migrations")
 	migrations.Run(db)
 }
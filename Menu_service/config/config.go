@@ -2,7 +2,7 @@ package config
 
 import (
 	"context"
-	"log"
+	"foodstore/logging"
 	"os"
 	"time"
 
@@ -39,10 +39,10 @@ func ConnectToMongo(uri, dbName string) *mongo.Database {
 
 	client, err := mongo.Connect(ctx, clientOpts)
 	if err != nil {
-		log.Fatalf("Не удалось подключиться к Mongo: %v", err)
+		logging.Fatal("failed to connect to MongoDB", "err", err)
 	}
 	if err := client.Ping(ctx, nil); err != nil {
-		log.Fatalf("Не удалось пропинговать Mongo: %v", err)
+		logging.Fatal("failed to ping MongoDB", "err", err)
 	}
 
 	return client.Database(dbName)
@@ -3,6 +3,7 @@ module foodstore/menu
 go 1.23.4
 
 require (
+	foodstore/logging v0.0.0
 	github.com/go-redis/redis/v8 v8.11.5
 	github.com/stretchr/testify v1.10.0
 	go.mongodb.org/mongo-driver v1.17.3
@@ -31,3 +32,5 @@ require (
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20250218202821-56aae31c358a // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+replace foodstore/logging => ../logging
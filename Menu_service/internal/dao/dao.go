@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/go-redis/redis/v8"
 	"foodstore/menu/internal/model"
+	"log/slog"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -82,12 +83,12 @@ func (r *MongoMenuRepository) GetAllMenuItems(ctx context.Context, filter interf
 		if err == nil && cached != "" {
 			var cachedItems []model.MenuItem
 			if err := json.Unmarshal([]byte(cached), &cachedItems); err == nil {
-				fmt.Println("Cache hit for GetAllMenuItems")
+				slog.DebugContext(ctx, "menu cache hit")
 				return cachedItems, nil
 			}
-			fmt.Println("Cache unmarshal error:", err)
+			slog.WarnContext(ctx, "menu cache entry unreadable", "err", err)
 		} else {
-			fmt.Println("Cache miss for GetAllMenuItems")
+			slog.DebugContext(ctx, "menu cache miss")
 		}
 	}
 
@@ -117,7 +118,7 @@ func (r *MongoMenuRepository) GetAllMenuItems(ctx context.Context, filter interf
 		if err == nil {
 			err := r.Cache.Set(ctx, cacheKey, data, 10*time.Minute).Err()
 			if err != nil {
-				fmt.Println("Failed to set cache:", err)
+				slog.WarnContext(ctx, "failed to cache menu items", "err", err)
 			}
 		}
 	}
@@ -2,7 +2,7 @@ package migrations
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -20,7 +20,7 @@ func Run(db *mongo.Database) {
 		Keys: bson.M{"category": 1},
 	})
 	if err != nil {
-		log.Printf("⚠️ Failed to create index: %v", err)
+		slog.Warn("failed to create index", "err", err)
 	}
 	count, _ := menuCol.CountDocuments(ctx, bson.M{})
 	if count == 0 {
@@ -149,9 +149,9 @@ func Run(db *mongo.Database) {
 
 		_, err := menuCol.InsertMany(ctx, items)
 		if err != nil {
-			log.Printf("Failed to insert seed data: %v", err)
+			slog.Error("failed to insert seed data", "err", err)
 		} else {
-			log.Println("Seeded menu items")
+			slog.Info("seeded menu items", "count", len(items))
 		}
 	}
 }
@@ -1,21 +1,31 @@
 package main
 
 import (
-	"fmt"
+	"context"
+	"errors"
+	"foodstore/events"
+	"foodstore/logging"
 	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.mongodb.org/mongo-driver/mongo"
 	"google.golang.org/grpc"
-	"log"
+	"log/slog"
 	"net"
+	"net/http"
 	"order/config"
 	"order/internal/dao"
 	"order/internal/handler"
 	"order/internal/nats"
+	"order/internal/outbox"
 	"order/internal/service"
 	pb "order/proto"
 	menupb "order/proto/menu"
+	"time"
 )
 
 func main() {
+	logging.Setup("order")
 	cfg := config.LoadConfig()
 	db := config.ConnectToMongo(cfg.MongoURI, cfg.DatabaseName)
 	cache := redis.NewClient(&redis.Options{
@@ -23,29 +33,73 @@ func main() {
 	})
 
 	repo := dao.NewOrderDao(db, cache)
-	svc := service.NewOrderService(repo)
+	svc := service.NewOrderService(repo, dao.NewRedisIdempotencyStore(cache), service.Pricing{
+		DeliveryFee: cfg.DeliveryFee,
+		PromoCodes:  cfg.PromoCodes,
+	})
 
-	menuConn, err := grpc.Dial("localhost:50051", grpc.WithInsecure())
+	menuConn, err := grpc.Dial("localhost:50051", grpc.WithInsecure(),
+		grpc.WithUnaryInterceptor(logging.UnaryClientInterceptor()))
 	if err != nil {
-		log.Fatalf("failed to connect to MenuService: %v", err)
+		logging.Fatal("failed to connect to MenuService", "err", err)
 	}
 	menuClient := menupb.NewMenuServiceClient(menuConn)
 	natsPublisher, err := nats.NewPublisher("nats://localhost:4222")
 	if err != nil {
-		log.Fatalf("Failed to connect to NATS: %v", err)
+		logging.Fatal("failed to connect to NATS", "err", err)
+	}
+	orderHandler := handler.NewOrderHandler(svc, menuClient)
+	carts := service.NewCartService(dao.NewRedisCartStore(cache), svc, cfg.CartTTL)
+
+	outboxStore := dao.NewOutboxDao(db)
+	indexCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	if err := outboxStore.EnsureIndexes(indexCtx); err != nil {
+		slog.Warn("failed to create outbox indexes", "err", err)
+	}
+	cancel()
+	relay := outbox.NewRelay(outboxStore, natsPublisher, outbox.DefaultConfig(), outbox.NewMetrics(prometheus.DefaultRegisterer))
+	go relay.Run(context.Background())
+
+	go func() {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.Handler())
+		slog.Info("metrics listening", "addr", cfg.MetricsAddr)
+		if err := http.ListenAndServe(cfg.MetricsAddr, mux); err != nil {
+			slog.Error("metrics server stopped", "err", err)
+		}
+	}()
+
+	subscriber, err := nats.NewSubscriber("nats://localhost:4222")
+	if err != nil {
+		logging.Fatal("failed to connect to NATS", "err", err)
+	}
+	subscribeCtx, cancelSubscribe := context.WithTimeout(context.Background(), 10*time.Second)
+	err = subscriber.OnPaymentCaptured(subscribeCtx, func(ctx context.Context, evt *events.PaymentCaptured) error {
+		err := svc.ConfirmPaidOrder(ctx, evt.GetOrderId())
+		if errors.Is(err, service.ErrInvalidTransition) || errors.Is(err, mongo.ErrNoDocuments) {
+			// Retrying cannot confirm a cancelled or missing order.
+			slog.ErrorContext(ctx, "paid order cannot be confirmed, the payment needs a refund",
+				"order_id", evt.GetOrderId(), "payment_id", evt.GetPaymentId(), "err", err)
+			return nil
+		}
+		return err
+	})
+	cancelSubscribe()
+	if err != nil {
+		logging.Fatal("failed to subscribe", "subject", events.TypePaymentCaptured, "err", err)
 	}
-	orderHandler := handler.NewOrderHandler(svc, menuClient, natsPublisher)
 
 	lis, err := net.Listen("tcp", ":50053")
 	if err != nil {
-		log.Fatalf("Failed to listen: %v", err)
+		logging.Fatal("failed to listen", "err", err)
 	}
 
-	grpcServer := grpc.NewServer()
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(logging.UnaryServerInterceptor()))
 	pb.RegisterOrderServiceServer(grpcServer, orderHandler)
+	pb.RegisterCartServiceServer(grpcServer, handler.NewCartHandler(carts, orderHandler))
 
-	fmt.Println("OrderService is running on :50053")
+	slog.Info("OrderService started", "addr", ":50053")
 	if err := grpcServer.Serve(lis); err != nil {
-		log.Fatalf("gRPC server error: %v", err)
+		logging.Fatal("gRPC server error", "err", err)
 	}
 }
@@ -2,12 +2,15 @@ package config
 
 import (
 	"context"
+	"fmt"
+	"foodstore/logging"
 	"github.com/joho/godotenv"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
-	"log"
+	"log/slog"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -19,15 +22,36 @@ type Config struct {
 	SMTPUser     string
 	SMTPPass     string
 	SMTPFrom     string
+	// MetricsAddr serves Prometheus metrics, including the outbox lag.
+	MetricsAddr string
+	// CartTTL is how long a cart is kept after its last change.
+	CartTTL time.Duration
+	// DeliveryFee is added to the total of delivery orders.
+	DeliveryFee float64
+	// PromoCodes maps an upper case promo code to the percentage it takes
+	// off an order.
+	PromoCodes map[string]float64
 }
 
 func LoadConfig() *Config {
 	err := godotenv.Load()
 	if err != nil {
-		log.Println("No .env file found")
+		slog.Info("no .env file found")
 	}
 
 	port, _ := strconv.Atoi(os.Getenv("SMTP_PORT"))
+	cartTTL, err := time.ParseDuration(getEnv("CART_TTL", "168h"))
+	if err != nil || cartTTL <= 0 {
+		logging.Fatal("invalid CART_TTL", "value", os.Getenv("CART_TTL"))
+	}
+	deliveryFee, err := strconv.ParseFloat(getEnv("DELIVERY_FEE", "0"), 64)
+	if err != nil || deliveryFee < 0 {
+		logging.Fatal("invalid DELIVERY_FEE", "value", os.Getenv("DELIVERY_FEE"))
+	}
+	promoCodes, err := parsePromoCodes(os.Getenv("PROMO_CODES"))
+	if err != nil {
+		logging.Fatal("invalid PROMO_CODES", "err", err)
+	}
 
 	return &Config{
 		MongoURI:     os.Getenv("MONGO_URI"),
@@ -37,7 +61,30 @@ func LoadConfig() *Config {
 		SMTPUser:     os.Getenv("SMTP_USER"),
 		SMTPPass:     os.Getenv("SMTP_PASS"),
 		SMTPFrom:     os.Getenv("SMTP_FROM"),
+		MetricsAddr:  getEnv("METRICS_ADDR", ":9103"),
+		CartTTL:      cartTTL,
+		DeliveryFee:  deliveryFee,
+		PromoCodes:   promoCodes,
+	}
+}
+
+// parsePromoCodes reads a comma separated list of CODE=percent pairs, such
+// as "WELCOME10=10,HALF=50".
+func parsePromoCodes(value string) (map[string]float64, error) {
+	codes := map[string]float64{}
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		code, percent, ok := strings.Cut(pair, "=")
+		code = strings.ToUpper(strings.TrimSpace(code))
+		off, err := strconv.ParseFloat(strings.TrimSpace(percent), 64)
+		if !ok || code == "" || err != nil || off <= 0 || off > 100 {
+			return nil, fmt.Errorf("%q is not CODE=percent", pair)
+		}
+		codes[code] = off
 	}
+	return codes, nil
 }
 
 func getEnv(key, defaultValue string) string {
@@ -54,13 +101,13 @@ func ConnectToMongo(uri string, dbName string) *mongo.Database {
 	clientOptions := options.Client().ApplyURI(uri)
 	client, err := mongo.Connect(ctx, clientOptions)
 	if err != nil {
-		log.Fatalf("Failed to connect to MongoDB: %v", err)
+		logging.Fatal("failed to connect to MongoDB", "err", err)
 	}
 
 	if err := client.Ping(ctx, nil); err != nil {
-		log.Fatalf("Failed to ping MongoDB: %v", err)
+		logging.Fatal("failed to ping MongoDB", "err", err)
 	}
 
-	log.Println("Connected to MongoDB")
+	slog.Info("connected to MongoDB", "database", dbName)
 	return client.Database(dbName)
 }
@@ -3,35 +3,111 @@ package dao
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/go-redis/redis/v8"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log/slog"
 	"order/internal/model"
 	"time"
 )
 
+var ErrStatusChanged = errors.New("order status was changed concurrently")
+
+type OrderRepository interface {
+	// Create inserts order and, in the same transaction, the outbox event
+	// built by event, if any. Replace, UpdateStatus and Delete likewise write
+	// the given outbox events together with their change.
+	Create(ctx context.Context, order model.Order, event EventFunc) (string, error)
+	GetByID(ctx context.Context, id string) (*model.Order, error)
+	Replace(ctx context.Context, order model.Order, from string, events ...model.OutboxEvent) error
+	UpdateStatus(ctx context.Context, id string, from string, change model.StatusChange, events ...model.OutboxEvent) error
+	Delete(ctx context.Context, id string, events ...model.OutboxEvent) error
+	FindOrdersByUserId(ctx context.Context, userId string) ([]model.Order, error)
+	List(ctx context.Context, limit int64, skip int64) ([]model.Order, error)
+	ReassignUser(ctx context.Context, from, to string) (int64, error)
+}
+
 type OrderDao struct {
 	Collection *mongo.Collection
+	Outbox     *mongo.Collection
 	Cache      *redis.Client
 }
 
 func NewOrderDao(db *mongo.Database, cache *redis.Client) *OrderDao {
 	return &OrderDao{
 		Collection: db.Collection("orders"),
+		Outbox:     db.Collection(outboxCollection),
 		Cache:      cache,
 	}
 }
 
-func (r *OrderDao) Create(ctx context.Context, order model.Order) (string, error) {
-	res, err := r.Collection.InsertOne(ctx, order)
+// Create needs MongoDB to run as a replica set, since the order and its event
+// are written in one transaction, as are the changes below that carry events.
+func (r *OrderDao) Create(ctx context.Context, order model.Order, event EventFunc) (string, error) {
+	session, err := r.Collection.Database().Client().StartSession()
+	if err != nil {
+		return "", err
+	}
+	defer session.EndSession(ctx)
+
+	id, err := session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		res, err := r.Collection.InsertOne(sc, order)
+		if err != nil {
+			return nil, err
+		}
+		id := res.InsertedID.(primitive.ObjectID).Hex()
+		if event == nil {
+			return id, nil
+		}
+
+		evt, err := event(id)
+		if err != nil {
+			return nil, err
+		}
+		return id, r.insertEvents(sc, evt)
+	})
 	if err != nil {
 		return "", err
 	}
-	return res.InsertedID.(interface {
-		Hex() string
-	}).Hex(), nil
+	return id.(string), nil
+}
+
+// withEvents runs write and inserts events in one transaction. Without
+// events, write runs on its own.
+func (r *OrderDao) withEvents(ctx context.Context, events []model.OutboxEvent, write func(ctx context.Context) error) error {
+	if len(events) == 0 {
+		return write(ctx)
+	}
+	session, err := r.Collection.Database().Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		if err := write(sc); err != nil {
+			return nil, err
+		}
+		return nil, r.insertEvents(sc, events...)
+	})
+	return err
+}
+
+func (r *OrderDao) insertEvents(ctx context.Context, events ...model.OutboxEvent) error {
+	for _, evt := range events {
+		if evt.CreatedAt.IsZero() {
+			evt.CreatedAt = time.Now()
+		}
+		evt.NextAttemptAt = evt.CreatedAt
+		if _, err := r.Outbox.InsertOne(ctx, evt); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (r *OrderDao) GetByID(ctx context.Context, id string) (*model.Order, error) {
@@ -68,17 +144,55 @@ func (r *OrderDao) GetByID(ctx context.Context, id string) (*model.Order, error)
 	return &order, nil
 }
 
-func (r *OrderDao) UpdateStatus(ctx context.Context, id string, status string) error {
+// Replace stores order if the stored order is still in status from, and
+// returns ErrStatusChanged otherwise, like UpdateStatus.
+func (r *OrderDao) Replace(ctx context.Context, order model.Order, from string, events ...model.OutboxEvent) error {
+	objID, err := primitive.ObjectIDFromHex(order.ID)
+	if err != nil {
+		return fmt.Errorf("invalid ObjectID: %v", err)
+	}
+
+	id := order.ID
+	order.ID = ""
+	err = r.withEvents(ctx, events, func(ctx context.Context) error {
+		res, err := r.Collection.ReplaceOne(ctx, bson.M{"_id": objID, "status": from}, order)
+		if err == nil && res.MatchedCount == 0 {
+			return ErrStatusChanged
+		}
+		return err
+	})
+
+	if err == nil && r.Cache != nil {
+		r.Cache.Del(ctx, "order:id:"+id)
+		err = r.invalidateUserOrdersCache(ctx)
+	}
+
+	return err
+}
+
+// UpdateStatus moves an order from status from to change.Status and appends
+// change to its history. It returns ErrStatusChanged if the order is no longer
+// in status from, so concurrent updates cannot skip the state machine.
+func (r *OrderDao) UpdateStatus(ctx context.Context, id string, from string, change model.StatusChange, events ...model.OutboxEvent) error {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return fmt.Errorf("invalid ObjectID: %v", err)
 	}
 
-	_, err = r.Collection.UpdateOne(
-		ctx,
-		bson.M{"_id": objID},
-		bson.M{"$set": bson.M{"status": status}},
-	)
+	err = r.withEvents(ctx, events, func(ctx context.Context) error {
+		res, err := r.Collection.UpdateOne(
+			ctx,
+			bson.M{"_id": objID, "status": from},
+			bson.M{
+				"$set":  bson.M{"status": change.Status},
+				"$push": bson.M{"status_history": change},
+			},
+		)
+		if err == nil && res.MatchedCount == 0 {
+			return ErrStatusChanged
+		}
+		return err
+	})
 
 	if err == nil && r.Cache != nil {
 		r.Cache.Del(ctx, "order:id:"+id)
@@ -88,13 +202,19 @@ func (r *OrderDao) UpdateStatus(ctx context.Context, id string, status string) e
 	return err
 }
 
-func (r *OrderDao) Delete(ctx context.Context, id string) error {
+func (r *OrderDao) Delete(ctx context.Context, id string, events ...model.OutboxEvent) error {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return fmt.Errorf("invalid ObjectID: %v", err)
 	}
 
-	_, err = r.Collection.DeleteOne(ctx, bson.M{"_id": objID})
+	err = r.withEvents(ctx, events, func(ctx context.Context) error {
+		res, err := r.Collection.DeleteOne(ctx, bson.M{"_id": objID})
+		if err == nil && res.DeletedCount == 0 {
+			return mongo.ErrNoDocuments
+		}
+		return err
+	})
 
 	if err == nil && r.Cache != nil {
 		r.Cache.Del(ctx, "order:id:"+id)
@@ -111,13 +231,13 @@ func (r *OrderDao) FindOrdersByUserId(ctx context.Context, userId string) ([]mod
 		if err == nil && cached != "" {
 			var cachedOrders []model.Order
 			if err := json.Unmarshal([]byte(cached), &cachedOrders); err == nil {
-				fmt.Println("Cache hit for user:", userId)
+				slog.DebugContext(ctx, "orders cache hit", "user_id", userId)
 				return cachedOrders, nil
 			}
 
-			fmt.Println("Cache unmarshal error for user:", userId, err)
+			slog.WarnContext(ctx, "orders cache entry unreadable", "user_id", userId, "err", err)
 		} else {
-			fmt.Println("Cache miss for user:", userId)
+			slog.DebugContext(ctx, "orders cache miss", "user_id", userId)
 		}
 	}
 
@@ -138,9 +258,7 @@ func (r *OrderDao) FindOrdersByUserId(ctx context.Context, userId string) ([]mod
 		if err == nil {
 			err = r.Cache.Set(ctx, cacheKey, data, 10*time.Minute).Err()
 			if err != nil {
-				fmt.Println("Failed to set cache for user:", userId, err)
-			} else {
-				fmt.Println("Cache set for user:", userId)
+				slog.WarnContext(ctx, "failed to cache orders", "user_id", userId, "err", err)
 			}
 		}
 	}
@@ -148,6 +266,56 @@ func (r *OrderDao) FindOrdersByUserId(ctx context.Context, userId string) ([]mod
 	return orders, nil
 }
 
+// ReassignUser moves every order of user from to user to, including the
+// status changes from made on them. The contact details of their fulfillment
+// are removed in the same update; the fulfillment type and time stay.
+// Events published before keep their payload until the outbox and the
+// stream expire them.
+func (r *OrderDao) ReassignUser(ctx context.Context, from, to string) (int64, error) {
+	cursor, err := r.Collection.Find(ctx, bson.M{"user_id": from}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return 0, err
+	}
+	var ids []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &ids); err != nil {
+		return 0, err
+	}
+
+	_, err = r.Collection.UpdateMany(
+		ctx,
+		bson.M{"status_history.actor_id": from},
+		bson.M{"$set": bson.M{"status_history.$[change].actor_id": to}},
+		options.Update().SetArrayFilters(options.ArrayFilters{
+			Filters: []interface{}{bson.M{"change.actor_id": from}},
+		}),
+	)
+	if err != nil {
+		return 0, err
+	}
+	res, err := r.Collection.UpdateMany(ctx, bson.M{"user_id": from}, bson.M{
+		"$set": bson.M{"user_id": to},
+		"$unset": bson.M{
+			"fulfillment.contact_name": "",
+			"fulfillment.address":      "",
+			"fulfillment.phone":        "",
+			"fulfillment.notes":        "",
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if r.Cache != nil {
+		for _, id := range ids {
+			r.Cache.Del(ctx, "order:id:"+id.ID.Hex())
+		}
+		err = r.invalidateUserOrdersCache(ctx)
+	}
+	return res.ModifiedCount, err
+}
+
 func (r *OrderDao) invalidateUserOrdersCache(ctx context.Context) error {
 	iter := r.Cache.Scan(ctx, 0, "orders:user:*", 0).Iterator()
 	for iter.Next(ctx) {
@@ -0,0 +1,130 @@
+package dao
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"order/internal/model"
+)
+
+// ErrCartConflict means the cart kept changing while an update was applied.
+var ErrCartConflict = errors.New("cart was changed concurrently")
+
+type CartStore interface {
+	// Get returns the cart of userID, which is empty if the user has none.
+	Get(ctx context.Context, userID string) (*model.Cart, error)
+	// Update applies change to the cart of userID and stores the result
+	// for ttl, or removes it if it is left empty. Concurrent updates of the
+	// same cart are applied one after the other.
+	Update(ctx context.Context, userID string, ttl time.Duration, change func(*model.Cart) error) (*model.Cart, error)
+	// Remove deletes cart unless it changed since it was read.
+	Remove(ctx context.Context, cart model.Cart) (bool, error)
+}
+
+type RedisCartStore struct {
+	Cache *redis.Client
+}
+
+func NewRedisCartStore(cache *redis.Client) *RedisCartStore {
+	return &RedisCartStore{Cache: cache}
+}
+
+// cartUpdateAttempts bounds how often an update is retried after losing a
+// race with another update of the same cart.
+const cartUpdateAttempts = 5
+
+func cartKey(userID string) string {
+	return "cart:" + userID
+}
+
+func (s *RedisCartStore) Get(ctx context.Context, userID string) (*model.Cart, error) {
+	return getCart(ctx, s.Cache, userID)
+}
+
+func getCart(ctx context.Context, c redis.Cmdable, userID string) (*model.Cart, error) {
+	data, err := c.Get(ctx, cartKey(userID)).Bytes()
+	if err == redis.Nil {
+		return &model.Cart{UserID: userID}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cart model.Cart
+	if err := json.Unmarshal(data, &cart); err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+func (s *RedisCartStore) Update(ctx context.Context, userID string, ttl time.Duration, change func(*model.Cart) error) (*model.Cart, error) {
+	key := cartKey(userID)
+	var updated *model.Cart
+	update := func(tx *redis.Tx) error {
+		cart, err := getCart(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+		if err := change(cart); err != nil {
+			return err
+		}
+		if cart.ID == "" {
+			cart.ID = primitive.NewObjectID().Hex()
+		}
+		cart.Version++
+		cart.UpdatedAt = time.Now()
+		data, err := json.Marshal(cart)
+		if err != nil {
+			return err
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			if len(cart.Items) == 0 {
+				pipe.Del(ctx, key)
+			} else {
+				pipe.Set(ctx, key, data, ttl)
+			}
+			return nil
+		})
+		updated = cart
+		return err
+	}
+
+	for i := 0; i < cartUpdateAttempts; i++ {
+		err := s.Cache.Watch(ctx, update, key)
+		if err == redis.TxFailedErr {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return updated, nil
+	}
+	return nil, ErrCartConflict
+}
+
+func (s *RedisCartStore) Remove(ctx context.Context, cart model.Cart) (bool, error) {
+	key := cartKey(cart.UserID)
+	removed := false
+	err := s.Cache.Watch(ctx, func(tx *redis.Tx) error {
+		current, err := getCart(ctx, tx, cart.UserID)
+		if err != nil {
+			return err
+		}
+		if current.ID != cart.ID || current.Version != cart.Version {
+			return nil
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Del(ctx, key)
+			return nil
+		})
+		removed = err == nil
+		return err
+	}, key)
+	if err == redis.TxFailedErr {
+		return false, nil
+	}
+	return removed, err
+}
@@ -59,7 +59,7 @@ func TestOrderDao_Caching(t *testing.T) {
 		ItemIDs:    []string{"item1", "item2"},
 		CreatedAt:  time.Now(),
 	}
-	id, err := dao.Create(ctx, order)
+	id, err := dao.Create(ctx, order, nil)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, id)
 	fetchedOrder, err := dao.GetByID(ctx, id)
@@ -80,12 +80,63 @@ func TestOrderDao_Caching(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, id, fetchedOrder2.ID)
 
-	err = dao.UpdateStatus(ctx, id, "Completed")
+	err = dao.UpdateStatus(ctx, id, "Pending", model.StatusChange{
+		Status:    "Confirmed",
+		ChangedAt: time.Now(),
+		ActorID:   "admin1",
+	})
 	assert.NoError(t, err)
 
 	_, err = redisClient.Get(ctx, cacheKey).Result()
 	assert.Error(t, err)
 	fetchedOrder3, err := dao.GetByID(ctx, id)
 	assert.NoError(t, err)
-	assert.Equal(t, "Completed", fetchedOrder3.Status)
+	assert.Equal(t, "Confirmed", fetchedOrder3.Status)
+	assert.Len(t, fetchedOrder3.StatusHistory, 1)
+
+	err = dao.UpdateStatus(ctx, id, "Pending", model.StatusChange{Status: "Cancelled", ChangedAt: time.Now()})
+	assert.Error(t, err)
+
+	// Replace, too, only applies to the status it was computed from.
+	err = dao.Replace(ctx, *fetchedOrder3, "Pending")
+	assert.ErrorContains(t, err, "changed concurrently")
+	assert.NoError(t, dao.Replace(ctx, *fetchedOrder3, "Confirmed"))
+}
+
+func TestOrderDao_ReassignUserRemovesContactDetails(t *testing.T) {
+	ctx := context.Background()
+
+	mongoClient, teardownMongo := setupMongo(t)
+	defer teardownMongo()
+
+	db := mongoClient.Database("testdb")
+	dao := dao.NewOrderDao(db, nil)
+	_ = db.Collection("orders").Drop(ctx)
+
+	requestedAt := time.Now().Add(time.Hour).UTC().Truncate(time.Millisecond)
+	id, err := dao.Create(ctx, model.Order{
+		UserID:     "user123",
+		Status:     "Pending",
+		TotalPrice: 20.5,
+		ItemIDs:    []string{"item1"},
+		CreatedAt:  time.Now(),
+		Fulfillment: &model.Fulfillment{
+			Type:        model.FulfillmentDelivery,
+			ContactName: "John Doe",
+			Address:     "1 Main St",
+			Phone:       "+15551234567",
+			Notes:       "ring twice",
+			RequestedAt: requestedAt,
+		},
+	}, nil)
+	assert.NoError(t, err)
+
+	n, err := dao.ReassignUser(ctx, "user123", model.DeletedUserID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	order, err := dao.GetByID(ctx, id)
+	assert.NoError(t, err)
+	assert.Equal(t, model.DeletedUserID, order.UserID)
+	assert.Equal(t, &model.Fulfillment{Type: model.FulfillmentDelivery, RequestedAt: requestedAt}, order.Fulfillment)
 }
@@ -0,0 +1,78 @@
+package dao
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// IdempotencyRecord is the JSON value kept in Redis under an idempotency key.
+// Result is the id of the order created for it; it stays empty while that
+// order is being created.
+type IdempotencyRecord struct {
+	RequestHash string `json:"request_hash"`
+	Result      string `json:"result,omitempty"`
+}
+
+// IdempotencyStore keeps the keys of OrderService.CreateOnce. Every write
+// takes the ttl after which Redis drops the key.
+type IdempotencyStore interface {
+	// Reserve writes a record without result for hash unless key is set. A
+	// set key is returned as it is, with false.
+	Reserve(ctx context.Context, key, hash string, ttl time.Duration) (*IdempotencyRecord, bool, error)
+	// Complete overwrites key with the finished record.
+	Complete(ctx context.Context, key string, record IdempotencyRecord, ttl time.Duration) error
+	// Release deletes key, so that a request that failed can be sent again.
+	Release(ctx context.Context, key string) error
+}
+
+type RedisIdempotencyStore struct {
+	Cache *redis.Client
+}
+
+func NewRedisIdempotencyStore(cache *redis.Client) *RedisIdempotencyStore {
+	return &RedisIdempotencyStore{Cache: cache}
+}
+
+func idempotencyKey(key string) string {
+	return "idempotency:" + key
+}
+
+func (s *RedisIdempotencyStore) Reserve(ctx context.Context, key, hash string, ttl time.Duration) (*IdempotencyRecord, bool, error) {
+	data, err := json.Marshal(IdempotencyRecord{RequestHash: hash})
+	if err != nil {
+		return nil, false, err
+	}
+	ok, err := s.Cache.SetNX(ctx, idempotencyKey(key), data, ttl).Result()
+	if err != nil || ok {
+		return nil, ok, err
+	}
+
+	stored, err := s.Cache.Get(ctx, idempotencyKey(key)).Result()
+	if err == redis.Nil {
+		// Released or expired in between; let the caller try again.
+		return s.Reserve(ctx, key, hash, ttl)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	var record IdempotencyRecord
+	if err := json.Unmarshal([]byte(stored), &record); err != nil {
+		return nil, false, err
+	}
+	return &record, false, nil
+}
+
+func (s *RedisIdempotencyStore) Complete(ctx context.Context, key string, record IdempotencyRecord, ttl time.Duration) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return s.Cache.Set(ctx, idempotencyKey(key), data, ttl).Err()
+}
+
+func (s *RedisIdempotencyStore) Release(ctx context.Context, key string) error {
+	return s.Cache.Del(ctx, idempotencyKey(key)).Err()
+}
@@ -0,0 +1,122 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"order/internal/model"
+)
+
+// SentEventRetention is how long published events stay in the outbox.
+const SentEventRetention = 7 * 24 * time.Hour
+
+// EventFunc builds the outbox event for a newly inserted order.
+type EventFunc func(orderID string) (model.OutboxEvent, error)
+
+type OutboxStore interface {
+	// Claim returns the oldest event that is due and leases it for lease, so
+	// that other relays skip it meanwhile. It returns nil when nothing is due.
+	Claim(ctx context.Context, now time.Time, lease time.Duration) (*model.OutboxEvent, error)
+	MarkSent(ctx context.Context, id string, at time.Time) error
+	// MarkFailed records a failed publish and when to try again.
+	MarkFailed(ctx context.Context, id string, retryAt time.Time, reason string) error
+	// Pending returns the number of unsent events and the creation time of
+	// the oldest one.
+	Pending(ctx context.Context) (int64, time.Time, error)
+}
+
+type OutboxDao struct {
+	Collection *mongo.Collection
+}
+
+func NewOutboxDao(db *mongo.Database) *OutboxDao {
+	return &OutboxDao{Collection: db.Collection(outboxCollection)}
+}
+
+const outboxCollection = "outbox"
+
+var unsent = bson.M{"sent_at": bson.M{"$exists": false}}
+
+// EnsureIndexes creates the indexes the relay queries with, and a TTL index
+// that drops published events after SentEventRetention.
+func (r *OutboxDao) EnsureIndexes(ctx context.Context) error {
+	_, err := r.Collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "next_attempt_at", Value: 1}, {Key: "created_at", Value: 1}},
+			Options: options.Index().SetName("due").SetPartialFilterExpression(bson.M{"next_attempt_at": bson.M{"$exists": true}}),
+		},
+		{
+			Keys:    bson.D{{Key: "sent_at", Value: 1}},
+			Options: options.Index().SetName("sent_at_ttl").SetExpireAfterSeconds(int32(SentEventRetention.Seconds())),
+		},
+	})
+	return err
+}
+
+func (r *OutboxDao) Claim(ctx context.Context, now time.Time, lease time.Duration) (*model.OutboxEvent, error) {
+	filter := bson.M{
+		"sent_at":         bson.M{"$exists": false},
+		"next_attempt_at": bson.M{"$lte": now},
+	}
+	update := bson.M{
+		"$set": bson.M{"next_attempt_at": now.Add(lease)},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "created_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var event model.OutboxEvent
+	err := r.Collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&event)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+func (r *OutboxDao) MarkSent(ctx context.Context, id string, at time.Time) error {
+	return r.update(ctx, id, bson.M{
+		"$set":   bson.M{"sent_at": at},
+		"$unset": bson.M{"next_attempt_at": "", "last_error": ""},
+	})
+}
+
+func (r *OutboxDao) MarkFailed(ctx context.Context, id string, retryAt time.Time, reason string) error {
+	return r.update(ctx, id, bson.M{
+		"$set": bson.M{"next_attempt_at": retryAt, "last_error": reason},
+	})
+}
+
+func (r *OutboxDao) update(ctx context.Context, id string, update bson.M) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return mongo.ErrNoDocuments
+	}
+	_, err = r.Collection.UpdateOne(ctx, bson.M{"_id": objID}, update)
+	return err
+}
+
+func (r *OutboxDao) Pending(ctx context.Context) (int64, time.Time, error) {
+	count, err := r.Collection.CountDocuments(ctx, unsent)
+	if err != nil || count == 0 {
+		return count, time.Time{}, err
+	}
+
+	var oldest model.OutboxEvent
+	err = r.Collection.FindOne(ctx, unsent, options.FindOne().SetSort(bson.D{{Key: "created_at", Value: 1}})).Decode(&oldest)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, time.Time{}, nil
+	}
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	return count, oldest.CreatedAt, nil
+}
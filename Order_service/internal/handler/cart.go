@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"order/internal/dao"
+	"order/internal/identity"
+	"order/internal/model"
+	"order/internal/service"
+	pb "order/proto"
+	menupb "order/proto/menu"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type CartHandler struct {
+	pb.UnimplementedCartServiceServer
+	carts  *service.CartService
+	orders *OrderHandler
+}
+
+// NewCartHandler returns the cart API. Carts are priced with the menu client
+// of orders and checked out through it.
+func NewCartHandler(carts *service.CartService, orders *OrderHandler) *CartHandler {
+	return &CartHandler{carts: carts, orders: orders}
+}
+
+func (h *CartHandler) GetCart(ctx context.Context, req *pb.GetCartRequest) (*pb.CartResponse, error) {
+	caller, ok := identity.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing caller identity")
+	}
+	cart, err := h.carts.Get(ctx, caller.UserID)
+	if err != nil {
+		return nil, cartError(err)
+	}
+	return h.response(ctx, cart)
+}
+
+func (h *CartHandler) AddCartItem(ctx context.Context, req *pb.AddCartItemRequest) (*pb.CartResponse, error) {
+	caller, ok := identity.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing caller identity")
+	}
+	quantity := req.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+	if quantity < 0 || quantity > service.MaxItemQuantity {
+		return nil, cartError(service.ErrInvalidQuantity)
+	}
+	// Only orderable items get into the cart; the price is looked up again
+	// whenever the cart is shown.
+	if _, err := h.priceLines(ctx, []model.OrderItem{{MenuItemID: req.MenuItemId, Quantity: quantity}}); err != nil {
+		return nil, err
+	}
+
+	cart, err := h.carts.AddItem(ctx, caller.UserID, req.MenuItemId, quantity)
+	if err != nil {
+		return nil, cartError(err)
+	}
+	return h.response(ctx, cart)
+}
+
+func (h *CartHandler) UpdateCartItem(ctx context.Context, req *pb.UpdateCartItemRequest) (*pb.CartResponse, error) {
+	caller, ok := identity.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing caller identity")
+	}
+	cart, err := h.carts.SetQuantity(ctx, caller.UserID, req.MenuItemId, req.Quantity)
+	if err != nil {
+		return nil, cartError(err)
+	}
+	return h.response(ctx, cart)
+}
+
+func (h *CartHandler) RemoveCartItem(ctx context.Context, req *pb.RemoveCartItemRequest) (*pb.CartResponse, error) {
+	caller, ok := identity.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing caller identity")
+	}
+	cart, err := h.carts.RemoveItem(ctx, caller.UserID, req.MenuItemId)
+	if err != nil {
+		return nil, cartError(err)
+	}
+	return h.response(ctx, cart)
+}
+
+func (h *CartHandler) ClearCart(ctx context.Context, req *pb.ClearCartRequest) (*pb.CartResponse, error) {
+	caller, ok := identity.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing caller identity")
+	}
+	if err := h.carts.Clear(ctx, caller.UserID); err != nil {
+		return nil, cartError(err)
+	}
+	return &pb.CartResponse{Cart: &pb.Cart{}}, nil
+}
+
+// CheckoutCart places an order for the cart at current menu prices. When an
+// item is no longer available nothing is ordered and the cart is kept, so the
+// customer can fix it.
+func (h *CartHandler) CheckoutCart(ctx context.Context, req *pb.CheckoutCartRequest) (*pb.CheckoutCartResponse, error) {
+	caller, ok := identity.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing caller identity")
+	}
+	fulfillment, err := fulfillmentFromPb(req.Fulfillment, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	id, replayed, err := h.carts.Checkout(ctx, caller.UserID, fulfillment, req.PromoCode, func(items []model.OrderItem, promoCode string) (string, error) {
+		return h.orders.placeOrder(ctx, caller.UserID, items, fulfillment, promoCode)
+	})
+	if err != nil {
+		return nil, cartError(err)
+	}
+	slog.InfoContext(ctx, "checked out cart", "order_id", id, "replayed", replayed)
+	return &pb.CheckoutCartResponse{OrderId: id, Replayed: replayed}, nil
+}
+
+// priceLines prices lines like an order would be, failing with the same
+// violations.
+func (h *CartHandler) priceLines(ctx context.Context, lines []model.OrderItem) ([]model.OrderItem, error) {
+	menuItems, err := h.menuItems(ctx, lines)
+	if err != nil {
+		return nil, err
+	}
+	return priceItems(lines, menuItems)
+}
+
+func (h *CartHandler) menuItems(ctx context.Context, lines []model.OrderItem) ([]*menupb.MenuItem, error) {
+	ids := make([]string, 0, len(lines))
+	for _, line := range lines {
+		ids = append(ids, line.MenuItemID)
+	}
+	res, err := h.orders.menuClient.GetMultipleMenuItems(ctx, &menupb.GetMultipleMenuItemsRequest{Ids: ids})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch menu items: %v", err)
+	}
+	return res.Items, nil
+}
+
+// response prices cart at current menu prices. Lines that can no longer be
+// ordered are returned unavailable and left out of the total.
+func (h *CartHandler) response(ctx context.Context, cart *model.Cart) (*pb.CartResponse, error) {
+	if len(cart.Items) == 0 {
+		return &pb.CartResponse{Cart: &pb.Cart{}}, nil
+	}
+	menuItems, err := h.menuItems(ctx, cart.OrderItems())
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*menupb.MenuItem, len(menuItems))
+	for _, item := range menuItems {
+		byID[item.Id] = item
+	}
+
+	out := &pb.Cart{UpdatedAt: cart.UpdatedAt.Format(time.RFC3339)}
+	for _, item := range cart.Items {
+		line := &pb.CartItem{MenuItemId: item.MenuItemID, Quantity: item.Quantity}
+		if menuItem, ok := byID[item.MenuItemID]; ok {
+			line.Name = menuItem.Name
+			line.UnitPrice = menuItem.Price
+			line.LineTotal = menuItem.Price * float64(item.Quantity)
+			line.Available = menuItem.Available
+		}
+		if line.Available {
+			out.Total += line.LineTotal
+			out.ItemCount += item.Quantity
+		}
+		out.Items = append(out.Items, line)
+	}
+	return &pb.CartResponse{Cart: out}, nil
+}
+
+// cartError maps cart and checkout errors onto gRPC status codes. Errors that
+// already carry a status, such as item violations, pass through.
+func cartError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidQuantity):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrUnknownPromoCode):
+		return promoCodeError(err)
+	case errors.Is(err, service.ErrNotInCart):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrCartTooLarge), errors.Is(err, service.ErrCartEmpty):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, dao.ErrCartConflict), errors.Is(err, service.ErrIdempotencyInProgress):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, service.ErrIdempotencyConflict):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, service.ErrIdempotencyUnavailable):
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	return err
+}
@@ -0,0 +1,122 @@
+package handler_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"order/internal/handler"
+	"order/internal/model"
+	"order/internal/service"
+	pb "order/proto"
+	menupb "order/proto/menu"
+)
+
+// memCartStore keeps one cart per user in a map.
+type memCartStore struct {
+	carts map[string]model.Cart
+}
+
+func (s *memCartStore) Get(ctx context.Context, userID string) (*model.Cart, error) {
+	cart := s.carts[userID]
+	cart.UserID = userID
+	return &cart, nil
+}
+
+func (s *memCartStore) Update(ctx context.Context, userID string, ttl time.Duration, change func(*model.Cart) error) (*model.Cart, error) {
+	cart, _ := s.Get(ctx, userID)
+	cart.Items = append([]model.CartItem(nil), cart.Items...)
+	if err := change(cart); err != nil {
+		return nil, err
+	}
+	cart.Version++
+	s.carts[userID] = *cart
+	return cart, nil
+}
+
+func (s *memCartStore) Remove(ctx context.Context, cart model.Cart) (bool, error) {
+	delete(s.carts, cart.UserID)
+	return true, nil
+}
+
+func newCartHandler(menu *stubMenuClient) *handler.CartHandler {
+	carts := service.NewCartService(&memCartStore{carts: map[string]model.Cart{}}, service.NewOrderService(nil, nil, service.Pricing{}), 0)
+	return handler.NewCartHandler(carts, handler.NewOrderHandler(nil, menu))
+}
+
+func TestCart_PricedLive(t *testing.T) {
+	menu := &stubMenuClient{items: []*menupb.MenuItem{
+		{Id: burgerID, Name: "Burger", Price: 9.99, Available: true},
+		{Id: saladID, Name: "Salad", Price: 7.99, Available: true},
+	}}
+	h := newCartHandler(menu)
+	ctx := callerContext("user123", "user")
+
+	_, err := h.AddCartItem(ctx, &pb.AddCartItemRequest{MenuItemId: burgerID, Quantity: 2})
+	assert.NoError(t, err)
+	res, err := h.AddCartItem(ctx, &pb.AddCartItemRequest{MenuItemId: saladID})
+	assert.NoError(t, err)
+	assert.InDelta(t, 27.97, res.Cart.Total, 1e-9)
+	assert.Equal(t, int32(3), res.Cart.ItemCount)
+
+	// The salad sells out and the burger gets dearer after they were added.
+	menu.items = []*menupb.MenuItem{
+		{Id: burgerID, Name: "Burger", Price: 10.49, Available: true},
+		{Id: saladID, Name: "Salad", Price: 7.99, Available: false},
+	}
+	res, err = h.GetCart(ctx, &pb.GetCartRequest{})
+	assert.NoError(t, err)
+	if assert.Len(t, res.Cart.Items, 2) {
+		assert.True(t, res.Cart.Items[0].Available)
+		assert.InDelta(t, 20.98, res.Cart.Items[0].LineTotal, 1e-9)
+		assert.False(t, res.Cart.Items[1].Available)
+	}
+	assert.InDelta(t, 20.98, res.Cart.Total, 1e-9)
+	assert.Equal(t, int32(2), res.Cart.ItemCount)
+
+	_, err = h.CheckoutCart(ctx, &pb.CheckoutCartRequest{})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.Equal(t, map[string]string{saladID: handler.ReasonUnavailable}, violations(t, err))
+}
+
+func TestCart_AddRejectsItemsThatCannotBeOrdered(t *testing.T) {
+	h := newCartHandler(&stubMenuClient{items: []*menupb.MenuItem{
+		{Id: saladID, Name: "Salad", Price: 7.99, Available: false},
+	}})
+	ctx := callerContext("user123", "user")
+
+	_, err := h.AddCartItem(ctx, &pb.AddCartItemRequest{MenuItemId: saladID})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = h.AddCartItem(ctx, &pb.AddCartItemRequest{MenuItemId: deletedID})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = h.AddCartItem(ctx, &pb.AddCartItemRequest{MenuItemId: saladID, Quantity: -1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	res, err := h.GetCart(ctx, &pb.GetCartRequest{})
+	assert.NoError(t, err)
+	assert.Empty(t, res.Cart.Items)
+
+	_, err = h.GetCart(context.Background(), &pb.GetCartRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestCart_CheckoutRejectsUnknownPromoCode(t *testing.T) {
+	h := newCartHandler(&stubMenuClient{items: []*menupb.MenuItem{
+		{Id: burgerID, Name: "Burger", Price: 9.99, Available: true},
+	}})
+	ctx := callerContext("user123", "user")
+
+	_, err := h.AddCartItem(ctx, &pb.AddCartItemRequest{MenuItemId: burgerID})
+	assert.NoError(t, err)
+	_, err = h.CheckoutCart(ctx, &pb.CheckoutCartRequest{PromoCode: "FREEFOOD"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, map[string]string{"promo_code": handler.ReasonInvalid}, violations(t, err))
+
+	res, err := h.GetCart(ctx, &pb.GetCartRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, res.Cart.Items, 1, "the cart is kept")
+}
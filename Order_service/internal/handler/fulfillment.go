@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"order/internal/model"
+	pb "order/proto"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Reasons reported for checkout details that are not accepted.
+const (
+	ReasonRequired = "required"
+	ReasonInvalid  = "invalid"
+	ReasonTooLong  = "too_long"
+	ReasonInPast   = "in_past"
+	ReasonTooFar   = "too_far_ahead"
+)
+
+const (
+	maxContactNameLen = 100
+	maxAddressLen     = 300
+	maxNotesLen       = 500
+	minPhoneDigits    = 7
+	maxPhoneDigits    = 15
+	// maxScheduleAhead is how far ahead an order may be requested.
+	maxScheduleAhead = 7 * 24 * time.Hour
+)
+
+// fulfillmentFromPb checks the checkout details of an order placed at now.
+// Every field that is not accepted is reported in a single InvalidArgument
+// error, with a BadRequest violation named like "fulfillment.phone". Orders
+// without details, from older clients, get nil.
+func fulfillmentFromPb(f *pb.Fulfillment, now time.Time) (*model.Fulfillment, error) {
+	if f == nil {
+		return nil, nil
+	}
+
+	var violations []*errdetails.BadRequest_FieldViolation
+	reject := func(field, reason string) {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       "fulfillment." + field,
+			Description: reason,
+		})
+	}
+	text := func(field, value string, max int, required bool) string {
+		value = strings.TrimSpace(value)
+		switch {
+		case value == "" && required:
+			reject(field, ReasonRequired)
+		case utf8.RuneCountInString(value) > max:
+			reject(field, ReasonTooLong)
+		}
+		return value
+	}
+
+	out := &model.Fulfillment{Type: strings.ToLower(strings.TrimSpace(f.Type))}
+	switch out.Type {
+	case model.FulfillmentDelivery:
+		out.Address = text("address", f.Address, maxAddressLen, true)
+	case model.FulfillmentPickup:
+	case "":
+		reject("type", ReasonRequired)
+	default:
+		reject("type", ReasonInvalid)
+	}
+	out.ContactName = text("contact_name", f.ContactName, maxContactNameLen, false)
+	out.Notes = text("notes", f.Notes, maxNotesLen, false)
+
+	phone, ok := normalizePhone(f.Phone)
+	switch {
+	case strings.TrimSpace(f.Phone) == "":
+		reject("phone", ReasonRequired)
+	case !ok:
+		reject("phone", ReasonInvalid)
+	}
+	out.Phone = phone
+
+	if requested := strings.TrimSpace(f.RequestedTime); requested != "" {
+		at, err := time.Parse(time.RFC3339, requested)
+		switch {
+		case err != nil:
+			reject("requested_time", ReasonInvalid)
+		case !at.After(now):
+			reject("requested_time", ReasonInPast)
+		case at.Sub(now) > maxScheduleAhead:
+			reject("requested_time", ReasonTooFar)
+		}
+		out.RequestedAt = at.UTC()
+	}
+
+	if len(violations) > 0 {
+		st, err := status.New(codes.InvalidArgument, "checkout details are invalid").
+			WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "checkout details are invalid")
+		}
+		return nil, st.Err()
+	}
+	return out, nil
+}
+
+// promoCodeField names the violation reported for an unknown promo code.
+const promoCodeField = "promo_code"
+
+// promoCodeError reports a promo code that is not on offer like invalid
+// checkout details, with a "promo_code" violation.
+func promoCodeError(err error) error {
+	st, detailsErr := status.New(codes.InvalidArgument, err.Error()).
+		WithDetails(&errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{Field: promoCodeField, Description: ReasonInvalid},
+		}})
+	if detailsErr != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return st.Err()
+}
+
+// normalizePhone drops the spaces, dashes, dots and brackets people type in
+// phone numbers, keeping a leading +.
+func normalizePhone(phone string) (string, bool) {
+	phone = strings.TrimSpace(phone)
+	var b strings.Builder
+	digits := 0
+	for i, r := range phone {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+			digits++
+		case r == '+' && i == 0:
+			b.WriteRune(r)
+		case strings.ContainsRune(" -.()", r):
+		default:
+			return "", false
+		}
+	}
+	return b.String(), digits >= minPhoneDigits && digits <= maxPhoneDigits
+}
+
+func toPbFulfillment(f *model.Fulfillment) *pb.Fulfillment {
+	if f == nil {
+		return nil
+	}
+	out := &pb.Fulfillment{
+		Type:        f.Type,
+		ContactName: f.ContactName,
+		Address:     f.Address,
+		Phone:       f.Phone,
+		Notes:       f.Notes,
+	}
+	if !f.RequestedAt.IsZero() {
+		out.RequestedTime = f.RequestedAt.Format(time.RFC3339)
+	}
+	return out
+}
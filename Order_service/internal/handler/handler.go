@@ -2,114 +2,351 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"order/internal/dao"
+	"order/internal/identity"
 	"order/internal/model"
-	nats "order/internal/nats"
-
 	"order/internal/service"
 	pb "order/proto"
 	menupb "order/proto/menu"
 	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type OrderHandler struct {
 	pb.UnimplementedOrderServiceServer
-	svc           *service.OrderService
-	menuClient    menupb.MenuServiceClient
-	natsPublisher *nats.Publisher
+	svc        *service.OrderService
+	menuClient menupb.MenuServiceClient
 }
 
-func NewOrderHandler(
-	svc *service.OrderService,
-	menuClient menupb.MenuServiceClient,
-	natsPublisher *nats.Publisher,
-) *OrderHandler {
+func NewOrderHandler(svc *service.OrderService, menuClient menupb.MenuServiceClient) *OrderHandler {
 	return &OrderHandler{
-		svc:           svc,
-		menuClient:    menuClient,
-		natsPublisher: natsPublisher,
+		svc:        svc,
+		menuClient: menuClient,
 	}
 }
 
 func (h *OrderHandler) CreateOrder(ctx context.Context, req *pb.CreateOrderRequest) (*pb.CreateOrderResponse, error) {
+	caller, ok := identity.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing caller identity")
+	}
+	userID := caller.UserID
+	if req.UserId != "" && req.UserId != caller.UserID {
+		if !caller.IsAdmin() {
+			return nil, status.Error(codes.PermissionDenied, "cannot place an order for another user")
+		}
+		userID = req.UserId
+	}
+
+	requested, err := requestedItems(req)
+	if err != nil {
+		return nil, err
+	}
+	fulfillment, err := fulfillmentFromPb(req.Fulfillment, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	var promoCode string
+	if req.PromoCode != "" {
+		if promoCode, err = h.svc.PromoCode(req.PromoCode); err != nil {
+			return nil, promoCodeError(err)
+		}
+	}
+	if len(req.IdempotencyKey) > maxIdempotencyKeyLen {
+		return nil, status.Errorf(codes.InvalidArgument, "idempotency key is longer than %d characters", maxIdempotencyKeyLen)
+	}
+
+	hash := service.RequestHash(userID, requested, fulfillment, promoCode)
+	id, replayed, err := h.svc.CreateOnce(ctx, userID, req.IdempotencyKey, hash, func() (string, error) {
+		return h.placeOrder(ctx, userID, requested, fulfillment, promoCode)
+	})
+	switch {
+	case errors.Is(err, service.ErrIdempotencyConflict):
+		return nil, status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, service.ErrIdempotencyInProgress):
+		return nil, status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, service.ErrIdempotencyUnavailable):
+		return nil, status.Error(codes.Unavailable, err.Error())
+	case err != nil:
+		return nil, err
+	}
+	if replayed {
+		slog.InfoContext(ctx, "replayed order creation", "order_id", id)
+	}
+
+	return &pb.CreateOrderResponse{Id: id, Replayed: replayed}, nil
+}
+
+const maxIdempotencyKeyLen = 255
+
+// placeOrder prices the requested lines and stores the order; its
+// order.created event goes out through the outbox.
+func (h *OrderHandler) placeOrder(ctx context.Context, userID string, requested []model.OrderItem, fulfillment *model.Fulfillment, promoCode string) (string, error) {
+	ids := make([]string, 0, len(requested))
+	for _, item := range requested {
+		ids = append(ids, item.MenuItemID)
+	}
 	menuRes, err := h.menuClient.GetMultipleMenuItems(ctx, &menupb.GetMultipleMenuItemsRequest{
-		Ids: req.ItemIds,
+		Ids: ids,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch menu items: %v", err)
+		return "", fmt.Errorf("failed to fetch menu items: %v", err)
 	}
 
-	totalPrice := 0.0
-	for _, item := range menuRes.Items {
-		totalPrice += item.Price
+	items, err := priceItems(requested, menuRes.Items)
+	if err != nil {
+		return "", err
 	}
+	return h.svc.CreateOrder(ctx, userID, items, fulfillment, promoCode)
+}
 
-	id, err := h.svc.CreateOrder(ctx, req.UserId, req.ItemIds, totalPrice)
-	if err != nil {
-		return nil, err
+// requestedItems merges the items and legacy item_ids fields of a create
+// request into one line per menu item.
+func requestedItems(req *pb.CreateOrderRequest) ([]model.OrderItem, error) {
+	var items []model.OrderItem
+	index := map[string]int{}
+	add := func(id string, quantity int32) {
+		if i, ok := index[id]; ok {
+			items[i].Quantity += quantity
+			return
+		}
+		index[id] = len(items)
+		items = append(items, model.OrderItem{MenuItemID: id, Quantity: quantity})
 	}
 
-	_ = h.natsPublisher.PublishOrderCreated(map[string]interface{}{
-		"orderId":   id,
-		"userId":    req.UserId,
-		"items":     req.ItemIds,
-		"total":     totalPrice,
-		"createdAt": time.Now().Format(time.RFC3339),
-	})
+	for _, item := range req.Items {
+		if item.Quantity < 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid quantity %d for item %s", item.Quantity, item.MenuItemId)
+		}
+		quantity := item.Quantity
+		if quantity == 0 {
+			quantity = 1
+		}
+		add(item.MenuItemId, quantity)
+	}
+	for _, item := range model.ItemsFromIDs(req.ItemIds) {
+		add(item.MenuItemID, item.Quantity)
+	}
 
-	return &pb.CreateOrderResponse{Id: id}, nil
+	if len(items) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "order has no items")
+	}
+	return items, nil
+}
+
+// Reasons reported for order lines that cannot be placed.
+const (
+	ReasonInvalidID   = "invalid_id"
+	ReasonNotFound    = "not_found"
+	ReasonUnavailable = "unavailable"
+)
+
+// priceItems fills in name and price of each requested line from the menu.
+// Every line that cannot be ordered is reported in a single error carrying a
+// BadRequest detail with one violation per item id.
+func priceItems(requested []model.OrderItem, menuItems []*menupb.MenuItem) ([]model.OrderItem, error) {
+	byID := make(map[string]*menupb.MenuItem, len(menuItems))
+	for _, item := range menuItems {
+		byID[item.Id] = item
+	}
+
+	code := codes.FailedPrecondition
+	var violations []*errdetails.BadRequest_FieldViolation
+	var items []model.OrderItem
+	for _, item := range requested {
+		menuItem, ok := byID[item.MenuItemID]
+		reason := ""
+		switch {
+		case !primitive.IsValidObjectID(item.MenuItemID):
+			reason = ReasonInvalidID
+		case !ok:
+			reason = ReasonNotFound
+		case !menuItem.Available:
+			reason = ReasonUnavailable
+		}
+		if reason != "" {
+			if reason != ReasonUnavailable {
+				code = codes.InvalidArgument
+			}
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{
+				Field:       item.MenuItemID,
+				Description: reason,
+			})
+			continue
+		}
+
+		item.Name = menuItem.Name
+		item.UnitPrice = menuItem.Price
+		item.LineTotal = menuItem.Price * float64(item.Quantity)
+		items = append(items, item)
+	}
+
+	if len(violations) > 0 {
+		st, err := status.New(code, "order contains items that cannot be ordered").
+			WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+		if err != nil {
+			return nil, status.Error(code, "order contains items that cannot be ordered")
+		}
+		return nil, st.Err()
+	}
+	return items, nil
+}
+
+func toPbOrder(order model.Order) *pb.Order {
+	var items []*pb.OrderItem
+	for _, item := range order.LineItems() {
+		items = append(items, &pb.OrderItem{
+			MenuItemId: item.MenuItemID,
+			Name:       item.Name,
+			UnitPrice:  item.UnitPrice,
+			Quantity:   item.Quantity,
+			LineTotal:  item.LineTotal,
+		})
+	}
+
+	var history []*pb.StatusChange
+	for _, change := range order.StatusHistory {
+		history = append(history, &pb.StatusChange{
+			Status:    change.Status,
+			ChangedAt: change.ChangedAt.Format(time.RFC3339),
+			ActorId:   change.ActorID,
+		})
+	}
+
+	return &pb.Order{
+		Id:            order.ID,
+		UserId:        order.UserID,
+		ItemIds:       order.ItemIDs,
+		Items:         items,
+		TotalPrice:    order.TotalPrice,
+		Status:        order.Status,
+		CreatedAt:     order.CreatedAt.Format(time.RFC3339),
+		StatusHistory: history,
+		Fulfillment:   toPbFulfillment(order.Fulfillment),
+		Discount:      order.Discount,
+		DeliveryFee:   order.DeliveryFee,
+		PromoCode:     order.PromoCode,
+	}
+}
+
+// statusError maps service and dao errors onto gRPC status codes.
+func statusError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrUnknownStatus):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrInvalidTransition), errors.Is(err, service.ErrPaymentRequired),
+		errors.Is(err, dao.ErrStatusChanged):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return status.Error(codes.NotFound, "order not found")
+	}
+	return err
 }
 
 func (h *OrderHandler) GetOrder(ctx context.Context, req *pb.GetOrderRequest) (*pb.GetOrderResponse, error) {
 	order, err := h.svc.GetOrder(ctx, req.Id)
 	if err != nil {
-		return nil, err
+		return nil, statusError(err)
 	}
 	return &pb.GetOrderResponse{
-		Order: &pb.Order{
-			Id:         order.ID,
-			UserId:     order.UserID,
-			ItemIds:    order.ItemIDs,
-			TotalPrice: order.TotalPrice,
-			Status:     order.Status,
-			CreatedAt:  order.CreatedAt.String(),
-		},
+		Order: toPbOrder(*order),
 	}, nil
 }
 
 func (h *OrderHandler) UpdateOrder(ctx context.Context, req *pb.UpdateOrderRequest) (*pb.UpdateOrderResponse, error) {
+	caller, ok := identity.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing caller identity")
+	}
+
 	order := model.Order{
 		ID:         req.Id,
 		UserID:     req.UserId,
 		ItemIDs:    req.ItemIds,
+		Items:      fromPbItems(req.Items),
 		TotalPrice: req.TotalPrice,
 		Status:     req.Status,
 	}
+	if len(order.ItemIDs) == 0 {
+		order.ItemIDs = model.ExpandItemIDs(order.Items)
+	}
 
-	err := h.svc.UpdateOrder(ctx, order)
+	err := h.svc.UpdateOrder(ctx, order, caller.UserID)
 	if err != nil {
-		return nil, err
+		return nil, statusError(err)
 	}
 
 	return &pb.UpdateOrderResponse{Message: "Order fully updated"}, nil
 }
+
+func fromPbItems(pbItems []*pb.OrderItem) []model.OrderItem {
+	var items []model.OrderItem
+	for _, item := range pbItems {
+		items = append(items, model.OrderItem{
+			MenuItemID: item.MenuItemId,
+			Name:       item.Name,
+			UnitPrice:  item.UnitPrice,
+			Quantity:   item.Quantity,
+			LineTotal:  item.LineTotal,
+		})
+	}
+	return items
+}
+
 func (h *OrderHandler) PatchOrderStatus(ctx context.Context, req *pb.PatchOrderStatusRequest) (*pb.PatchOrderStatusResponse, error) {
-	err := h.svc.UpdateOrderStatus(ctx, req.Id, req.Status)
+	caller, ok := identity.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing caller identity")
+	}
+
+	err := h.svc.UpdateOrderStatus(ctx, req.Id, req.Status, caller.UserID)
 	if err != nil {
-		return nil, err
+		return nil, statusError(err)
 	}
 
 	return &pb.PatchOrderStatusResponse{Message: "Status updated"}, nil
 }
 
 func (h *OrderHandler) DeleteOrder(ctx context.Context, req *pb.DeleteOrderRequest) (*pb.DeleteOrderResponse, error) {
-	err := h.svc.DeleteOrder(ctx, req.Id)
+	caller, ok := identity.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing caller identity")
+	}
+
+	err := h.svc.DeleteOrder(ctx, req.Id, caller.UserID)
 	if err != nil {
-		return nil, err
+		return nil, statusError(err)
 	}
 	return &pb.DeleteOrderResponse{Message: "Order deleted successfully"}, nil
 }
 
+func (h *OrderHandler) AnonymizeUserOrders(ctx context.Context, req *pb.AnonymizeUserOrdersRequest) (*pb.AnonymizeUserOrdersResponse, error) {
+	caller, ok := identity.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing caller identity")
+	}
+	if req.UserId == "" || req.UserId == model.DeletedUserID {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	if req.UserId != caller.UserID && !caller.IsAdmin() {
+		return nil, status.Error(codes.PermissionDenied, "cannot anonymize orders of another user")
+	}
+
+	n, err := h.svc.AnonymizeUserOrders(ctx, req.UserId)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to anonymize orders")
+	}
+	return &pb.AnonymizeUserOrdersResponse{Anonymized: n}, nil
+}
+
 func (h *OrderHandler) ListOrders(ctx context.Context, req *pb.ListOrdersRequest) (*pb.ListOrdersResponse, error) {
 	orders, err := h.svc.ListOrders(ctx, req.Limit, req.Skip)
 	if err != nil {
@@ -118,14 +355,7 @@ func (h *OrderHandler) ListOrders(ctx context.Context, req *pb.ListOrdersRequest
 
 	var pbOrders []*pb.Order
 	for _, order := range orders {
-		pbOrders = append(pbOrders, &pb.Order{
-			Id:         order.ID,
-			UserId:     order.UserID,
-			ItemIds:    order.ItemIDs,
-			TotalPrice: order.TotalPrice,
-			Status:     order.Status,
-			CreatedAt:  order.CreatedAt.String(),
-		})
+		pbOrders = append(pbOrders, toPbOrder(order))
 	}
 
 	return &pb.ListOrdersResponse{Orders: pbOrders}, nil
@@ -138,14 +368,7 @@ func (h *OrderHandler) ListOrdersByUser(ctx context.Context, req *pb.ListOrdersB
 
 	var pbOrders []*pb.Order
 	for _, order := range orders {
-		pbOrders = append(pbOrders, &pb.Order{
-			Id:         order.ID,
-			UserId:     order.UserID,
-			ItemIds:    order.ItemIDs,
-			TotalPrice: order.TotalPrice,
-			Status:     order.Status,
-			CreatedAt:  order.CreatedAt.String(),
-		})
+		pbOrders = append(pbOrders, toPbOrder(order))
 	}
 
 	return &pb.ListOrdersByUserResponse{
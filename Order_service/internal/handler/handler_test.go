@@ -0,0 +1,144 @@
+package handler_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"order/internal/handler"
+	"order/internal/identity"
+	pb "order/proto"
+	menupb "order/proto/menu"
+)
+
+type stubMenuClient struct {
+	menupb.MenuServiceClient
+	items []*menupb.MenuItem
+}
+
+func (s *stubMenuClient) GetMultipleMenuItems(ctx context.Context, in *menupb.GetMultipleMenuItemsRequest, opts ...grpc.CallOption) (*menupb.GetMultipleMenuItemsResponse, error) {
+	return &menupb.GetMultipleMenuItemsResponse{Items: s.items}, nil
+}
+
+const (
+	burgerID  = "665f1c2b9a1e4b3c2d1e0f01"
+	saladID   = "665f1c2b9a1e4b3c2d1e0f02"
+	deletedID = "665f1c2b9a1e4b3c2d1e0f03"
+)
+
+func callerContext(userID, role string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		identity.UserIDKey, userID,
+		identity.RoleKey, role,
+	))
+}
+
+func violations(t *testing.T, err error) map[string]string {
+	st, ok := status.FromError(err)
+	assert.True(t, ok)
+
+	found := map[string]string{}
+	for _, detail := range st.Details() {
+		if br, ok := detail.(*errdetails.BadRequest); ok {
+			for _, v := range br.FieldViolations {
+				found[v.Field] = v.Description
+			}
+		}
+	}
+	return found
+}
+
+func TestCreateOrder_RejectsInvalidItems(t *testing.T) {
+	menu := &stubMenuClient{items: []*menupb.MenuItem{
+		{Id: burgerID, Name: "Burger", Price: 9.99, Available: true},
+		{Id: saladID, Name: "Salad", Price: 7.99, Available: false},
+	}}
+	h := handler.NewOrderHandler(nil, menu)
+
+	_, err := h.CreateOrder(callerContext("user123", "user"), &pb.CreateOrderRequest{
+		UserId:  "user123",
+		ItemIds: []string{burgerID, saladID, deletedID, "not-a-hex-id"},
+	})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, map[string]string{
+		saladID:        handler.ReasonUnavailable,
+		deletedID:      handler.ReasonNotFound,
+		"not-a-hex-id": handler.ReasonInvalidID,
+	}, violations(t, err))
+}
+
+func TestCreateOrder_UnavailableItemIsFailedPrecondition(t *testing.T) {
+	menu := &stubMenuClient{items: []*menupb.MenuItem{
+		{Id: burgerID, Name: "Burger", Price: 9.99, Available: true},
+		{Id: saladID, Name: "Salad", Price: 7.99, Available: false},
+	}}
+	h := handler.NewOrderHandler(nil, menu)
+
+	_, err := h.CreateOrder(callerContext("user123", "user"), &pb.CreateOrderRequest{
+		UserId: "user123",
+		Items: []*pb.OrderItem{
+			{MenuItemId: burgerID, Quantity: 2},
+			{MenuItemId: saladID, Quantity: 1},
+		},
+	})
+
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.Equal(t, map[string]string{saladID: handler.ReasonUnavailable}, violations(t, err))
+}
+
+func TestCreateOrder_RequiresCallerIdentity(t *testing.T) {
+	h := handler.NewOrderHandler(nil, &stubMenuClient{})
+
+	_, err := h.CreateOrder(context.Background(), &pb.CreateOrderRequest{ItemIds: []string{burgerID}})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = h.CreateOrder(callerContext("user123", "user"), &pb.CreateOrderRequest{
+		UserId:  "victim456",
+		ItemIds: []string{burgerID},
+	})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestCreateOrder_RejectsInvalidFulfillment(t *testing.T) {
+	h := handler.NewOrderHandler(nil, &stubMenuClient{})
+
+	_, err := h.CreateOrder(callerContext("user123", "user"), &pb.CreateOrderRequest{
+		ItemIds: []string{burgerID},
+		Fulfillment: &pb.Fulfillment{
+			Type:          "delivery",
+			Phone:         "call me",
+			Notes:         strings.Repeat("x", 501),
+			RequestedTime: "2020-01-01T12:00:00Z",
+		},
+	})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, map[string]string{
+		"fulfillment.address":        handler.ReasonRequired,
+		"fulfillment.phone":          handler.ReasonInvalid,
+		"fulfillment.notes":          handler.ReasonTooLong,
+		"fulfillment.requested_time": handler.ReasonInPast,
+	}, violations(t, err))
+
+	_, err = h.CreateOrder(callerContext("user123", "user"), &pb.CreateOrderRequest{
+		ItemIds: []string{burgerID},
+		Fulfillment: &pb.Fulfillment{
+			Type:          "drone",
+			RequestedTime: time.Now().Add(30 * 24 * time.Hour).Format(time.RFC3339),
+		},
+	})
+	assert.Equal(t, map[string]string{
+		"fulfillment.type":           handler.ReasonInvalid,
+		"fulfillment.phone":          handler.ReasonRequired,
+		"fulfillment.requested_time": handler.ReasonTooFar,
+	}, violations(t, err))
+}
@@ -0,0 +1,46 @@
+package identity
+
+import (
+	"context"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// Metadata keys the API gateway uses to forward the authenticated caller,
+// taken from the verified JWT rather than from the request body.
+const (
+	UserIDKey = "x-user-id"
+	RoleKey   = "x-user-role"
+
+	RoleAdmin = "admin"
+)
+
+type Caller struct {
+	UserID string
+	Role   string
+}
+
+func (c Caller) IsAdmin() bool {
+	return c.Role == RoleAdmin
+}
+
+// FromContext returns the caller forwarded in incoming gRPC metadata. ok is
+// false when no user id was sent.
+func FromContext(ctx context.Context) (Caller, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return Caller{}, false
+	}
+	caller := Caller{
+		UserID: first(md.Get(UserIDKey)),
+		Role:   first(md.Get(RoleKey)),
+	}
+	return caller, caller.UserID != ""
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
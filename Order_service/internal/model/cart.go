@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// Cart is what a user is about to order. ID is new for every cart and
+// Version grows with each change, so together they name one state of the
+// cart.
+type Cart struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"user_id"`
+	Items     []CartItem `json:"items"`
+	Version   int64      `json:"version"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+type CartItem struct {
+	MenuItemID string `json:"menu_item_id"`
+	Quantity   int32  `json:"quantity"`
+}
+
+// OrderItems returns the cart lines as unpriced order lines.
+func (c Cart) OrderItems() []OrderItem {
+	items := make([]OrderItem, len(c.Items))
+	for i, item := range c.Items {
+		items[i] = OrderItem{MenuItemID: item.MenuItemID, Quantity: item.Quantity}
+	}
+	return items
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// How an order reaches the customer.
+const (
+	FulfillmentDelivery = "delivery"
+	FulfillmentPickup   = "pickup"
+)
+
+// Fulfillment is what the customer filled in at checkout. Address is only
+// set for delivery. A zero RequestedAt means as soon as possible.
+type Fulfillment struct {
+	Type        string    `bson:"type"`
+	ContactName string    `bson:"contact_name,omitempty"`
+	Address     string    `bson:"address,omitempty"`
+	Phone       string    `bson:"phone"`
+	Notes       string    `bson:"notes,omitempty"`
+	RequestedAt time.Time `bson:"requested_at,omitempty"`
+}
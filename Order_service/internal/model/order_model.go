@@ -2,11 +2,73 @@ package model
 
 import "time"
 
+// DeletedUserID owns the orders of deleted accounts.
+const DeletedUserID = "deleted-user"
+
+// PaymentActorID is recorded as the actor of status changes caused by payments.
+const PaymentActorID = "payment-service"
+
 type Order struct {
-	ID         string    `bson:"_id,omitempty"`
-	UserID     string    `bson:"user_id"`
-	ItemIDs    []string  `bson:"item_ids"`
-	TotalPrice float64   `bson:"total_price"`
-	Status     string    `bson:"status"`
-	CreatedAt  time.Time `bson:"created_at"`
+	ID            string         `bson:"_id,omitempty"`
+	UserID        string         `bson:"user_id"`
+	ItemIDs       []string       `bson:"item_ids"`
+	Items         []OrderItem    `bson:"items,omitempty"`
+	TotalPrice    float64        `bson:"total_price"`
+	Status        string         `bson:"status"`
+	StatusHistory []StatusChange `bson:"status_history,omitempty"`
+	// Fulfillment is nil for orders placed without checkout details.
+	Fulfillment *Fulfillment `bson:"fulfillment,omitempty"`
+	CreatedAt   time.Time    `bson:"created_at"`
+	// Discount is what PromoCode took off the line totals and DeliveryFee
+	// what delivery added; TotalPrice already has both applied.
+	Discount    float64 `bson:"discount,omitempty"`
+	DeliveryFee float64 `bson:"delivery_fee,omitempty"`
+	PromoCode   string  `bson:"promo_code,omitempty"`
+}
+
+// OrderItem is a priced order line. Name and UnitPrice are a snapshot taken
+// when the order was placed.
+type OrderItem struct {
+	MenuItemID string  `bson:"menu_item_id"`
+	Name       string  `bson:"name"`
+	UnitPrice  float64 `bson:"unit_price"`
+	Quantity   int32   `bson:"quantity"`
+	LineTotal  float64 `bson:"line_total"`
+}
+
+// LineItems returns the order lines. Orders stored before line items existed
+// only have ItemIDs, so those are grouped into unpriced lines instead.
+func (o Order) LineItems() []OrderItem {
+	if len(o.Items) > 0 {
+		return o.Items
+	}
+	return ItemsFromIDs(o.ItemIDs)
+}
+
+// ItemsFromIDs groups a flat list of menu item ids into lines, counting each
+// repeated id as one more unit and keeping first-seen order.
+func ItemsFromIDs(ids []string) []OrderItem {
+	var items []OrderItem
+	index := map[string]int{}
+	for _, id := range ids {
+		if i, ok := index[id]; ok {
+			items[i].Quantity++
+			continue
+		}
+		index[id] = len(items)
+		items = append(items, OrderItem{MenuItemID: id, Quantity: 1})
+	}
+	return items
+}
+
+// ExpandItemIDs flattens lines back into the legacy item_ids form, repeating
+// an id once per unit.
+func ExpandItemIDs(items []OrderItem) []string {
+	var ids []string
+	for _, item := range items {
+		for i := int32(0); i < item.Quantity; i++ {
+			ids = append(ids, item.MenuItemID)
+		}
+	}
+	return ids
 }
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	StatusPending        = "Pending"
+	StatusConfirmed      = "Confirmed"
+	StatusPreparing      = "Preparing"
+	StatusReadyForPickup = "ReadyForPickup"
+	StatusOutForDelivery = "OutForDelivery"
+	StatusDelivered      = "Delivered"
+	StatusCancelled      = "Cancelled"
+	StatusRefunded       = "Refunded"
+)
+
+// statusTransitions lists, for every known status, the statuses an order may
+// move to next. Refunded is terminal.
+var statusTransitions = map[string][]string{
+	StatusPending:        {StatusConfirmed, StatusCancelled},
+	StatusConfirmed:      {StatusPreparing, StatusCancelled},
+	StatusPreparing:      {StatusReadyForPickup, StatusOutForDelivery, StatusCancelled},
+	StatusReadyForPickup: {StatusDelivered},
+	StatusOutForDelivery: {StatusDelivered},
+	StatusDelivered:      {StatusRefunded},
+	StatusCancelled:      {StatusRefunded},
+	StatusRefunded:       {},
+}
+
+// StatusChange is one entry of an order's status history.
+type StatusChange struct {
+	Status    string    `bson:"status"`
+	ChangedAt time.Time `bson:"changed_at"`
+	ActorID   string    `bson:"actor_id"`
+}
+
+// ParseStatus returns the canonical spelling of a known status, ignoring case
+// and surrounding spaces.
+func ParseStatus(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	for status := range statusTransitions {
+		if strings.EqualFold(status, s) {
+			return status, true
+		}
+	}
+	return "", false
+}
+
+// CanTransition reports whether an order in status from may move to status to.
+func CanTransition(from, to string) bool {
+	for _, next := range statusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
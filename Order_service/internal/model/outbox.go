@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// OutboxEvent is an event stored next to the change it announces, in the same
+// transaction, and published to NATS afterwards by the outbox relay. Payload
+// is an encoded events.Envelope.
+type OutboxEvent struct {
+	ID        string    `bson:"_id,omitempty"`
+	Subject   string    `bson:"subject"`
+	Payload   []byte    `bson:"payload"`
+	RequestID string    `bson:"request_id,omitempty"`
+	CreatedAt time.Time `bson:"created_at"`
+
+	// NextAttemptAt is when the relay may pick the event up; while a relay
+	// publishes it, it doubles as a lease.
+	NextAttemptAt time.Time  `bson:"next_attempt_at"`
+	Attempts      int        `bson:"attempts"`
+	LastError     string     `bson:"last_error,omitempty"`
+	SentAt        *time.Time `bson:"sent_at,omitempty"`
+}
@@ -1,41 +1,90 @@
 package nats
 
 import (
-	"encoding/json"
-	"log"
+	"context"
+	"errors"
+	"foodstore/logging"
+	"log/slog"
+	"time"
 
 	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
 )
 
+// publishTimeout bounds how long Publish waits for JetStream to confirm it
+// stored a message.
+const publishTimeout = 5 * time.Second
+
+// OrdersStream keeps order events for a week, so consumers that were down
+// catch up when they come back. Payment_service declares the same stream;
+// whichever service starts first creates it.
+var OrdersStream = jetstream.StreamConfig{
+	Name:       "ORDERS",
+	Subjects:   []string{"order.>"},
+	Storage:    jetstream.FileStorage,
+	MaxAge:     7 * 24 * time.Hour,
+	Duplicates: 10 * time.Minute,
+}
+
 type Publisher struct {
 	conn *nats.Conn
+	js   jetstream.JetStream
 }
 
+// NewPublisher connects to url. The service starts even if NATS is down and
+// keeps reconnecting; events wait in the outbox meanwhile.
 func NewPublisher(url string) (*Publisher, error) {
-	nc, err := nats.Connect(url)
+	nc, err := nats.Connect(url, nats.RetryOnFailedConnect(true), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, err
+	}
+	js, err := jetstream.New(nc)
 	if err != nil {
+		nc.Close()
 		return nil, err
 	}
-	log.Printf("[NATS]Connected to %s", url)
-	return &Publisher{conn: nc}, nil
+	slog.Info("connecting to NATS", "url", url)
+	return &Publisher{conn: nc, js: js}, nil
 }
 
-func (p *Publisher) PublishOrderCreated(data interface{}) error {
-	bytes, err := json.Marshal(data)
-	if err != nil {
-		log.Printf("[NATS]JSON marshal failed: %v", err)
-		return err
+// Publish stores data on subject in JetStream, so that a nil error means the
+// event is persisted. id lets the stream drop a message it already has, which
+// happens when the relay publishes an event again. The request id of ctx
+// travels in the message header.
+func (p *Publisher) Publish(ctx context.Context, id, subject string, data []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, publishTimeout)
+	defer cancel()
+
+	msg := nats.NewMsg(subject)
+	msg.Data = data
+	if requestID := logging.RequestID(ctx); requestID != "" {
+		msg.Header.Set(logging.RequestIDKey, requestID)
 	}
+	slog.InfoContext(ctx, "publishing event", "subject", msg.Subject, "event_id", id)
 
-	subject := "order.created"
-	log.Printf("[NATS]Publishing: %s → %s", subject, string(bytes))
+	_, err := p.js.PublishMsg(ctx, msg, jetstream.WithMsgID(id))
+	if errors.Is(err, jetstream.ErrNoStreamResponse) {
+		// Nobody has declared the stream yet.
+		if err := EnsureStream(ctx, p.js, OrdersStream); err != nil {
+			return err
+		}
+		_, err = p.js.PublishMsg(ctx, msg, jetstream.WithMsgID(id))
+	}
+	return err
+}
 
-	return p.conn.Publish(subject, bytes)
+// EnsureStream creates the stream described by cfg unless it exists.
+func EnsureStream(ctx context.Context, js jetstream.JetStream, cfg jetstream.StreamConfig) error {
+	_, err := js.CreateStream(ctx, cfg)
+	if errors.Is(err, jetstream.ErrStreamNameAlreadyInUse) {
+		return nil
+	}
+	return err
 }
 
 func (p *Publisher) Close() {
 	if p.conn != nil && !p.conn.IsClosed() {
 		p.conn.Close()
-		log.Println("[NATS]Connection closed")
+		slog.Info("NATS connection closed")
 	}
 }
@@ -0,0 +1,132 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"foodstore/events"
+	"foodstore/logging"
+	"log/slog"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// PaymentsStream keeps payment events for a week. Payment_service publishes
+// to it and declares the same stream; whichever service starts first creates
+// it.
+var PaymentsStream = jetstream.StreamConfig{
+	Name:       "PAYMENTS",
+	Subjects:   []string{"payment.>"},
+	Storage:    jetstream.FileStorage,
+	MaxAge:     7 * 24 * time.Hour,
+	Duplicates: 10 * time.Minute,
+}
+
+// PaymentCapturedConsumer is the durable consumer confirming paid orders. It
+// keeps its position across restarts, so no capture is missed.
+const PaymentCapturedConsumer = "order-payment-captured"
+
+// paymentCapturedAckWait is how long handling one event may take before it is
+// delivered again.
+const paymentCapturedAckWait = 30 * time.Second
+
+// paymentCapturedBackoff[n-1] is the wait after the nth failed delivery; the
+// last entry repeats. An order must not stay unconfirmed, so there is no
+// delivery limit.
+var paymentCapturedBackoff = []time.Duration{time.Second, 10 * time.Second, time.Minute, 5 * time.Minute}
+
+type Subscriber struct {
+	conn *nats.Conn
+	js   jetstream.JetStream
+}
+
+func NewSubscriber(url string) (*Subscriber, error) {
+	nc, err := nats.Connect(url, nats.RetryOnFailedConnect(true), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, err
+	}
+	js, err := jetstream.New(nc)
+	if err != nil {
+		nc.Close()
+		return nil, err
+	}
+	return &Subscriber{conn: nc, js: js}, nil
+}
+
+// OnPaymentCaptured calls handle for every payment.captured event, which
+// PaymentService publishes once the money of an order has been taken. An
+// event is acknowledged only after handle returns nil; otherwise it is
+// delivered again with a growing delay. Malformed events are dropped.
+func (s *Subscriber) OnPaymentCaptured(ctx context.Context, handle func(ctx context.Context, evt *events.PaymentCaptured) error) error {
+	if err := EnsureStream(ctx, s.js, PaymentsStream); err != nil {
+		return err
+	}
+	consumer, err := s.js.CreateOrUpdateConsumer(ctx, PaymentsStream.Name, jetstream.ConsumerConfig{
+		Durable:       PaymentCapturedConsumer,
+		FilterSubject: events.TypePaymentCaptured,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       paymentCapturedAckWait,
+		MaxDeliver:    -1,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = consumer.Consume(func(m jetstream.Msg) {
+		handlePaymentCaptured(m, handle)
+	})
+	return err
+}
+
+func handlePaymentCaptured(m jetstream.Msg, handle func(ctx context.Context, evt *events.PaymentCaptured) error) {
+	ctx := context.Background()
+	if id := m.Headers().Get(logging.RequestIDKey); id != "" {
+		ctx = logging.WithRequestID(ctx, id)
+	}
+
+	var evt events.PaymentCaptured
+	env, err := events.Decode(m.Subject(), m.Data())
+	if err == nil {
+		err = env.Open(&evt)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "dropping invalid event", "subject", m.Subject(), "err", err)
+		if err := m.Term(); err != nil {
+			slog.WarnContext(ctx, "failed to terminate message", "err", err)
+		}
+		return
+	}
+	slog.InfoContext(ctx, "received event", "subject", m.Subject(), "event_id", env.GetEventId(),
+		"order_id", evt.GetOrderId(), "payment_id", evt.GetPaymentId())
+
+	if err := handle(ctx, &evt); err != nil {
+		delay := redeliveryDelay(m)
+		slog.ErrorContext(ctx, "failed to handle event, will retry", "subject", m.Subject(),
+			"order_id", evt.GetOrderId(), "retry_in", delay, "err", err)
+		if err := m.NakWithDelay(delay); err != nil {
+			slog.WarnContext(ctx, "failed to nak message", "err", err)
+		}
+		return
+	}
+	if err := m.DoubleAck(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		// The event comes again and is handled as a no-op.
+		slog.WarnContext(ctx, "failed to ack message", "err", err)
+	}
+}
+
+func redeliveryDelay(m jetstream.Msg) time.Duration {
+	n := 1
+	if meta, err := m.Metadata(); err == nil {
+		n = int(meta.NumDelivered)
+	}
+	if n > len(paymentCapturedBackoff) {
+		n = len(paymentCapturedBackoff)
+	}
+	return paymentCapturedBackoff[n-1]
+}
+
+func (s *Subscriber) Close() {
+	if s.conn != nil && !s.conn.IsClosed() {
+		s.conn.Close()
+	}
+}
@@ -0,0 +1,108 @@
+package nats_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"foodstore/events"
+	"github.com/nats-io/nats-server/v2/server"
+	natslib "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"order/internal/nats"
+)
+
+func runServer(t *testing.T) *server.Server {
+	t.Helper()
+	srv, err := server.NewServer(&server.Options{
+		Host: "127.0.0.1", Port: server.RANDOM_PORT, NoLog: true, NoSigs: true,
+		JetStream: true, StoreDir: t.TempDir(),
+	})
+	require.NoError(t, err)
+	go srv.Start()
+	require.True(t, srv.ReadyForConnections(5*time.Second), "embedded NATS server did not start")
+	t.Cleanup(srv.Shutdown)
+	return srv
+}
+
+func publishCaptured(t *testing.T, url, orderID string) {
+	t.Helper()
+	nc, err := natslib.Connect(url)
+	require.NoError(t, err)
+	defer nc.Close()
+	js, err := jetstream.New(nc)
+	require.NoError(t, err)
+	data, err := events.Marshal(events.TypePaymentCaptured, &events.PaymentCaptured{OrderId: orderID}, "", time.Now())
+	require.NoError(t, err)
+	_, err = js.Publish(context.Background(), events.TypePaymentCaptured, data)
+	require.NoError(t, err)
+}
+
+func TestOnPaymentCaptured_RetriesUntilHandled(t *testing.T) {
+	srv := runServer(t)
+	sub, err := nats.NewSubscriber(srv.ClientURL())
+	require.NoError(t, err)
+	defer sub.Close()
+
+	var mu sync.Mutex
+	var calls []string
+	confirmed := make(chan string, 1)
+	err = sub.OnPaymentCaptured(context.Background(), func(ctx context.Context, evt *events.PaymentCaptured) error {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, evt.GetOrderId())
+		if len(calls) == 1 {
+			return errors.New("mongo: timeout")
+		}
+		confirmed <- evt.GetOrderId()
+		return nil
+	})
+	require.NoError(t, err)
+
+	// Published after the consumer exists and picked up from the stream.
+	publishCaptured(t, srv.ClientURL(), "order1")
+
+	select {
+	case id := <-confirmed:
+		assert.Equal(t, "order1", id)
+	case <-time.After(5 * time.Second):
+		t.Fatal("event was not delivered again after the failure")
+	}
+	mu.Lock()
+	assert.Equal(t, []string{"order1", "order1"}, calls)
+	mu.Unlock()
+}
+
+func TestOnPaymentCaptured_CatchesUpAfterRestart(t *testing.T) {
+	srv := runServer(t)
+	sub, err := nats.NewSubscriber(srv.ClientURL())
+	require.NoError(t, err)
+	// The first run declares the durable consumer and stops.
+	require.NoError(t, sub.OnPaymentCaptured(context.Background(), func(ctx context.Context, evt *events.PaymentCaptured) error {
+		return errors.New("not running")
+	}))
+	sub.Close()
+
+	publishCaptured(t, srv.ClientURL(), "order2")
+
+	sub, err = nats.NewSubscriber(srv.ClientURL())
+	require.NoError(t, err)
+	defer sub.Close()
+	confirmed := make(chan string, 1)
+	require.NoError(t, sub.OnPaymentCaptured(context.Background(), func(ctx context.Context, evt *events.PaymentCaptured) error {
+		confirmed <- evt.GetOrderId()
+		return nil
+	}))
+
+	select {
+	case id := <-confirmed:
+		assert.Equal(t, "order2", id)
+	case <-time.After(5 * time.Second):
+		t.Fatal("event published while the service was down was not delivered")
+	}
+}
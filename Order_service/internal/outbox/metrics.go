@@ -0,0 +1,41 @@
+package outbox
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Metrics describe how far the relay is behind the events written to the
+// outbox.
+type Metrics struct {
+	pending   prometheus.Gauge
+	lag       prometheus.Gauge
+	published *prometheus.CounterVec
+	failures  *prometheus.CounterVec
+	delay     prometheus.Histogram
+}
+
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		pending: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "order_outbox_pending_events",
+			Help: "Events in the outbox that have not been published yet.",
+		}),
+		lag: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "order_outbox_lag_seconds",
+			Help: "Age of the oldest unpublished outbox event, 0 when the outbox is drained.",
+		}),
+		published: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "order_outbox_published_total",
+			Help: "Outbox events published to NATS.",
+		}, []string{"subject"}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "order_outbox_publish_failures_total",
+			Help: "Failed attempts to publish an outbox event.",
+		}, []string{"subject"}),
+		delay: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "order_outbox_publish_delay_seconds",
+			Help:    "Time from writing an event to the outbox until it was published.",
+			Buckets: []float64{0.01, 0.05, 0.1, 0.5, 1, 5, 30, 60, 300, 1800},
+		}),
+	}
+	reg.MustRegister(m.pending, m.lag, m.published, m.failures, m.delay)
+	return m
+}
@@ -0,0 +1,141 @@
+// Package outbox publishes the events stored in the outbox collection.
+package outbox
+
+import (
+	"context"
+	"foodstore/logging"
+	"log/slog"
+	"time"
+
+	"order/internal/dao"
+	"order/internal/model"
+)
+
+// Publisher delivers an event; a nil error means the broker has it. id is
+// the same every time an event is published, so the broker can drop copies.
+type Publisher interface {
+	Publish(ctx context.Context, id, subject string, data []byte) error
+}
+
+type Config struct {
+	// PollInterval is how often the relay looks for due events.
+	PollInterval time.Duration
+	// Lease keeps other relays away from an event while it is published.
+	Lease time.Duration
+	// MinBackoff and MaxBackoff bound the wait after a failed publish, which
+	// doubles with every attempt.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// BatchSize caps the events published per poll.
+	BatchSize int
+}
+
+func DefaultConfig() Config {
+	return Config{
+		PollInterval: time.Second,
+		Lease:        30 * time.Second,
+		MinBackoff:   time.Second,
+		MaxBackoff:   5 * time.Minute,
+		BatchSize:    100,
+	}
+}
+
+// Relay moves events from the outbox to NATS. Delivery is at least once: an
+// event whose publish succeeded but could not be marked sent goes out again.
+type Relay struct {
+	store   dao.OutboxStore
+	pub     Publisher
+	cfg     Config
+	metrics *Metrics
+}
+
+func NewRelay(store dao.OutboxStore, pub Publisher, cfg Config, metrics *Metrics) *Relay {
+	return &Relay{store: store, pub: pub, cfg: cfg, metrics: metrics}
+}
+
+// Run publishes due events every PollInterval until ctx is done.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		r.Drain(ctx)
+		r.observe(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Drain publishes due events, oldest first, until none is due, BatchSize is
+// reached or a publish fails. It returns how many were published.
+func (r *Relay) Drain(ctx context.Context) int {
+	published := 0
+	for published < r.cfg.BatchSize {
+		event, err := r.store.Claim(ctx, time.Now(), r.cfg.Lease)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to read outbox", "err", err)
+			return published
+		}
+		if event == nil {
+			return published
+		}
+		if !r.publish(ctx, event) {
+			return published
+		}
+		published++
+	}
+	return published
+}
+
+func (r *Relay) publish(ctx context.Context, event *model.OutboxEvent) bool {
+	if event.RequestID != "" {
+		ctx = logging.WithRequestID(ctx, event.RequestID)
+	}
+
+	if err := r.pub.Publish(ctx, event.ID, event.Subject, event.Payload); err != nil {
+		r.metrics.failures.WithLabelValues(event.Subject).Inc()
+		retryAt := time.Now().Add(r.backoff(event.Attempts))
+		slog.WarnContext(ctx, "failed to publish outbox event", "event_id", event.ID, "subject", event.Subject,
+			"attempt", event.Attempts, "retry_at", retryAt, "err", err)
+		if err := r.store.MarkFailed(ctx, event.ID, retryAt, err.Error()); err != nil {
+			slog.ErrorContext(ctx, "failed to reschedule outbox event", "event_id", event.ID, "err", err)
+		}
+		return false
+	}
+
+	sentAt := time.Now()
+	r.metrics.published.WithLabelValues(event.Subject).Inc()
+	r.metrics.delay.Observe(sentAt.Sub(event.CreatedAt).Seconds())
+	if err := r.store.MarkSent(ctx, event.ID, sentAt); err != nil {
+		slog.ErrorContext(ctx, "failed to mark outbox event sent", "event_id", event.ID, "err", err)
+	}
+	return true
+}
+
+// backoff is the wait after the given failed attempt, counting from 1.
+func (r *Relay) backoff(attempt int) time.Duration {
+	d := r.cfg.MinBackoff
+	for i := 1; i < attempt && d < r.cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > r.cfg.MaxBackoff {
+		d = r.cfg.MaxBackoff
+	}
+	return d
+}
+
+func (r *Relay) observe(ctx context.Context) {
+	count, oldest, err := r.store.Pending(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to measure outbox lag", "err", err)
+		return
+	}
+	r.metrics.pending.Set(float64(count))
+	if count == 0 {
+		r.metrics.lag.Set(0)
+		return
+	}
+	r.metrics.lag.Set(time.Since(oldest).Seconds())
+}
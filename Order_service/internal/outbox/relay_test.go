@@ -0,0 +1,180 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"order/internal/model"
+	"order/internal/outbox"
+)
+
+// memOutbox is an in-memory dao.OutboxStore.
+type memOutbox struct {
+	events map[string]*model.OutboxEvent
+}
+
+func newMemOutbox(events ...model.OutboxEvent) *memOutbox {
+	m := &memOutbox{events: map[string]*model.OutboxEvent{}}
+	for i := range events {
+		e := events[i]
+		e.NextAttemptAt = e.CreatedAt
+		m.events[e.ID] = &e
+	}
+	return m
+}
+
+func (m *memOutbox) Claim(ctx context.Context, now time.Time, lease time.Duration) (*model.OutboxEvent, error) {
+	var due []*model.OutboxEvent
+	for _, e := range m.events {
+		if e.SentAt == nil && !e.NextAttemptAt.After(now) {
+			due = append(due, e)
+		}
+	}
+	if len(due) == 0 {
+		return nil, nil
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].CreatedAt.Before(due[j].CreatedAt) })
+	e := due[0]
+	e.NextAttemptAt = now.Add(lease)
+	e.Attempts++
+	claimed := *e
+	return &claimed, nil
+}
+
+func (m *memOutbox) MarkSent(ctx context.Context, id string, at time.Time) error {
+	m.events[id].SentAt = &at
+	return nil
+}
+
+func (m *memOutbox) MarkFailed(ctx context.Context, id string, retryAt time.Time, reason string) error {
+	m.events[id].NextAttemptAt = retryAt
+	m.events[id].LastError = reason
+	return nil
+}
+
+func (m *memOutbox) Pending(ctx context.Context) (int64, time.Time, error) {
+	var count int64
+	var oldest time.Time
+	for _, e := range m.events {
+		if e.SentAt != nil {
+			continue
+		}
+		count++
+		if oldest.IsZero() || e.CreatedAt.Before(oldest) {
+			oldest = e.CreatedAt
+		}
+	}
+	return count, oldest, nil
+}
+
+type fakePublisher struct {
+	err      error
+	subjects []string
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, id, subject string, data []byte) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.subjects = append(p.subjects, id+" "+subject+":"+string(data))
+	return nil
+}
+
+func runOnce(t *testing.T, relay *outbox.Relay) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	relay.Run(ctx)
+}
+
+// gauge reads the current value of an unlabelled gauge from reg.
+func gauge(t *testing.T, reg *prometheus.Registry, name string) float64 {
+	t.Helper()
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range families {
+		if f.GetName() == name {
+			return f.GetMetric()[0].GetGauge().GetValue()
+		}
+	}
+	t.Fatalf("metric %s not registered", name)
+	return 0
+}
+
+func TestRelay_PublishesInOrderAndMarksSent(t *testing.T) {
+	now := time.Now()
+	store := newMemOutbox(
+		model.OutboxEvent{ID: "e2", Subject: "order.created", Payload: []byte("2"), CreatedAt: now.Add(-time.Second)},
+		model.OutboxEvent{ID: "e1", Subject: "order.created", Payload: []byte("1"), CreatedAt: now.Add(-time.Minute)},
+	)
+	pub := &fakePublisher{}
+	reg := prometheus.NewRegistry()
+	relay := outbox.NewRelay(store, pub, outbox.DefaultConfig(), outbox.NewMetrics(reg))
+
+	runOnce(t, relay)
+
+	assert.Equal(t, []string{"e1 order.created:1", "e2 order.created:2"}, pub.subjects)
+	assert.NotNil(t, store.events["e1"].SentAt)
+	assert.NotNil(t, store.events["e2"].SentAt)
+
+	count, err := testutil.GatherAndCount(reg, "order_outbox_published_total")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	failures, err := testutil.GatherAndCount(reg, "order_outbox_publish_failures_total")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, failures)
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP order_outbox_pending_events Events in the outbox that have not been published yet.
+# TYPE order_outbox_pending_events gauge
+order_outbox_pending_events 0
+# HELP order_outbox_lag_seconds Age of the oldest unpublished outbox event, 0 when the outbox is drained.
+# TYPE order_outbox_lag_seconds gauge
+order_outbox_lag_seconds 0
+`), "order_outbox_pending_events", "order_outbox_lag_seconds"))
+}
+
+func TestRelay_BacksOffWhenPublishFails(t *testing.T) {
+	created := time.Now().Add(-time.Hour)
+	store := newMemOutbox(model.OutboxEvent{ID: "e1", Subject: "order.created", CreatedAt: created})
+	pub := &fakePublisher{err: errors.New("nats: timeout")}
+	reg := prometheus.NewRegistry()
+	cfg := outbox.DefaultConfig()
+	cfg.MinBackoff = time.Minute
+	relay := outbox.NewRelay(store, pub, cfg, outbox.NewMetrics(reg))
+
+	runOnce(t, relay)
+
+	e := store.events["e1"]
+	assert.Nil(t, e.SentAt)
+	assert.Equal(t, 1, e.Attempts)
+	assert.Equal(t, "nats: timeout", e.LastError)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), e.NextAttemptAt, 5*time.Second)
+
+	// Not due yet, so nothing is retried.
+	assert.Equal(t, 0, relay.Drain(context.Background()))
+
+	// Once due, the next failure waits twice as long.
+	e.NextAttemptAt = time.Now()
+	relay.Drain(context.Background())
+	assert.Equal(t, 2, e.Attempts)
+	assert.WithinDuration(t, time.Now().Add(2*time.Minute), e.NextAttemptAt, 5*time.Second)
+
+	assert.Equal(t, 1.0, gauge(t, reg, "order_outbox_pending_events"))
+	assert.InDelta(t, time.Hour.Seconds(), gauge(t, reg, "order_outbox_lag_seconds"), 5)
+
+	// When NATS is back, the event goes out.
+	pub.err = nil
+	e.NextAttemptAt = time.Now()
+	assert.Equal(t, 1, relay.Drain(context.Background()))
+	assert.NotNil(t, e.SentAt)
+}
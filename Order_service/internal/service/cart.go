@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"order/internal/dao"
+	"order/internal/model"
+	"time"
+)
+
+var (
+	ErrInvalidQuantity = fmt.Errorf("quantity must be between 1 and %d", MaxItemQuantity)
+	ErrCartTooLarge    = fmt.Errorf("a cart holds at most %d different items", MaxCartLines)
+	ErrNotInCart       = errors.New("item is not in the cart")
+	ErrCartEmpty       = errors.New("cart is empty")
+)
+
+// Limits on what a single cart may hold.
+const (
+	MaxCartLines    = 50
+	MaxItemQuantity = 99
+)
+
+// DefaultCartTTL is how long an untouched cart is kept.
+const DefaultCartTTL = 7 * 24 * time.Hour
+
+// CartService keeps one cart per user. Every change refreshes the TTL of
+// the cart, so only abandoned carts expire.
+type CartService struct {
+	store  dao.CartStore
+	orders *OrderService
+	ttl    time.Duration
+}
+
+// NewCartService returns a cart service whose carts check out through
+// orders. A ttl of zero means DefaultCartTTL.
+func NewCartService(store dao.CartStore, orders *OrderService, ttl time.Duration) *CartService {
+	if ttl <= 0 {
+		ttl = DefaultCartTTL
+	}
+	return &CartService{store: store, orders: orders, ttl: ttl}
+}
+
+func (s *CartService) Get(ctx context.Context, userID string) (*model.Cart, error) {
+	return s.store.Get(ctx, userID)
+}
+
+// AddItem adds quantity of an item to the cart, on top of what is already
+// there.
+func (s *CartService) AddItem(ctx context.Context, userID, itemID string, quantity int32) (*model.Cart, error) {
+	if quantity < 1 || quantity > MaxItemQuantity {
+		return nil, ErrInvalidQuantity
+	}
+	return s.store.Update(ctx, userID, s.ttl, func(cart *model.Cart) error {
+		if i := cartLine(cart, itemID); i >= 0 {
+			if cart.Items[i].Quantity+quantity > MaxItemQuantity {
+				return ErrInvalidQuantity
+			}
+			cart.Items[i].Quantity += quantity
+			return nil
+		}
+		if len(cart.Items) >= MaxCartLines {
+			return ErrCartTooLarge
+		}
+		cart.Items = append(cart.Items, model.CartItem{MenuItemID: itemID, Quantity: quantity})
+		return nil
+	})
+}
+
+// SetQuantity replaces the quantity of an item already in the cart; zero
+// removes it.
+func (s *CartService) SetQuantity(ctx context.Context, userID, itemID string, quantity int32) (*model.Cart, error) {
+	if quantity == 0 {
+		return s.RemoveItem(ctx, userID, itemID)
+	}
+	if quantity < 0 || quantity > MaxItemQuantity {
+		return nil, ErrInvalidQuantity
+	}
+	return s.store.Update(ctx, userID, s.ttl, func(cart *model.Cart) error {
+		i := cartLine(cart, itemID)
+		if i < 0 {
+			return ErrNotInCart
+		}
+		cart.Items[i].Quantity = quantity
+		return nil
+	})
+}
+
+func (s *CartService) RemoveItem(ctx context.Context, userID, itemID string) (*model.Cart, error) {
+	return s.store.Update(ctx, userID, s.ttl, func(cart *model.Cart) error {
+		i := cartLine(cart, itemID)
+		if i < 0 {
+			return ErrNotInCart
+		}
+		cart.Items = append(cart.Items[:i], cart.Items[i+1:]...)
+		return nil
+	})
+}
+
+func (s *CartService) Clear(ctx context.Context, userID string) error {
+	_, err := s.store.Update(ctx, userID, s.ttl, func(cart *model.Cart) error {
+		cart.Items = nil
+		return nil
+	})
+	return err
+}
+
+// Checkout turns the cart into an order with place. The idempotency key is
+// derived from the cart and its version, so a double submit of the same
+// cart places a single order. The cart is emptied afterwards unless it was
+// changed meanwhile. Checking out the same cart again with other fulfillment
+// details or promo code fails with ErrIdempotencyConflict. An unknown promo
+// code fails with ErrUnknownPromoCode; place gets the code as stored.
+func (s *CartService) Checkout(ctx context.Context, userID string, fulfillment *model.Fulfillment, promoCode string, place func(items []model.OrderItem, promoCode string) (string, error)) (id string, replayed bool, err error) {
+	promoCode, err = s.orders.PromoCode(promoCode)
+	if err != nil {
+		return "", false, err
+	}
+	cart, err := s.store.Get(ctx, userID)
+	if err != nil {
+		return "", false, err
+	}
+	if len(cart.Items) == 0 {
+		return "", false, ErrCartEmpty
+	}
+
+	items := cart.OrderItems()
+	key := fmt.Sprintf("cart:%s:%d", cart.ID, cart.Version)
+	id, replayed, err = s.orders.CreateOnce(ctx, userID, key, RequestHash(userID, items, fulfillment, promoCode), func() (string, error) {
+		return place(items, promoCode)
+	})
+	if err != nil {
+		return "", false, err
+	}
+	if _, err := s.store.Remove(ctx, *cart); err != nil {
+		slog.WarnContext(ctx, "failed to clear checked out cart", "order_id", id, "err", err)
+	}
+	return id, replayed, nil
+}
+
+func cartLine(cart *model.Cart, itemID string) int {
+	for i, item := range cart.Items {
+		if item.MenuItemID == itemID {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,194 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"order/internal/dao"
+	"order/internal/model"
+	"order/internal/service"
+)
+
+// memCartStore keeps carts in a map, versioned like the Redis store.
+type memCartStore struct {
+	carts map[string]model.Cart
+	ids   int
+}
+
+func newMemCartStore() *memCartStore {
+	return &memCartStore{carts: map[string]model.Cart{}}
+}
+
+func (s *memCartStore) Get(ctx context.Context, userID string) (*model.Cart, error) {
+	cart, ok := s.carts[userID]
+	if !ok {
+		return &model.Cart{UserID: userID}, nil
+	}
+	cart.Items = append([]model.CartItem(nil), cart.Items...)
+	return &cart, nil
+}
+
+func (s *memCartStore) Update(ctx context.Context, userID string, ttl time.Duration, change func(*model.Cart) error) (*model.Cart, error) {
+	cart, _ := s.Get(ctx, userID)
+	if err := change(cart); err != nil {
+		return nil, err
+	}
+	if cart.ID == "" {
+		s.ids++
+		cart.ID = fmt.Sprintf("cart%d", s.ids)
+	}
+	cart.Version++
+	if len(cart.Items) == 0 {
+		delete(s.carts, userID)
+	} else {
+		s.carts[userID] = *cart
+	}
+	return cart, nil
+}
+
+func (s *memCartStore) Remove(ctx context.Context, cart model.Cart) (bool, error) {
+	current, ok := s.carts[cart.UserID]
+	if !ok || current.ID != cart.ID || current.Version != cart.Version {
+		return false, nil
+	}
+	delete(s.carts, cart.UserID)
+	return true, nil
+}
+
+var _ dao.CartStore = (*memCartStore)(nil)
+
+func TestCartService_Items(t *testing.T) {
+	svc := service.NewCartService(newMemCartStore(), nil, 0)
+	ctx := context.Background()
+
+	_, err := svc.AddItem(ctx, "user1", "burger", 2)
+	assert.NoError(t, err)
+	cart, err := svc.AddItem(ctx, "user1", "burger", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.CartItem{{MenuItemID: "burger", Quantity: 3}}, cart.Items)
+
+	_, err = svc.AddItem(ctx, "user1", "burger", service.MaxItemQuantity)
+	assert.ErrorIs(t, err, service.ErrInvalidQuantity)
+	_, err = svc.AddItem(ctx, "user1", "salad", 0)
+	assert.ErrorIs(t, err, service.ErrInvalidQuantity)
+
+	cart, err = svc.AddItem(ctx, "user1", "salad", 1)
+	assert.NoError(t, err)
+	cart, err = svc.SetQuantity(ctx, "user1", "burger", 5)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.CartItem{{MenuItemID: "burger", Quantity: 5}, {MenuItemID: "salad", Quantity: 1}}, cart.Items)
+
+	_, err = svc.SetQuantity(ctx, "user1", "soup", 1)
+	assert.ErrorIs(t, err, service.ErrNotInCart)
+
+	cart, err = svc.SetQuantity(ctx, "user1", "burger", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.CartItem{{MenuItemID: "salad", Quantity: 1}}, cart.Items)
+
+	other, err := svc.Get(ctx, "user2")
+	assert.NoError(t, err)
+	assert.Empty(t, other.Items)
+
+	assert.NoError(t, svc.Clear(ctx, "user1"))
+	cart, err = svc.Get(ctx, "user1")
+	assert.NoError(t, err)
+	assert.Empty(t, cart.Items)
+}
+
+func TestCartService_LimitsLines(t *testing.T) {
+	svc := service.NewCartService(newMemCartStore(), nil, 0)
+	ctx := context.Background()
+
+	for i := 0; i < service.MaxCartLines; i++ {
+		_, err := svc.AddItem(ctx, "user1", fmt.Sprintf("item%d", i), 1)
+		assert.NoError(t, err)
+	}
+	_, err := svc.AddItem(ctx, "user1", "one-too-many", 1)
+	assert.ErrorIs(t, err, service.ErrCartTooLarge)
+}
+
+func TestCartService_Checkout(t *testing.T) {
+	store := newMemCartStore()
+	orders := service.NewOrderService(new(MockOrderDao), &memIdempotencyStore{records: map[string]dao.IdempotencyRecord{}}, service.Pricing{})
+	svc := service.NewCartService(store, orders, 0)
+	ctx := context.Background()
+
+	_, _, err := svc.Checkout(ctx, "user1", nil, "", nil)
+	assert.ErrorIs(t, err, service.ErrCartEmpty)
+
+	_, err = svc.AddItem(ctx, "user1", "burger", 2)
+	assert.NoError(t, err)
+
+	var placed [][]model.OrderItem
+	place := func(items []model.OrderItem, promoCode string) (string, error) {
+		placed = append(placed, items)
+		return "order1", nil
+	}
+	id, replayed, err := svc.Checkout(ctx, "user1", nil, "", place)
+	assert.NoError(t, err)
+	assert.False(t, replayed)
+	assert.Equal(t, "order1", id)
+	assert.Equal(t, [][]model.OrderItem{{{MenuItemID: "burger", Quantity: 2}}}, placed)
+
+	cart, err := svc.Get(ctx, "user1")
+	assert.NoError(t, err)
+	assert.Empty(t, cart.Items)
+}
+
+func TestCartService_CheckoutSameCartOnce(t *testing.T) {
+	store := newMemCartStore()
+	orders := service.NewOrderService(new(MockOrderDao), &memIdempotencyStore{records: map[string]dao.IdempotencyRecord{}}, service.Pricing{})
+	svc := service.NewCartService(store, orders, 0)
+	ctx := context.Background()
+
+	_, err := svc.AddItem(ctx, "user1", "burger", 1)
+	assert.NoError(t, err)
+	cart, _ := store.Get(ctx, "user1")
+
+	// The cart changes while the first checkout places its order, so it
+	// must not be cleared; checking out the same state again must not place
+	// a second order.
+	placed := 0
+	place := func(items []model.OrderItem, promoCode string) (string, error) {
+		placed++
+		store.carts["user1"] = model.Cart{UserID: "user1", ID: cart.ID, Version: cart.Version + 1, Items: cart.Items}
+		return "order1", nil
+	}
+	_, _, err = svc.Checkout(ctx, "user1", nil, "", place)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, store.carts["user1"].Items)
+
+	store.carts["user1"] = *cart
+	id, replayed, err := svc.Checkout(ctx, "user1", nil, "", place)
+	assert.NoError(t, err)
+	assert.True(t, replayed)
+	assert.Equal(t, "order1", id)
+	assert.Equal(t, 1, placed)
+	assert.Empty(t, store.carts["user1"].Items)
+}
+
+func TestCartService_CheckoutWithOtherFulfillmentConflicts(t *testing.T) {
+	store := newMemCartStore()
+	orders := service.NewOrderService(new(MockOrderDao), &memIdempotencyStore{records: map[string]dao.IdempotencyRecord{}}, service.Pricing{})
+	svc := service.NewCartService(store, orders, 0)
+	ctx := context.Background()
+
+	_, err := svc.AddItem(ctx, "user1", "burger", 1)
+	assert.NoError(t, err)
+	cart, _ := store.Get(ctx, "user1")
+	place := func(items []model.OrderItem, promoCode string) (string, error) { return "order1", nil }
+
+	pickup := &model.Fulfillment{Type: model.FulfillmentPickup, Phone: "+15551234567"}
+	_, _, err = svc.Checkout(ctx, "user1", pickup, "", place)
+	assert.NoError(t, err)
+
+	// A resubmit of the same cart with another phone is not a replay.
+	store.carts["user1"] = *cart
+	otherPhone := &model.Fulfillment{Type: model.FulfillmentPickup, Phone: "+15557654321"}
+	_, _, err = svc.Checkout(ctx, "user1", otherPhone, "", place)
+	assert.ErrorIs(t, err, service.ErrIdempotencyConflict)
+}
@@ -2,51 +2,408 @@ package service
 
 import (
 	"context"
-	"go.mongodb.org/mongo-driver/bson"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"foodstore/events"
+	"foodstore/logging"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"log/slog"
 	"order/internal/dao"
 	"order/internal/model"
+	"slices"
+	"sort"
+	"strconv"
 	"time"
 )
 
+var (
+	ErrUnknownStatus     = errors.New("unknown order status")
+	ErrInvalidTransition = errors.New("invalid order status transition")
+	ErrPaymentRequired   = errors.New("orders are confirmed by their payment, not by hand")
+
+	ErrIdempotencyConflict    = errors.New("idempotency key was already used for a different request")
+	ErrIdempotencyInProgress  = errors.New("a request with this idempotency key is still in progress")
+	ErrIdempotencyUnavailable = errors.New("idempotency keys cannot be checked right now")
+)
+
+const (
+	// IdempotencyLease is how long a key stays reserved while its order is
+	// created. It frees the key of a request that died half way.
+	IdempotencyLease = time.Minute
+	// IdempotencyTTL is how long a key answers with the order created for it.
+	IdempotencyTTL = 24 * time.Hour
+)
+
 type OrderService struct {
-	repo *dao.OrderDao
+	repo        dao.OrderRepository
+	idempotency dao.IdempotencyStore
+	pricing     Pricing
 }
 
-func NewOrderService(repo *dao.OrderDao) *OrderService {
-	return &OrderService{repo: repo}
+// NewOrderService returns an order service. idempotency may be nil, in which
+// case idempotency keys are ignored.
+func NewOrderService(repo dao.OrderRepository, idempotency dao.IdempotencyStore, pricing Pricing) *OrderService {
+	return &OrderService{repo: repo, idempotency: idempotency, pricing: pricing}
 }
 
-func (s *OrderService) CreateOrder(ctx context.Context, userID string, itemIDs []string, totalPrice float64) (string, error) {
+// PromoCode returns code as it is stored on orders, failing with
+// ErrUnknownPromoCode when it is not on offer.
+func (s *OrderService) PromoCode(code string) (string, error) {
+	return s.pricing.PromoCode(code)
+}
+
+// CreateOnce runs create at most once per idempotency key of a user. Repeating
+// the request returns the order id of the first run with replayed set, while
+// reusing the key for a different request, whose hash differs, fails with
+// ErrIdempotencyConflict. Without a key create simply runs. With a key but
+// an unreachable store it fails with ErrIdempotencyUnavailable, since the
+// client relies on the key to retry safely.
+func (s *OrderService) CreateOnce(ctx context.Context, userID, key, hash string, create func() (string, error)) (id string, replayed bool, err error) {
+	if key == "" || s.idempotency == nil {
+		id, err = create()
+		return id, false, err
+	}
+
+	scoped := userID + ":" + key
+	existing, reserved, err := s.idempotency.Reserve(ctx, scoped, hash, IdempotencyLease)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to reserve idempotency key", "err", err)
+		return "", false, ErrIdempotencyUnavailable
+	}
+	if !reserved {
+		switch {
+		case existing.RequestHash != hash:
+			return "", false, ErrIdempotencyConflict
+		case existing.Result == "":
+			return "", false, ErrIdempotencyInProgress
+		}
+		return existing.Result, true, nil
+	}
+
+	id, err = create()
+	if err != nil {
+		if releaseErr := s.idempotency.Release(ctx, scoped); releaseErr != nil {
+			slog.WarnContext(ctx, "failed to release idempotency key", "err", releaseErr)
+		}
+		return "", false, err
+	}
+	record := dao.IdempotencyRecord{RequestHash: hash, Result: id}
+	if err := s.idempotency.Complete(ctx, scoped, record, IdempotencyTTL); err != nil {
+		slog.WarnContext(ctx, "failed to store idempotency result", "order_id", id, "err", err)
+	}
+	return id, false, nil
+}
+
+// RequestHash fingerprints an order request, so that a replay with the same
+// items in a different order still matches while other fulfillment details,
+// such as a changed address, or another promo code do not.
+func RequestHash(userID string, items []model.OrderItem, fulfillment *model.Fulfillment, promoCode string) string {
+	lines := make([]string, 0, len(items))
+	for _, item := range items {
+		lines = append(lines, item.MenuItemID+"x"+strconv.Itoa(int(item.Quantity)))
+	}
+	sort.Strings(lines)
+
+	h := sha256.New()
+	h.Write([]byte(userID))
+	for _, line := range lines {
+		h.Write([]byte{0})
+		h.Write([]byte(line))
+	}
+	if f := fulfillment; f != nil {
+		requestedAt := ""
+		if !f.RequestedAt.IsZero() {
+			requestedAt = f.RequestedAt.UTC().Format(time.RFC3339Nano)
+		}
+		for _, field := range []string{f.Type, f.ContactName, f.Address, f.Phone, f.Notes, requestedAt} {
+			h.Write([]byte{1})
+			h.Write([]byte(field))
+		}
+	}
+	if promoCode != "" {
+		h.Write([]byte{2})
+		h.Write([]byte(promoCode))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// CreateOrder stores a new order of priced items. fulfillment may be nil for
+// orders placed without checkout details, and promoCode empty. The total is
+// the line totals less the discount of promoCode, plus the delivery fee of
+// delivery orders.
+func (s *OrderService) CreateOrder(ctx context.Context, userID string, items []model.OrderItem, fulfillment *model.Fulfillment, promoCode string) (string, error) {
+	now := time.Now()
 	order := model.Order{
-		UserID:     userID,
-		ItemIDs:    itemIDs,
-		TotalPrice: totalPrice,
-		Status:     "Pending",
-		CreatedAt:  time.Now(),
+		UserID:  userID,
+		ItemIDs: model.ExpandItemIDs(items),
+		Items:   items,
+		Status:  model.StatusPending,
+		StatusHistory: []model.StatusChange{
+			{Status: model.StatusPending, ChangedAt: now, ActorID: userID},
+		},
+		Fulfillment: fulfillment,
+		CreatedAt:   now,
+		PromoCode:   promoCode,
+	}
+	if err := s.pricing.price(&order); err != nil {
+		return "", err
+	}
+	return s.repo.Create(ctx, order, func(orderID string) (model.OutboxEvent, error) {
+		return outboxEvent(ctx, events.TypeOrderCreated, &events.OrderCreated{
+			OrderId:     orderID,
+			UserId:      userID,
+			ItemIds:     order.ItemIDs,
+			Lines:       eventLines(items),
+			Total:       order.TotalPrice,
+			CreatedAt:   timestamppb.New(now),
+			Fulfillment: eventFulfillment(fulfillment),
+			Discount:    order.Discount,
+			DeliveryFee: order.DeliveryFee,
+			PromoCode:   order.PromoCode,
+		}, now)
+	})
+}
+
+func eventFulfillment(f *model.Fulfillment) *events.Fulfillment {
+	if f == nil {
+		return nil
+	}
+	out := &events.Fulfillment{
+		Type:        f.Type,
+		ContactName: f.ContactName,
+		Address:     f.Address,
+		Phone:       f.Phone,
+		Notes:       f.Notes,
+	}
+	if !f.RequestedAt.IsZero() {
+		out.RequestedAt = timestamppb.New(f.RequestedAt)
+	}
+	return out
+}
+
+// outboxEvent wraps msg in an envelope of eventType, ready for the outbox.
+func outboxEvent(ctx context.Context, eventType string, msg proto.Message, at time.Time) (model.OutboxEvent, error) {
+	payload, err := events.Marshal(eventType, msg, logging.RequestID(ctx), at)
+	return model.OutboxEvent{
+		Subject:   eventType,
+		Payload:   payload,
+		RequestID: logging.RequestID(ctx),
+		CreatedAt: at,
+	}, err
+}
+
+// statusEvents announces that order moves to next: order.status_changed,
+// and order.cancelled as well when next is Cancelled.
+func statusEvents(ctx context.Context, order model.Order, next, actorID string, at time.Time) ([]model.OutboxEvent, error) {
+	changed, err := outboxEvent(ctx, events.TypeOrderStatusChanged, &events.OrderStatusChanged{
+		OrderId:    order.ID,
+		UserId:     order.UserID,
+		FromStatus: order.Status,
+		ToStatus:   next,
+		ChangedBy:  actorID,
+		Total:      order.TotalPrice,
+	}, at)
+	if err != nil || next != model.StatusCancelled {
+		return []model.OutboxEvent{changed}, err
+	}
+	cancelled, err := cancelledEvent(ctx, order, actorID, false, at)
+	return []model.OutboxEvent{changed, cancelled}, err
+}
+
+func cancelledEvent(ctx context.Context, order model.Order, actorID string, deleted bool, at time.Time) (model.OutboxEvent, error) {
+	return outboxEvent(ctx, events.TypeOrderCancelled, &events.OrderCancelled{
+		OrderId:        order.ID,
+		UserId:         order.UserID,
+		PreviousStatus: order.Status,
+		CancelledBy:    actorID,
+		Deleted:        deleted,
+		Total:          order.TotalPrice,
+	}, at)
+}
+
+func snapshot(order model.Order) *events.OrderSnapshot {
+	return &events.OrderSnapshot{
+		Status:  order.Status,
+		ItemIds: order.ItemIDs,
+		Lines:   eventLines(order.Items),
+		Total:   order.TotalPrice,
+	}
+}
+
+func eventLines(items []model.OrderItem) []*events.OrderLine {
+	if len(items) == 0 {
+		return nil
 	}
-	return s.repo.Create(ctx, order)
+	lines := make([]*events.OrderLine, len(items))
+	for i, item := range items {
+		lines[i] = &events.OrderLine{
+			MenuItemId: item.MenuItemID,
+			Name:       item.Name,
+			Quantity:   item.Quantity,
+			UnitPrice:  item.UnitPrice,
+			LineTotal:  item.LineTotal,
+		}
+	}
+	return lines
+}
+
+// OrderTotal sums the line totals of already priced items.
+func OrderTotal(items []model.OrderItem) float64 {
+	total := 0.0
+	for _, item := range items {
+		total += item.LineTotal
+	}
+	return total
 }
 
 func (s *OrderService) GetOrder(ctx context.Context, id string) (*model.Order, error) {
 	return s.repo.GetByID(ctx, id)
 }
 
-func (s *OrderService) UpdateOrderStatus(ctx context.Context, id string, status string) error {
-	return s.repo.UpdateStatus(ctx, id, status)
+// UpdateOrderStatus moves an order to status on behalf of actorID, rejecting
+// unknown statuses and transitions the order lifecycle does not allow.
+// Confirmed is reserved for ConfirmPaidOrder.
+func (s *OrderService) UpdateOrderStatus(ctx context.Context, id string, status string, actorID string) error {
+	next, ok := model.ParseStatus(status)
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownStatus, status)
+	}
+	if next == model.StatusConfirmed {
+		return ErrPaymentRequired
+	}
+
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.changeStatus(ctx, order, next, actorID)
 }
-func (s *OrderService) UpdateOrder(ctx context.Context, order model.Order) error {
 
-	_, err := s.repo.Collection.ReplaceOne(
-		ctx,
-		bson.M{"_id": order.ID},
-		order,
-	)
-	return err
+// ConfirmPaidOrder confirms a pending order once PaymentService captured its
+// payment. Events may be delivered more than once, so an order that is
+// already confirmed is left alone.
+func (s *OrderService) ConfirmPaidOrder(ctx context.Context, id string) error {
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if order.Status == model.StatusConfirmed {
+		return nil
+	}
+	return s.changeStatus(ctx, order, model.StatusConfirmed, model.PaymentActorID)
 }
 
-func (s *OrderService) DeleteOrder(ctx context.Context, id string) error {
-	return s.repo.Delete(ctx, id)
+func (s *OrderService) changeStatus(ctx context.Context, order *model.Order, next string, actorID string) error {
+	if !model.CanTransition(order.Status, next) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, order.Status, next)
+	}
+
+	now := time.Now()
+	evts, err := statusEvents(ctx, *order, next, actorID, now)
+	if err != nil {
+		return err
+	}
+	return s.repo.UpdateStatus(ctx, order.ID, order.Status, model.StatusChange{
+		Status:    next,
+		ChangedAt: now,
+		ActorID:   actorID,
+	}, evts...)
 }
+
+// UpdateOrder replaces an order. Creation time, status history and checkout
+// details are kept from the stored order, and a status change goes through
+// the same checks as UpdateOrderStatus. New items or a new total are
+// announced as order.updated, a new status like in UpdateOrderStatus. If the
+// stored order changes status meanwhile, it fails with dao.ErrStatusChanged.
+func (s *OrderService) UpdateOrder(ctx context.Context, order model.Order, actorID string) error {
+	existing, err := s.repo.GetByID(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	order.CreatedAt = existing.CreatedAt
+	order.StatusHistory = existing.StatusHistory
+	order.Fulfillment = existing.Fulfillment
+	order.Discount = existing.Discount
+	order.DeliveryFee = existing.DeliveryFee
+	order.PromoCode = existing.PromoCode
+	next := existing.Status
+	if order.Status != "" && order.Status != existing.Status {
+		var ok bool
+		next, ok = model.ParseStatus(order.Status)
+		if !ok {
+			return fmt.Errorf("%w: %q", ErrUnknownStatus, order.Status)
+		}
+	}
+	order.Status = existing.Status
+
+	var evts []model.OutboxEvent
+	if !sameContents(*existing, order) {
+		evt, err := outboxEvent(ctx, events.TypeOrderUpdated, &events.OrderUpdated{
+			OrderId:   order.ID,
+			UserId:    order.UserID,
+			Before:    snapshot(*existing),
+			After:     snapshot(order),
+			UpdatedBy: actorID,
+		}, now)
+		if err != nil {
+			return err
+		}
+		evts = append(evts, evt)
+	}
+
+	if next != existing.Status {
+		if next == model.StatusConfirmed {
+			return ErrPaymentRequired
+		}
+		if !model.CanTransition(existing.Status, next) {
+			return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, existing.Status, next)
+		}
+		statusEvts, err := statusEvents(ctx, order, next, actorID, now)
+		if err != nil {
+			return err
+		}
+		evts = append(evts, statusEvts...)
+		order.StatusHistory = append(order.StatusHistory, model.StatusChange{
+			Status:    next,
+			ChangedAt: now,
+			ActorID:   actorID,
+		})
+	}
+	order.Status = next
+
+	return s.repo.Replace(ctx, order, existing.Status, evts...)
+}
+
+// sameContents reports whether a and b have the same items and total.
+func sameContents(a, b model.Order) bool {
+	return a.TotalPrice == b.TotalPrice && slices.Equal(a.ItemIDs, b.ItemIDs) && slices.Equal(a.Items, b.Items)
+}
+
+// DeleteOrder removes an order on behalf of actorID and announces it as
+// order.cancelled with deleted set.
+func (s *OrderService) DeleteOrder(ctx context.Context, id string, actorID string) error {
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	evt, err := cancelledEvent(ctx, *order, actorID, true, time.Now())
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id, evt)
+}
+
+// AnonymizeUserOrders hands the orders of a deleted account over to
+// model.DeletedUserID so they stay in the books without pointing at anyone.
+func (s *OrderService) AnonymizeUserOrders(ctx context.Context, userID string) (int64, error) {
+	return s.repo.ReassignUser(ctx, userID, model.DeletedUserID)
+}
+
 func (s *OrderService) ListOrdersByUser(ctx context.Context, userId string) ([]model.Order, error) {
 	return s.repo.FindOrdersByUserId(ctx, userId)
 }
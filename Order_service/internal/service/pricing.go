@@ -0,0 +1,59 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"order/internal/model"
+	"strings"
+)
+
+// ErrUnknownPromoCode is returned for a promo code that is not on offer.
+var ErrUnknownPromoCode = errors.New("unknown promo code")
+
+// Pricing is what an order costs besides its line totals.
+type Pricing struct {
+	// DeliveryFee is added to delivery orders.
+	DeliveryFee float64
+	// PromoCodes maps an upper case promo code to the percentage it takes
+	// off the line totals.
+	PromoCodes map[string]float64
+}
+
+// PromoCode returns code as it is stored on orders, failing with
+// ErrUnknownPromoCode when it is not on offer. An empty code is no code.
+func (p Pricing) PromoCode(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return "", nil
+	}
+	if _, ok := p.PromoCodes[code]; !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownPromoCode, code)
+	}
+	return code, nil
+}
+
+// price sets the discount, delivery fee and total of an order from its
+// items, fulfillment and promo code.
+func (p Pricing) price(order *model.Order) error {
+	code, err := p.PromoCode(order.PromoCode)
+	if err != nil {
+		return err
+	}
+	subtotal := OrderTotal(order.Items)
+	order.PromoCode = code
+	order.Discount = 0
+	if code != "" {
+		order.Discount = roundCents(subtotal * p.PromoCodes[code] / 100)
+	}
+	order.DeliveryFee = 0
+	if f := order.Fulfillment; f != nil && f.Type == model.FulfillmentDelivery {
+		order.DeliveryFee = p.DeliveryFee
+	}
+	order.TotalPrice = roundCents(subtotal - order.Discount + order.DeliveryFee)
+	return nil
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
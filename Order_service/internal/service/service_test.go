@@ -3,22 +3,37 @@ package service_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
+	"foodstore/events"
+	"foodstore/logging"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"google.golang.org/protobuf/proto"
 
+	"order/internal/dao"
 	"order/internal/model"
 	"order/internal/service"
 )
 
 type MockOrderDao struct {
 	mock.Mock
+	// events collects the outbox events the service asked to write with
+	// successful changes.
+	events []model.OutboxEvent
 }
 
-func (m *MockOrderDao) Create(ctx context.Context, order model.Order) (string, error) {
+func (m *MockOrderDao) Create(ctx context.Context, order model.Order, event dao.EventFunc) (string, error) {
 	args := m.Called(ctx, order)
+	if args.Error(1) == nil && event != nil {
+		evt, err := event(args.String(0))
+		if err != nil {
+			return "", err
+		}
+		m.events = append(m.events, evt)
+	}
 	return args.String(0), args.Error(1)
 }
 
@@ -27,14 +42,41 @@ func (m *MockOrderDao) GetByID(ctx context.Context, id string) (*model.Order, er
 	return args.Get(0).(*model.Order), args.Error(1)
 }
 
-func (m *MockOrderDao) UpdateStatus(ctx context.Context, id string, status string) error {
-	args := m.Called(ctx, id, status)
-	return args.Error(0)
+func (m *MockOrderDao) Replace(ctx context.Context, order model.Order, from string, events ...model.OutboxEvent) error {
+	args := m.Called(ctx, order, from)
+	return m.record(args.Error(0), events)
+}
+
+func (m *MockOrderDao) UpdateStatus(ctx context.Context, id string, from string, change model.StatusChange, events ...model.OutboxEvent) error {
+	args := m.Called(ctx, id, from, change)
+	return m.record(args.Error(0), events)
 }
 
-func (m *MockOrderDao) Delete(ctx context.Context, id string) error {
+func (m *MockOrderDao) Delete(ctx context.Context, id string, events ...model.OutboxEvent) error {
 	args := m.Called(ctx, id)
-	return args.Error(0)
+	return m.record(args.Error(0), events)
+}
+
+func (m *MockOrderDao) record(err error, events []model.OutboxEvent) error {
+	if err == nil {
+		m.events = append(m.events, events...)
+	}
+	return err
+}
+
+// openEvent decodes the outbox event evt into msg and checks its type.
+func openEvent(t *testing.T, evt model.OutboxEvent, eventType string, msg proto.Message) {
+	t.Helper()
+	assert.Equal(t, eventType, evt.Subject)
+	env, err := events.Decode(evt.Subject, evt.Payload)
+	if assert.NoError(t, err) {
+		assert.NoError(t, env.Open(msg))
+	}
+}
+
+func (m *MockOrderDao) ReassignUser(ctx context.Context, from, to string) (int64, error) {
+	args := m.Called(ctx, from, to)
+	return args.Get(0).(int64), args.Error(1)
 }
 
 func (m *MockOrderDao) FindOrdersByUserId(ctx context.Context, userId string) ([]model.Order, error) {
@@ -49,30 +91,144 @@ func (m *MockOrderDao) List(ctx context.Context, limit int64, skip int64) ([]mod
 
 func TestOrderService_CreateOrder(t *testing.T) {
 	mockRepo := new(MockOrderDao)
-	svc := service.NewOrderService(mockRepo)
+	svc := service.NewOrderService(mockRepo, nil, service.Pricing{})
 
 	userID := "user123"
-	itemIDs := []string{"item1", "item2"}
-	totalPrice := 50.0
+	items := []model.OrderItem{
+		{MenuItemID: "item1", Name: "Burger", UnitPrice: 10, Quantity: 2, LineTotal: 20},
+		{MenuItemID: "item2", Name: "Latte", UnitPrice: 30, Quantity: 1, LineTotal: 30},
+	}
 
 	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(order model.Order) bool {
 		return order.UserID == userID &&
-			len(order.ItemIDs) == len(itemIDs) &&
-			order.TotalPrice == totalPrice &&
+			len(order.Items) == len(items) &&
+			assert.ObjectsAreEqual([]string{"item1", "item1", "item2"}, order.ItemIDs) &&
+			order.TotalPrice == 50.0 &&
 			order.Status == "Pending"
 	})).Return("order123", nil)
 
-	id, err := svc.CreateOrder(context.Background(), userID, itemIDs, totalPrice)
+	ctx := logging.WithRequestID(context.Background(), "req-1")
+	id, err := svc.CreateOrder(ctx, userID, items, nil, "")
 
 	assert.NoError(t, err)
 	assert.Equal(t, "order123", id)
 
+	if assert.Len(t, mockRepo.events, 1) {
+		evt := mockRepo.events[0]
+		assert.Equal(t, events.TypeOrderCreated, evt.Subject)
+		assert.Equal(t, "req-1", evt.RequestID)
+
+		env, err := events.Decode(events.TypeOrderCreated, evt.Payload)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "req-1", env.GetCorrelationId())
+			assert.Equal(t, int32(events.Version), env.GetVersion())
+			var created events.OrderCreated
+			assert.NoError(t, env.Open(&created))
+			assert.Equal(t, "order123", created.GetOrderId())
+			assert.Equal(t, "user123", created.GetUserId())
+			assert.Equal(t, []string{"item1", "item1", "item2"}, created.GetItemIds())
+			assert.Len(t, created.GetLines(), len(items))
+			assert.Equal(t, 50.0, created.GetTotal())
+			assert.True(t, created.GetCreatedAt().AsTime().Equal(evt.CreatedAt))
+		}
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestOrderService_CreateOrderWithFulfillment(t *testing.T) {
+	mockRepo := new(MockOrderDao)
+	svc := service.NewOrderService(mockRepo, nil, service.Pricing{})
+
+	requestedAt := time.Date(2026, 3, 14, 18, 30, 0, 0, time.UTC)
+	fulfillment := &model.Fulfillment{
+		Type:        model.FulfillmentDelivery,
+		ContactName: "Aigerim",
+		Address:     "Astana, Kabanbay Batyr 53",
+		Phone:       "+77001234567",
+		Notes:       "Ring twice",
+		RequestedAt: requestedAt,
+	}
+	items := []model.OrderItem{{MenuItemID: "item1", Name: "Burger", UnitPrice: 10, Quantity: 1, LineTotal: 10}}
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(order model.Order) bool {
+		return order.Fulfillment == fulfillment
+	})).Return("order123", nil)
+
+	_, err := svc.CreateOrder(context.Background(), "user123", items, fulfillment, "")
+	assert.NoError(t, err)
+
+	if assert.Len(t, mockRepo.events, 1) {
+		var created events.OrderCreated
+		openEvent(t, mockRepo.events[0], events.TypeOrderCreated, &created)
+		f := created.GetFulfillment()
+		assert.Equal(t, model.FulfillmentDelivery, f.GetType())
+		assert.Equal(t, "Aigerim", f.GetContactName())
+		assert.Equal(t, "Astana, Kabanbay Batyr 53", f.GetAddress())
+		assert.Equal(t, "+77001234567", f.GetPhone())
+		assert.Equal(t, "Ring twice", f.GetNotes())
+		assert.True(t, f.GetRequestedAt().AsTime().Equal(requestedAt))
+	}
+	mockRepo.AssertExpectations(t)
+}
+
+func TestOrderService_CreateOrderWithDiscountAndDeliveryFee(t *testing.T) {
+	mockRepo := new(MockOrderDao)
+	svc := service.NewOrderService(mockRepo, nil, service.Pricing{
+		DeliveryFee: 2.5,
+		PromoCodes:  map[string]float64{"WELCOME10": 10},
+	})
+
+	delivery := &model.Fulfillment{Type: model.FulfillmentDelivery, Address: "1 Main St", Phone: "+15551234567"}
+	items := []model.OrderItem{{MenuItemID: "item1", Name: "Burger", UnitPrice: 9.99, Quantity: 2, LineTotal: 19.98}}
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(order model.Order) bool {
+		return order.Discount == 2.0 && order.DeliveryFee == 2.5 && order.PromoCode == "WELCOME10" &&
+			order.TotalPrice == 20.48
+	})).Return("order123", nil)
+
+	_, err := svc.CreateOrder(context.Background(), "user123", items, delivery, " welcome10 ")
+	assert.NoError(t, err)
+
+	if assert.Len(t, mockRepo.events, 1) {
+		var created events.OrderCreated
+		openEvent(t, mockRepo.events[0], events.TypeOrderCreated, &created)
+		assert.Equal(t, 2.0, created.GetDiscount())
+		assert.Equal(t, 2.5, created.GetDeliveryFee())
+		assert.Equal(t, "WELCOME10", created.GetPromoCode())
+		assert.Equal(t, 20.48, created.GetTotal())
+	}
+	mockRepo.AssertExpectations(t)
+
+	_, err = svc.CreateOrder(context.Background(), "user123", items, delivery, "EXPIRED")
+	assert.ErrorIs(t, err, service.ErrUnknownPromoCode)
+}
+
+func TestOrderService_PickupHasNoDeliveryFee(t *testing.T) {
+	mockRepo := new(MockOrderDao)
+	svc := service.NewOrderService(mockRepo, nil, service.Pricing{DeliveryFee: 2.5})
+
+	pickup := &model.Fulfillment{Type: model.FulfillmentPickup, Phone: "+15551234567"}
+	items := []model.OrderItem{{MenuItemID: "item1", Name: "Burger", UnitPrice: 9.99, Quantity: 1, LineTotal: 9.99}}
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(order model.Order) bool {
+		return order.DeliveryFee == 0 && order.Discount == 0 && order.TotalPrice == 9.99
+	})).Return("order123", nil)
+
+	_, err := svc.CreateOrder(context.Background(), "user123", items, pickup, "")
+	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
 
+func TestOrder_LineItemsFromLegacyItemIDs(t *testing.T) {
+	order := model.Order{ItemIDs: []string{"item1", "item2", "item1"}}
+
+	assert.Equal(t, []model.OrderItem{
+		{MenuItemID: "item1", Quantity: 2},
+		{MenuItemID: "item2", Quantity: 1},
+	}, order.LineItems())
+}
+
 func TestOrderService_GetOrder(t *testing.T) {
 	mockRepo := new(MockOrderDao)
-	svc := service.NewOrderService(mockRepo)
+	svc := service.NewOrderService(mockRepo, nil, service.Pricing{})
 
 	order := &model.Order{ID: "order123", UserID: "user123", Status: "Pending"}
 
@@ -88,33 +244,198 @@ func TestOrderService_GetOrder(t *testing.T) {
 
 func TestOrderService_UpdateOrderStatus(t *testing.T) {
 	mockRepo := new(MockOrderDao)
-	svc := service.NewOrderService(mockRepo)
+	svc := service.NewOrderService(mockRepo, nil, service.Pricing{})
 
-	mockRepo.On("UpdateStatus", mock.Anything, "order123", "Completed").Return(nil)
+	order := &model.Order{ID: "order123", UserID: "user123", Status: "Confirmed", TotalPrice: 12.5}
+	mockRepo.On("GetByID", mock.Anything, "order123").Return(order, nil)
+	mockRepo.On("UpdateStatus", mock.Anything, "order123", "Confirmed", mock.MatchedBy(func(change model.StatusChange) bool {
+		return change.Status == "Preparing" && change.ActorID == "admin1" && !change.ChangedAt.IsZero()
+	})).Return(nil)
 
-	err := svc.UpdateOrderStatus(context.Background(), "order123", "Completed")
+	err := svc.UpdateOrderStatus(context.Background(), "order123", "preparing", "admin1")
 
 	assert.NoError(t, err)
+	if assert.Len(t, mockRepo.events, 1) {
+		var changed events.OrderStatusChanged
+		openEvent(t, mockRepo.events[0], events.TypeOrderStatusChanged, &changed)
+		assert.Equal(t, "order123", changed.GetOrderId())
+		assert.Equal(t, "user123", changed.GetUserId())
+		assert.Equal(t, "Confirmed", changed.GetFromStatus())
+		assert.Equal(t, "Preparing", changed.GetToStatus())
+		assert.Equal(t, "admin1", changed.GetChangedBy())
+		assert.Equal(t, 12.5, changed.GetTotal())
+	}
 
 	mockRepo.AssertExpectations(t)
 }
 
+func TestOrderService_CancelOrderPublishesCancelled(t *testing.T) {
+	mockRepo := new(MockOrderDao)
+	svc := service.NewOrderService(mockRepo, nil, service.Pricing{})
+
+	mockRepo.On("GetByID", mock.Anything, "order123").Return(&model.Order{ID: "order123", UserID: "user123", Status: "Pending"}, nil)
+	mockRepo.On("UpdateStatus", mock.Anything, "order123", "Pending", mock.Anything).Return(nil)
+
+	assert.NoError(t, svc.UpdateOrderStatus(context.Background(), "order123", "Cancelled", "user123"))
+
+	if assert.Len(t, mockRepo.events, 2) {
+		var changed events.OrderStatusChanged
+		openEvent(t, mockRepo.events[0], events.TypeOrderStatusChanged, &changed)
+		assert.Equal(t, "Cancelled", changed.GetToStatus())
+		var cancelled events.OrderCancelled
+		openEvent(t, mockRepo.events[1], events.TypeOrderCancelled, &cancelled)
+		assert.Equal(t, "Pending", cancelled.GetPreviousStatus())
+		assert.Equal(t, "user123", cancelled.GetCancelledBy())
+		assert.False(t, cancelled.GetDeleted())
+	}
+}
+
+func TestOrderService_UpdateOrderPublishesBeforeAndAfter(t *testing.T) {
+	mockRepo := new(MockOrderDao)
+	svc := service.NewOrderService(mockRepo, nil, service.Pricing{})
+
+	existing := &model.Order{
+		ID:         "order123",
+		UserID:     "user123",
+		ItemIDs:    []string{"item1"},
+		Items:      []model.OrderItem{{MenuItemID: "item1", Name: "Burger", UnitPrice: 5, Quantity: 1, LineTotal: 5}},
+		TotalPrice: 5,
+		Status:     "Confirmed",
+	}
+	mockRepo.On("GetByID", mock.Anything, "order123").Return(existing, nil)
+	mockRepo.On("Replace", mock.Anything, mock.Anything, "Confirmed").Return(nil)
+
+	updated := *existing
+	updated.ItemIDs = []string{"item1", "item1"}
+	updated.Items = []model.OrderItem{{MenuItemID: "item1", Name: "Burger", UnitPrice: 5, Quantity: 2, LineTotal: 10}}
+	updated.TotalPrice = 10
+	updated.Status = "Preparing"
+	assert.NoError(t, svc.UpdateOrder(context.Background(), updated, "admin1"))
+
+	if assert.Len(t, mockRepo.events, 2) {
+		var evt events.OrderUpdated
+		openEvent(t, mockRepo.events[0], events.TypeOrderUpdated, &evt)
+		assert.Equal(t, "admin1", evt.GetUpdatedBy())
+		assert.Equal(t, []string{"item1"}, evt.GetBefore().GetItemIds())
+		assert.Equal(t, 5.0, evt.GetBefore().GetTotal())
+		assert.Equal(t, "Confirmed", evt.GetBefore().GetStatus())
+		assert.Equal(t, []string{"item1", "item1"}, evt.GetAfter().GetItemIds())
+		assert.Equal(t, int32(2), evt.GetAfter().GetLines()[0].GetQuantity())
+		assert.Equal(t, 10.0, evt.GetAfter().GetTotal())
+
+		var changed events.OrderStatusChanged
+		openEvent(t, mockRepo.events[1], events.TypeOrderStatusChanged, &changed)
+		assert.Equal(t, "Preparing", changed.GetToStatus())
+	}
+
+	// Replacing an order with the same contents and status announces nothing.
+	mockRepo.events = nil
+	assert.NoError(t, svc.UpdateOrder(context.Background(), *existing, "admin1"))
+	assert.Empty(t, mockRepo.events)
+}
+
+func TestOrderService_UpdateOrderLosesToConcurrentStatusChange(t *testing.T) {
+	mockRepo := new(MockOrderDao)
+	svc := service.NewOrderService(mockRepo, nil, service.Pricing{})
+
+	existing := &model.Order{ID: "order123", UserID: "user123", Status: "Pending"}
+	mockRepo.On("GetByID", mock.Anything, "order123").Return(existing, nil)
+	mockRepo.On("Replace", mock.Anything, mock.Anything, "Pending").Return(dao.ErrStatusChanged)
+
+	updated := *existing
+	updated.Status = "Cancelled"
+	err := svc.UpdateOrder(context.Background(), updated, "admin1")
+	assert.ErrorIs(t, err, dao.ErrStatusChanged)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestOrderService_ConfirmedOnlyByPayment(t *testing.T) {
+	mockRepo := new(MockOrderDao)
+	svc := service.NewOrderService(mockRepo, nil, service.Pricing{})
+
+	err := svc.UpdateOrderStatus(context.Background(), "order123", "Confirmed", "admin1")
+	assert.ErrorIs(t, err, service.ErrPaymentRequired)
+
+	mockRepo.On("GetByID", mock.Anything, "order123").Return(&model.Order{ID: "order123", Status: "Pending"}, nil)
+	err = svc.UpdateOrder(context.Background(), model.Order{ID: "order123", Status: "Confirmed"}, "admin1")
+	assert.ErrorIs(t, err, service.ErrPaymentRequired)
+
+	mockRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "Replace", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOrderService_ConfirmPaidOrder(t *testing.T) {
+	mockRepo := new(MockOrderDao)
+	svc := service.NewOrderService(mockRepo, nil, service.Pricing{})
+
+	mockRepo.On("GetByID", mock.Anything, "order123").Return(&model.Order{ID: "order123", Status: "Pending"}, nil).Once()
+	mockRepo.On("UpdateStatus", mock.Anything, "order123", "Pending", mock.MatchedBy(func(change model.StatusChange) bool {
+		return change.Status == "Confirmed" && change.ActorID == model.PaymentActorID
+	})).Return(nil).Once()
+	assert.NoError(t, svc.ConfirmPaidOrder(context.Background(), "order123"))
+
+	// A redelivered event finds the order confirmed already.
+	mockRepo.On("GetByID", mock.Anything, "order123").Return(&model.Order{ID: "order123", Status: "Confirmed"}, nil).Once()
+	assert.NoError(t, svc.ConfirmPaidOrder(context.Background(), "order123"))
+
+	mockRepo.On("GetByID", mock.Anything, "order456").Return(&model.Order{ID: "order456", Status: "Cancelled"}, nil)
+	assert.ErrorIs(t, svc.ConfirmPaidOrder(context.Background(), "order456"), service.ErrInvalidTransition)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestOrderService_UpdateOrderStatus_RejectsInvalidTransitions(t *testing.T) {
+	mockRepo := new(MockOrderDao)
+	svc := service.NewOrderService(mockRepo, nil, service.Pricing{})
+
+	order := &model.Order{ID: "order123", Status: "Delivered"}
+	mockRepo.On("GetByID", mock.Anything, "order123").Return(order, nil)
+
+	err := svc.UpdateOrderStatus(context.Background(), "order123", "Pending", "user123")
+	assert.ErrorIs(t, err, service.ErrInvalidTransition)
+
+	err = svc.UpdateOrderStatus(context.Background(), "order123", "Deliverd", "admin1")
+	assert.ErrorIs(t, err, service.ErrUnknownStatus)
+
+	mockRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestOrderService_DeleteOrder(t *testing.T) {
 	mockRepo := new(MockOrderDao)
-	svc := service.NewOrderService(mockRepo)
+	svc := service.NewOrderService(mockRepo, nil, service.Pricing{})
 
+	mockRepo.On("GetByID", mock.Anything, "order123").Return(&model.Order{ID: "order123", UserID: "user123", Status: "Preparing"}, nil)
 	mockRepo.On("Delete", mock.Anything, "order123").Return(nil)
 
-	err := svc.DeleteOrder(context.Background(), "order123")
+	err := svc.DeleteOrder(context.Background(), "order123", "admin1")
 
 	assert.NoError(t, err)
+	if assert.Len(t, mockRepo.events, 1) {
+		var cancelled events.OrderCancelled
+		openEvent(t, mockRepo.events[0], events.TypeOrderCancelled, &cancelled)
+		assert.Equal(t, "Preparing", cancelled.GetPreviousStatus())
+		assert.Equal(t, "admin1", cancelled.GetCancelledBy())
+		assert.True(t, cancelled.GetDeleted())
+	}
+
+	mockRepo.AssertExpectations(t)
+}
 
+func TestOrderService_AnonymizeUserOrders(t *testing.T) {
+	mockRepo := new(MockOrderDao)
+	svc := service.NewOrderService(mockRepo, nil, service.Pricing{})
+
+	mockRepo.On("ReassignUser", mock.Anything, "user123", model.DeletedUserID).Return(int64(3), nil)
+
+	n, err := svc.AnonymizeUserOrders(context.Background(), "user123")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), n)
 	mockRepo.AssertExpectations(t)
 }
 
 func TestOrderService_ListOrdersByUser(t *testing.T) {
 	mockRepo := new(MockOrderDao)
-	svc := service.NewOrderService(mockRepo)
+	svc := service.NewOrderService(mockRepo, nil, service.Pricing{})
 
 	orders := []model.Order{
 		{ID: "order1", UserID: "user123"},
@@ -133,7 +454,7 @@ func TestOrderService_ListOrdersByUser(t *testing.T) {
 
 func TestOrderService_ListOrders(t *testing.T) {
 	mockRepo := new(MockOrderDao)
-	svc := service.NewOrderService(mockRepo)
+	svc := service.NewOrderService(mockRepo, nil, service.Pricing{})
 
 	orders := []model.Order{
 		{ID: "order1"},
@@ -149,3 +470,162 @@ func TestOrderService_ListOrders(t *testing.T) {
 
 	mockRepo.AssertExpectations(t)
 }
+
+// memIdempotencyStore keeps idempotency records in a map and remembers the
+// ttl each key was last written with.
+type memIdempotencyStore struct {
+	records map[string]dao.IdempotencyRecord
+	ttls    map[string]time.Duration
+	// err, if set, is returned by Reserve as if Redis were down.
+	err error
+}
+
+func (s *memIdempotencyStore) Reserve(ctx context.Context, key, hash string, ttl time.Duration) (*dao.IdempotencyRecord, bool, error) {
+	if s.err != nil {
+		return nil, false, s.err
+	}
+	if record, ok := s.records[key]; ok {
+		return &record, false, nil
+	}
+	s.records[key] = dao.IdempotencyRecord{RequestHash: hash}
+	s.setTTL(key, ttl)
+	return nil, true, nil
+}
+
+func (s *memIdempotencyStore) Complete(ctx context.Context, key string, record dao.IdempotencyRecord, ttl time.Duration) error {
+	s.records[key] = record
+	s.setTTL(key, ttl)
+	return nil
+}
+
+func (s *memIdempotencyStore) setTTL(key string, ttl time.Duration) {
+	if s.ttls == nil {
+		s.ttls = map[string]time.Duration{}
+	}
+	s.ttls[key] = ttl
+}
+
+func (s *memIdempotencyStore) Release(ctx context.Context, key string) error {
+	delete(s.records, key)
+	return nil
+}
+
+func TestOrderService_CreateOnce(t *testing.T) {
+	store := &memIdempotencyStore{records: map[string]dao.IdempotencyRecord{}}
+	svc := service.NewOrderService(new(MockOrderDao), store, service.Pricing{})
+	ctx := context.Background()
+
+	burgers := []model.OrderItem{{MenuItemID: "item1", Quantity: 2}, {MenuItemID: "item2", Quantity: 1}}
+	sameBurgers := []model.OrderItem{{MenuItemID: "item2", Quantity: 1}, {MenuItemID: "item1", Quantity: 2}}
+	salad := []model.OrderItem{{MenuItemID: "item3", Quantity: 1}}
+
+	created := 0
+	create := func() (string, error) {
+		created++
+		return fmt.Sprintf("order%d", created), nil
+	}
+
+	id, replayed, err := svc.CreateOnce(ctx, "user1", "key1", service.RequestHash("user1", burgers, nil, ""), create)
+	assert.NoError(t, err)
+	assert.False(t, replayed)
+	assert.Equal(t, "order1", id)
+
+	id, replayed, err = svc.CreateOnce(ctx, "user1", "key1", service.RequestHash("user1", sameBurgers, nil, ""), create)
+	assert.NoError(t, err)
+	assert.True(t, replayed)
+	assert.Equal(t, "order1", id)
+
+	_, _, err = svc.CreateOnce(ctx, "user1", "key1", service.RequestHash("user1", salad, nil, ""), create)
+	assert.ErrorIs(t, err, service.ErrIdempotencyConflict)
+
+	// Keys are per user.
+	id, replayed, err = svc.CreateOnce(ctx, "user2", "key1", service.RequestHash("user2", burgers, nil, ""), create)
+	assert.NoError(t, err)
+	assert.False(t, replayed)
+	assert.Equal(t, "order2", id)
+
+	assert.Equal(t, 2, created)
+}
+
+func TestRequestHash_IncludesFulfillment(t *testing.T) {
+	items := []model.OrderItem{{MenuItemID: "item1", Quantity: 1}}
+	home := &model.Fulfillment{Type: model.FulfillmentDelivery, Address: "1 Main St", Phone: "+15551234567"}
+	sameHome := &model.Fulfillment{Type: model.FulfillmentDelivery, Address: "1 Main St", Phone: "+15551234567"}
+	work := &model.Fulfillment{Type: model.FulfillmentDelivery, Address: "9 Office Rd", Phone: "+15551234567"}
+	later := &model.Fulfillment{Type: model.FulfillmentDelivery, Address: "1 Main St", Phone: "+15551234567",
+		RequestedAt: time.Date(2026, 1, 1, 18, 0, 0, 0, time.UTC)}
+
+	assert.Equal(t, service.RequestHash("user1", items, home, ""), service.RequestHash("user1", items, sameHome, ""))
+	assert.NotEqual(t, service.RequestHash("user1", items, home, ""), service.RequestHash("user1", items, work, ""))
+	assert.NotEqual(t, service.RequestHash("user1", items, home, ""), service.RequestHash("user1", items, later, ""))
+	assert.NotEqual(t, service.RequestHash("user1", items, home, ""), service.RequestHash("user1", items, nil, ""))
+	assert.NotEqual(t, service.RequestHash("user1", items, home, ""), service.RequestHash("user1", items, home, "WELCOME10"))
+}
+
+func TestOrderService_CreateOnce_FailedRequestCanBeRetried(t *testing.T) {
+	store := &memIdempotencyStore{records: map[string]dao.IdempotencyRecord{}}
+	svc := service.NewOrderService(new(MockOrderDao), store, service.Pricing{})
+	ctx := context.Background()
+	hash := service.RequestHash("user1", []model.OrderItem{{MenuItemID: "item1", Quantity: 1}}, nil, "")
+
+	_, _, err := svc.CreateOnce(ctx, "user1", "key1", hash, func() (string, error) {
+		return "", errors.New("menu service down")
+	})
+	assert.Error(t, err)
+
+	id, replayed, err := svc.CreateOnce(ctx, "user1", "key1", hash, func() (string, error) {
+		return "order1", nil
+	})
+	assert.NoError(t, err)
+	assert.False(t, replayed)
+	assert.Equal(t, "order1", id)
+}
+
+func TestOrderService_CreateOnce_InProgress(t *testing.T) {
+	store := &memIdempotencyStore{records: map[string]dao.IdempotencyRecord{}}
+	svc := service.NewOrderService(new(MockOrderDao), store, service.Pricing{})
+	ctx := context.Background()
+	hash := service.RequestHash("user1", []model.OrderItem{{MenuItemID: "item1", Quantity: 1}}, nil, "")
+
+	_, _, err := svc.CreateOnce(ctx, "user1", "key1", hash, func() (string, error) {
+		_, _, err := svc.CreateOnce(ctx, "user1", "key1", hash, func() (string, error) { return "order2", nil })
+		assert.ErrorIs(t, err, service.ErrIdempotencyInProgress)
+		return "order1", nil
+	})
+	assert.NoError(t, err)
+}
+
+func TestOrderService_CreateOnce_LeaseThenTTL(t *testing.T) {
+	store := &memIdempotencyStore{records: map[string]dao.IdempotencyRecord{}}
+	svc := service.NewOrderService(new(MockOrderDao), store, service.Pricing{})
+	ctx := context.Background()
+	hash := service.RequestHash("user1", []model.OrderItem{{MenuItemID: "item1", Quantity: 1}}, nil, "")
+
+	_, _, err := svc.CreateOnce(ctx, "user1", "key1", hash, func() (string, error) {
+		// A request that dies now leaves the key reserved only briefly.
+		assert.Equal(t, service.IdempotencyLease, store.ttls["user1:key1"])
+		return "order1", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, service.IdempotencyTTL, store.ttls["user1:key1"])
+}
+
+func TestOrderService_CreateOnce_StoreDownFailsClosed(t *testing.T) {
+	store := &memIdempotencyStore{records: map[string]dao.IdempotencyRecord{}, err: errors.New("redis: connection refused")}
+	svc := service.NewOrderService(new(MockOrderDao), store, service.Pricing{})
+	ctx := context.Background()
+	hash := service.RequestHash("user1", []model.OrderItem{{MenuItemID: "item1", Quantity: 1}}, nil, "")
+
+	created := false
+	_, _, err := svc.CreateOnce(ctx, "user1", "key1", hash, func() (string, error) {
+		created = true
+		return "order1", nil
+	})
+	assert.ErrorIs(t, err, service.ErrIdempotencyUnavailable)
+	assert.False(t, created)
+
+	// Without a key the store is not needed.
+	id, _, err := svc.CreateOnce(ctx, "user1", "", hash, func() (string, error) { return "order1", nil })
+	assert.NoError(t, err)
+	assert.Equal(t, "order1", id)
+}
@@ -0,0 +1,169 @@
+// Command dlq inspects and replays the messages in the dead-letter stream.
+//
+//	go run ./cmd/dlq list
+//	go run ./cmd/dlq show 12
+//	go run ./cmd/dlq replay 12 13   (or: replay -all)
+//	go run ./cmd/dlq delete 12
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	natslib "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"payment/nats"
+)
+
+const usage = `usage: dlq [-nats URL] <command>
+
+commands:
+  list                 list dead letters, oldest first
+  show SEQ             print the headers and data of a dead letter
+  replay SEQ... | -all publish dead letters on their original subject again
+  delete SEQ...        drop dead letters
+`
+
+func main() {
+	defaultURL := os.Getenv("NATS_URL")
+	if defaultURL == "" {
+		defaultURL = natslib.DefaultURL
+	}
+	url := flag.String("nats", defaultURL, "NATS server URL")
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	nc, err := natslib.Connect(*url)
+	if err != nil {
+		fail(err)
+	}
+	defer nc.Close()
+	js, err := jetstream.New(nc)
+	if err != nil {
+		fail(err)
+	}
+	letters := nats.NewDeadLetters(js)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	args := flag.Args()[1:]
+	switch flag.Arg(0) {
+	case "list":
+		err = list(ctx, letters)
+	case "show":
+		if len(args) != 1 {
+			flag.Usage()
+			os.Exit(2)
+		}
+		err = show(ctx, letters, parseSeq(args[0]))
+	case "replay":
+		if len(args) == 1 && args[0] == "-all" {
+			args, err = allSequences(ctx, letters)
+			if err != nil {
+				break
+			}
+			if len(args) == 0 {
+				fmt.Println("no dead letters")
+				break
+			}
+		}
+		err = each(args, func(seq uint64) error {
+			if err := letters.Replay(ctx, seq); err != nil {
+				return err
+			}
+			fmt.Println("replayed", seq)
+			return nil
+		})
+	case "delete":
+		err = each(args, func(seq uint64) error {
+			if err := letters.Delete(ctx, seq); err != nil {
+				return err
+			}
+			fmt.Println("deleted", seq)
+			return nil
+		})
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fail(err)
+	}
+}
+
+func list(ctx context.Context, letters *nats.DeadLetters) error {
+	all, err := letters.List(ctx)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "SEQ\tSUBJECT\tCONSUMER\tDELIVERIES\tFAILED AT\tERROR")
+	for _, l := range all {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%s\t%s\n",
+			l.Sequence, l.Subject, l.Consumer, l.Deliveries, l.FailedAt.Format(time.RFC3339), l.Error)
+	}
+	return w.Flush()
+}
+
+func show(ctx context.Context, letters *nats.DeadLetters, seq uint64) error {
+	l, err := letters.Get(ctx, seq)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("sequence: %d\nfailed at: %s\n", l.Sequence, l.FailedAt.Format(time.RFC3339))
+	for key, values := range l.Header {
+		for _, v := range values {
+			fmt.Printf("%s: %s\n", key, v)
+		}
+	}
+	fmt.Printf("\n%s\n", l.Data)
+	return nil
+}
+
+func allSequences(ctx context.Context, letters *nats.DeadLetters) ([]string, error) {
+	all, err := letters.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	seqs := make([]string, len(all))
+	for i, l := range all {
+		seqs[i] = strconv.FormatUint(l.Sequence, 10)
+	}
+	return seqs, nil
+}
+
+func each(args []string, fn func(seq uint64) error) error {
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	for _, arg := range args {
+		if err := fn(parseSeq(arg)); err != nil {
+			return fmt.Errorf("%s: %w", arg, err)
+		}
+	}
+	return nil
+}
+
+func parseSeq(arg string) uint64 {
+	seq, err := strconv.ParseUint(arg, 10, 64)
+	if err != nil {
+		fail(fmt.Errorf("invalid sequence %q", arg))
+	}
+	return seq
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "dlq:", err)
+	os.Exit(1)
+}
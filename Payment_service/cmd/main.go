@@ -2,66 +2,198 @@ package main
 
 import (
 	"context"
-	"github.com/joho/godotenv"
+	"errors"
+	"foodstore/events"
+	"foodstore/logging"
 	natslib "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
 	"google.golang.org/grpc"
-	"log"
-	"os"
+	"log/slog"
+	"net"
+	"payment/config"
+	"payment/emails"
+	"payment/handler"
 	"payment/mailer"
 	"payment/nats"
+	"payment/notifications"
+	"payment/payments"
+	pb "payment/proto"
+	menupb "payment/proto/menu"
+	orderpb "payment/proto/order"
 	userpb "payment/proto/user"
-	"strconv"
+	"payment/provider"
+	"payment/receipt"
+	"payment/users"
+	"time"
 )
 
 func main() {
-	err := godotenv.Load()
+	logging.Setup("payment")
+	cfg := config.LoadConfig()
+
+	nc, err := natslib.Connect(cfg.NATSURL)
+	if err != nil {
+		logging.Fatal("failed to connect to NATS", "err", err)
+	}
+	userConn, err := grpc.Dial(cfg.UserServiceAddr, grpc.WithInsecure(),
+		grpc.WithUnaryInterceptor(logging.UnaryClientInterceptor()))
 	if err != nil {
-		log.Println(".env not found, using system env")
+		logging.Fatal("failed to connect to UserService", "err", err)
 	}
+	defer userConn.Close()
+	orderConn, err := grpc.Dial(cfg.OrderServiceAddr, grpc.WithInsecure(),
+		grpc.WithUnaryInterceptor(logging.UnaryClientInterceptor()))
+	if err != nil {
+		logging.Fatal("failed to connect to OrderService", "err", err)
+	}
+	defer orderConn.Close()
 
-	nc, err := natslib.Connect("nats://localhost:4222")
+	menuConn, err := grpc.Dial(cfg.MenuServiceAddr, grpc.WithInsecure(),
+		grpc.WithUnaryInterceptor(logging.UnaryClientInterceptor()))
 	if err != nil {
-		log.Fatal(err)
+		logging.Fatal("failed to connect to MenuService", "err", err)
 	}
-	userConn, err := grpc.Dial("localhost:50052", grpc.WithInsecure())
+	defer menuConn.Close()
+
+	templates, err := emails.Load()
 	if err != nil {
-		log.Fatalf("Failed to connect to UserService: %v", err)
+		logging.Fatal("failed to load email templates", "err", err)
 	}
-	defer userConn.Close()
 
-	userClient := userpb.NewUserServiceClient(userConn)
-	rawPort := os.Getenv("SMTP_PORT")
-	if rawPort == "" {
-		log.Fatal("SMTP_PORT is missing in environment")
+	usersClient := users.NewClient(userpb.NewUserServiceClient(userConn), cfg.Users)
+	menuClient := menupb.NewMenuServiceClient(menuConn)
+	menu := func(ctx context.Context, ids []string) (map[string]receipt.MenuItem, error) {
+		res, err := menuClient.GetMultipleMenuItems(ctx, &menupb.GetMultipleMenuItemsRequest{Ids: ids})
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to fetch menu items via gRPC", "err", err)
+			return nil, err
+		}
+		items := make(map[string]receipt.MenuItem, len(res.Items))
+		for _, item := range res.Items {
+			items[item.Id] = receipt.MenuItem{Name: item.Name, Price: item.Price}
+		}
+		return items, nil
+	}
+	db := config.ConnectToMongo(cfg.MongoURI, cfg.DatabaseName)
+	repo := payments.NewMongoRepository(db)
+	idempotency := payments.NewMongoIdempotencyStore(db)
+	notificationRepo := notifications.NewMongoRepository(db)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	if err := repo.EnsureIndexes(ctx); err != nil {
+		slog.Warn("failed to create payment indexes", "err", err)
 	}
-	port, err := strconv.Atoi(rawPort)
-	if err != nil || port <= 0 {
-		log.Fatalf("Invalid SMTP_PORT value: %s", rawPort)
+	if err := idempotency.EnsureIndexes(ctx); err != nil {
+		slog.Warn("failed to create idempotency key indexes", "err", err)
 	}
+	if err := notificationRepo.EnsureIndexes(ctx); err != nil {
+		slog.Warn("failed to create notification indexes", "err", err)
+	}
+	cancel()
 
-	m := mailer.NewMailer(
-		os.Getenv("SMTP_HOST"),
-		port,
-		os.Getenv("SMTP_USER"),
-		os.Getenv("SMTP_PASS"),
-		os.Getenv("SMTP_FROM"),
-	)
+	transport := newTransport(cfg)
+	defer transport.Close()
+	notifier := notifications.NewNotifier(notificationRepo, mailer.NewMailer(transport, cfg.SMTPFrom), cfg.Notifications)
+	go notifier.Run(context.Background())
 	worker := &nats.EmailWorker{
-		Mailer: m,
-		GetEmailFn: func(userID string) (string, error) {
-			res, err := userClient.GetUser(context.Background(), &userpb.GetUserRequest{Id: userID})
+		Notifier:       notifier,
+		Templates:      templates,
+		Store:          cfg.Store,
+		GetMenuItemsFn: menu,
+		StatusEmails:   cfg.StatusEmails,
+		GetUserFn: func(ctx context.Context, userID string) (nats.Recipient, error) {
+			user, err := usersClient.Get(ctx, userID)
+			if errors.Is(err, users.ErrNotFound) {
+				return nats.Recipient{}, nats.Permanent(err)
+			}
 			if err != nil {
-				log.Printf("Failed to fetch user via gRPC: %v", err)
-				return "", err
+				slog.ErrorContext(ctx, "failed to fetch user", "user_id", userID, "err", err)
+				return nats.Recipient{}, err
 			}
-			return res.User.Email, nil
+			return nats.Recipient{Email: user.Email, Name: user.Name, Locale: user.Locale}, nil
 		},
 	}
 
-	if _, err := nc.Subscribe("order.created", worker.HandleOrderCreated); err != nil {
-		log.Fatal(err)
+	js, err := jetstream.New(nc)
+	if err != nil {
+		logging.Fatal("failed to open JetStream", "err", err)
+	}
+	streamCtx, cancelStreams := context.WithTimeout(context.Background(), 10*time.Second)
+	if err := nats.EnsureStreams(streamCtx, js); err != nil {
+		logging.Fatal("failed to create JetStream streams", "err", err)
+	}
+	cancelStreams()
+	for _, c := range []*nats.Consumer{
+		nats.NewConsumer(js, cfg.OrderCreated, worker.HandleOrderCreated),
+		nats.NewConsumer(js, cfg.OrderStatusChanged, worker.HandleOrderStatusChanged),
+	} {
+		go func() {
+			if err := c.Run(context.Background()); err != nil {
+				logging.Fatal("order event consumer stopped", "err", err)
+			}
+		}()
+	}
+
+	if _, err := nc.Subscribe("user.password_reset_requested", worker.HandlePasswordResetRequested); err != nil {
+		logging.Fatal("failed to subscribe", "err", err)
 	}
 
-	log.Println("EmailService is listening on order.created...")
-	select {}
+	if _, err := nc.Subscribe("user.account_locked", worker.HandleAccountLocked); err != nil {
+		logging.Fatal("failed to subscribe", "err", err)
+	}
+
+	if _, err := nc.Subscribe(users.SubjectUserUpdated, usersClient.HandleUserUpdated); err != nil {
+		logging.Fatal("failed to subscribe", "err", err)
+	}
+
+	slog.Info("EmailService is listening", "subjects", []string{events.TypeOrderCreated, events.TypeOrderStatusChanged, "user.password_reset_requested", "user.account_locked", users.SubjectUserUpdated})
+
+	orderClient := orderpb.NewOrderServiceClient(orderConn)
+	svc := payments.NewService(
+		repo,
+		provider.NewFakeProvider(cfg.FakeProvider),
+		orderClient,
+		nats.NewPublisher(js),
+		idempotency,
+	)
+	go svc.RunReconciler(context.Background(), time.Minute)
+	receipts := &receipt.Orders{
+		Store:  cfg.Store,
+		Client: orderClient,
+		Menu:   menu,
+		CustomerName: func(ctx context.Context, userID string) (string, error) {
+			user, err := usersClient.Get(ctx, userID)
+			if errors.Is(err, users.ErrNotFound) {
+				return "", nil
+			}
+			return user.Name, err
+		},
+	}
+
+	lis, err := net.Listen("tcp", cfg.GRPCAddr)
+	if err != nil {
+		logging.Fatal("failed to listen", "err", err)
+	}
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(logging.UnaryServerInterceptor()))
+	pb.RegisterPaymentServiceServer(grpcServer, handler.NewPaymentHandler(svc, receipts, notifier))
+
+	slog.Info("PaymentService started", "addr", cfg.GRPCAddr, "provider", "fake")
+	if err := grpcServer.Serve(lis); err != nil {
+		logging.Fatal("gRPC server error", "err", err)
+	}
+}
+
+func newTransport(cfg *config.Config) mailer.Transport {
+	switch cfg.MailTransport {
+	case "file":
+		t, err := mailer.NewFileTransport(cfg.MailDir)
+		if err != nil {
+			logging.Fatal("failed to create mail directory", "dir", cfg.MailDir, "err", err)
+		}
+		slog.Info("emails are written to files, not sent", "dir", cfg.MailDir)
+		return t
+	case "memory":
+		slog.Info("emails are kept in memory, not sent")
+		return mailer.NewMemoryTransport(100)
+	}
+	return mailer.NewSMTPTransport(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUser, cfg.SMTPPass, cfg.SMTPIdleTimeout)
 }
@@ -0,0 +1,100 @@
+// Command preview renders every email template and the PDF receipt with
+// sample data, so changes can be reviewed in a browser and a text editor
+// before they ship.
+//
+//	go run ./cmd/preview                       (all emails, all locales)
+//	go run ./cmd/preview -out /tmp/emails -locale ru order_receipt
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"payment/emails"
+	"payment/receipt"
+)
+
+const usage = `usage: preview [-out DIR] [-locale LOCALE] [NAME...]
+
+Writes DIR/LOCALE/NAME.html and DIR/LOCALE/NAME.txt for every email NAME
+(default: all of them) in every locale, and DIR/receipt.pdf.
+
+emails: %v
+locales: %v
+`
+
+func main() {
+	out := flag.String("out", "email-preview", "directory to write the previews to")
+	locale := flag.String("locale", "", "render only this locale")
+	flag.Usage = func() { fmt.Fprintf(os.Stderr, usage, emails.Names, emails.Locales) }
+	flag.Parse()
+
+	names := emails.Names
+	if flag.NArg() > 0 {
+		names = flag.Args()
+	}
+	locales := emails.Locales
+	if *locale != "" {
+		locales = []string{*locale}
+	}
+	for _, l := range locales {
+		if !slices.Contains(emails.Locales, l) {
+			fail(fmt.Errorf("no templates for locale %q", l))
+		}
+	}
+
+	templates, err := emails.Load()
+	if err != nil {
+		fail(err)
+	}
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		fail(err)
+	}
+	pdf, err := previewStore.PDF(emails.Samples[emails.OrderReceipt].(receipt.Receipt))
+	if err != nil {
+		fail(err)
+	}
+	write(filepath.Join(*out, "receipt.pdf"), string(pdf))
+	for _, l := range locales {
+		dir := filepath.Join(*out, l)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			fail(err)
+		}
+		for _, name := range names {
+			data, ok := emails.Samples[name]
+			if !ok {
+				fail(fmt.Errorf("unknown email %q", name))
+			}
+			email, err := templates.Render(name, l, data)
+			if err != nil {
+				fail(err)
+			}
+			write(filepath.Join(dir, name+".html"), email.HTML)
+			write(filepath.Join(dir, name+".txt"), "Subject: "+email.Subject+"\n\n"+email.Text)
+		}
+	}
+}
+
+var previewStore = receipt.Store{
+	Name:    "QuickBite",
+	Address: "Abay Ave 10, Almaty",
+	Contact: "support@quickbite.example",
+	Footer:  "Thank you for ordering with QuickBite!",
+	TaxName: "VAT",
+	TaxRate: 0.12,
+}
+
+func write(path, content string) {
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		fail(err)
+	}
+	fmt.Println(path)
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "preview:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,245 @@
+package config
+
+import (
+	"context"
+	"foodstore/logging"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"payment/nats"
+	"payment/notifications"
+	"payment/provider"
+	"payment/receipt"
+	"payment/users"
+)
+
+type Config struct {
+	GRPCAddr         string
+	NATSURL          string
+	UserServiceAddr  string
+	OrderServiceAddr string
+	MenuServiceAddr  string
+	MongoURI         string
+	DatabaseName     string
+
+	// MailTransport is how emails leave the service: "smtp", "file"
+	// (written to MailDir) or "memory" (kept, never sent).
+	MailTransport   string
+	MailDir         string
+	SMTPHost        string
+	SMTPPort        int
+	SMTPUser        string
+	SMTPPass        string
+	SMTPFrom        string
+	SMTPIdleTimeout time.Duration
+
+	FakeProvider provider.FakeConfig
+
+	Store receipt.Store
+
+	Notifications notifications.Config
+	Users         users.Config
+
+	OrderCreated       nats.ConsumerConfig
+	OrderStatusChanged nats.ConsumerConfig
+	StatusEmails       nats.StatusTransitions
+}
+
+func LoadConfig() *Config {
+	if err := godotenv.Load(); err != nil {
+		slog.Info(".env not found, using system env")
+	}
+
+	transport := getEnv("MAIL_TRANSPORT", "smtp")
+	var port int
+	switch transport {
+	case "smtp":
+		rawPort := os.Getenv("SMTP_PORT")
+		if rawPort == "" {
+			logging.Fatal("SMTP_PORT is missing in environment")
+		}
+		var err error
+		port, err = strconv.Atoi(rawPort)
+		if err != nil || port <= 0 {
+			logging.Fatal("invalid SMTP_PORT value", "value", rawPort)
+		}
+	case "file", "memory":
+	default:
+		logging.Fatal("invalid MAIL_TRANSPORT, want smtp, file or memory", "value", transport)
+	}
+
+	declines, err := provider.ParseDeclines(os.Getenv("FAKE_PROVIDER_DECLINES"))
+	if err != nil {
+		logging.Fatal("invalid FAKE_PROVIDER_DECLINES", "err", err)
+	}
+
+	taxRate, err := strconv.ParseFloat(getEnv("RECEIPT_TAX_RATE", "0.12"), 64)
+	if err != nil || taxRate < 0 {
+		logging.Fatal("invalid RECEIPT_TAX_RATE", "value", os.Getenv("RECEIPT_TAX_RATE"))
+	}
+
+	statusEmails, err := nats.ParseStatusTransitions(getEnv("ORDER_STATUS_EMAILS", nats.DefaultStatusEmails))
+	if err != nil {
+		logging.Fatal("invalid ORDER_STATUS_EMAILS", "err", err)
+	}
+
+	return &Config{
+		GRPCAddr:         getEnv("PAYMENT_GRPC_ADDR", ":50054"),
+		NATSURL:          getEnv("NATS_URL", "nats://localhost:4222"),
+		UserServiceAddr:  getEnv("USER_SERVICE_ADDR", "localhost:50052"),
+		OrderServiceAddr: getEnv("ORDER_SERVICE_ADDR", "localhost:50053"),
+		MenuServiceAddr:  getEnv("MENU_SERVICE_ADDR", "localhost:50051"),
+		MongoURI:         getEnv("MONGO_URI", "mongodb://localhost:27017"),
+		DatabaseName:     getEnv("MONGO_DB", "paymentservice"),
+
+		MailTransport:   transport,
+		MailDir:         getEnv("MAIL_DIR", "mail-outbox"),
+		SMTPHost:        os.Getenv("SMTP_HOST"),
+		SMTPPort:        port,
+		SMTPUser:        os.Getenv("SMTP_USER"),
+		SMTPPass:        os.Getenv("SMTP_PASS"),
+		SMTPFrom:        getEnv("SMTP_FROM", "noreply@quickbite.local"),
+		SMTPIdleTimeout: getDuration("SMTP_IDLE_TIMEOUT", 30*time.Second),
+
+		FakeProvider: provider.FakeConfig{
+			Delay:     getDuration("FAKE_PROVIDER_DELAY", 0),
+			Declines:  declines,
+			MaxAmount: getInt64("FAKE_PROVIDER_MAX_AMOUNT", 0),
+		},
+
+		Store: receipt.Store{
+			Name:    getEnv("RECEIPT_STORE_NAME", "QuickBite"),
+			Address: getEnv("RECEIPT_STORE_ADDRESS", ""),
+			Contact: getEnv("RECEIPT_STORE_CONTACT", ""),
+			Footer:  getEnv("RECEIPT_FOOTER", "Thank you for ordering with QuickBite!"),
+			TaxName: getEnv("RECEIPT_TAX_NAME", "VAT"),
+			TaxRate: taxRate,
+		},
+
+		Notifications: notificationsConfig(),
+		Users:         usersConfig(),
+
+		OrderCreated:       orderConsumer(nats.OrderCreatedConsumer()),
+		OrderStatusChanged: orderConsumer(nats.OrderStatusChangedConsumer()),
+		StatusEmails:       statusEmails,
+	}
+}
+
+// orderConsumer applies the ORDER_EVENTS_* settings, shared by the consumers
+// of the ORDERS stream, to cfg.
+func orderConsumer(cfg nats.ConsumerConfig) nats.ConsumerConfig {
+	cfg.MaxDeliver = int(getInt64("ORDER_EVENTS_MAX_DELIVER", int64(cfg.MaxDeliver)))
+	cfg.AckWait = getDuration("ORDER_EVENTS_ACK_WAIT", cfg.AckWait)
+	cfg.Backoff = getDurations("ORDER_EVENTS_BACKOFF", cfg.Backoff)
+	if cfg.MaxDeliver < 1 {
+		logging.Fatal("ORDER_EVENTS_MAX_DELIVER must be at least 1")
+	}
+	return cfg
+}
+
+// notificationsConfig reads the NOTIFY_* settings of email retries.
+func notificationsConfig() notifications.Config {
+	cfg := notifications.DefaultConfig()
+	cfg.MaxAttempts = int(getInt64("NOTIFY_MAX_ATTEMPTS", int64(cfg.MaxAttempts)))
+	cfg.BaseDelay = getDuration("NOTIFY_RETRY_BASE_DELAY", cfg.BaseDelay)
+	cfg.MaxDelay = getDuration("NOTIFY_RETRY_MAX_DELAY", cfg.MaxDelay)
+	cfg.PollInterval = getDuration("NOTIFY_RETRY_INTERVAL", cfg.PollInterval)
+	cfg.Retention = getDuration("NOTIFY_RETENTION", cfg.Retention)
+	if cfg.MaxAttempts < 1 {
+		logging.Fatal("NOTIFY_MAX_ATTEMPTS must be at least 1")
+	}
+	if cfg.PollInterval <= 0 {
+		logging.Fatal("NOTIFY_RETRY_INTERVAL must be positive")
+	}
+	if cfg.Retention <= 0 {
+		logging.Fatal("NOTIFY_RETENTION must be positive")
+	}
+	return cfg
+}
+
+// usersConfig reads the USER_SERVICE_* and USER_CACHE_* settings of user
+// lookups.
+func usersConfig() users.Config {
+	cfg := users.DefaultConfig()
+	cfg.Timeout = getDuration("USER_SERVICE_TIMEOUT", cfg.Timeout)
+	cfg.Attempts = int(getInt64("USER_SERVICE_ATTEMPTS", int64(cfg.Attempts)))
+	cfg.RetryDelay = getDuration("USER_SERVICE_RETRY_DELAY", cfg.RetryDelay)
+	cfg.BreakerThreshold = int(getInt64("USER_SERVICE_BREAKER_THRESHOLD", int64(cfg.BreakerThreshold)))
+	cfg.BreakerCooldown = getDuration("USER_SERVICE_BREAKER_COOLDOWN", cfg.BreakerCooldown)
+	cfg.CacheTTL = getDuration("USER_CACHE_TTL", cfg.CacheTTL)
+	cfg.CacheSize = int(getInt64("USER_CACHE_SIZE", int64(cfg.CacheSize)))
+	if cfg.Timeout <= 0 || cfg.Attempts < 1 {
+		logging.Fatal("USER_SERVICE_TIMEOUT must be positive and USER_SERVICE_ATTEMPTS at least 1")
+	}
+	return cfg
+}
+
+func getEnv(key, defaultValue string) string {
+	if val, exists := os.LookupEnv(key); exists {
+		return val
+	}
+	return defaultValue
+}
+
+func getDuration(key string, defaultValue time.Duration) time.Duration {
+	val, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(val)
+	if err != nil {
+		logging.Fatal("invalid duration", "key", key, "value", val)
+	}
+	return d
+}
+
+// getDurations reads a comma-separated list such as "1s,10s,1m".
+func getDurations(key string, defaultValue []time.Duration) []time.Duration {
+	val, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	var ds []time.Duration
+	for _, part := range strings.Split(val, ",") {
+		d, err := time.ParseDuration(strings.TrimSpace(part))
+		if err != nil {
+			logging.Fatal("invalid duration list", "key", key, "value", val)
+		}
+		ds = append(ds, d)
+	}
+	return ds
+}
+
+func getInt64(key string, defaultValue int64) int64 {
+	val, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	n, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		logging.Fatal("invalid number", "key", key, "value", val)
+	}
+	return n
+}
+
+func ConnectToMongo(uri string, dbName string) *mongo.Database {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		logging.Fatal("failed to connect to MongoDB", "err", err)
+	}
+	if err := client.Ping(ctx, nil); err != nil {
+		logging.Fatal("failed to ping MongoDB", "err", err)
+	}
+
+	slog.Info("connected to MongoDB", "database", dbName)
+	return client.Database(dbName)
+}
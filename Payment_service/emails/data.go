@@ -0,0 +1,79 @@
+package emails
+
+import (
+	"time"
+
+	"payment/receipt"
+)
+
+// OrderReceipt is rendered from a receipt.Receipt, like the attached PDF.
+
+// StatusUpdate is the data of OrderStatus.
+type StatusUpdate struct {
+	Name    string
+	OrderID string
+	From    string
+	Status  string
+	Total   float64
+}
+
+// PasswordResetLink is the data of PasswordReset.
+type PasswordResetLink struct {
+	Name      string
+	ResetURL  string
+	ExpiresAt time.Time
+}
+
+// Lockout is the data of AccountLocked.
+type Lockout struct {
+	Name           string
+	FailedAttempts int64
+	ClientIP       string
+	LockedUntil    time.Time
+}
+
+// Samples holds example data for every email, for previews and tests.
+var Samples = map[string]any{
+	OrderReceipt: receipt.Receipt{
+		OrderID:      "665f1c2b9a1e4b3c2d1e0f10",
+		CustomerName: "John",
+		CreatedAt:    time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC),
+		Lines: []receipt.Line{
+			{MenuItemID: "m1", Name: "Burger", Quantity: 2, UnitPrice: 7.99, Total: 15.98},
+			{MenuItemID: "m2", Name: "Fries & <Dip>", Quantity: 1, UnitPrice: 3.49, Total: 3.49},
+		},
+		Subtotal:    19.47,
+		Discount:    1.95,
+		DeliveryFee: 2.99,
+		Tax:         2.20,
+		TaxName:     "VAT",
+		TaxRate:     0.12,
+		Total:       20.51,
+		Fulfillment: &receipt.Fulfillment{
+			Type:        "delivery",
+			ContactName: "John",
+			Address:     "Kabanbay Batyr 53, Astana",
+			Phone:       "+77001234567",
+			Notes:       "Ring twice & <leave at the door>",
+			RequestedAt: time.Date(2026, 1, 1, 13, 30, 0, 0, time.UTC),
+		},
+	},
+	OrderStatus: StatusUpdate{
+		Name:    "John",
+		OrderID: "665f1c2b9a1e4b3c2d1e0f10",
+		From:    "Preparing",
+		Status:  "OutForDelivery",
+		Total:   20.51,
+	},
+	PasswordReset: PasswordResetLink{
+		Name:      "John",
+		ResetURL:  "http://localhost:8082/reset-password.html?token=abc123",
+		ExpiresAt: time.Date(2026, 1, 1, 12, 30, 0, 0, time.UTC),
+	},
+	AccountLocked: Lockout{
+		Name:           "John",
+		FailedAttempts: 10,
+		ClientIP:       "203.0.113.7",
+		LockedUntil:    time.Date(2026, 1, 1, 12, 15, 0, 0, time.UTC),
+	},
+}
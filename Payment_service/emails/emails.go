@@ -0,0 +1,97 @@
+// Package emails renders the emails Payment_service sends from the templates
+// in templates/<locale>. Every email has a subject and a plain text body,
+// defined in <name>.txt, and an HTML body in <name>.html, which fills the
+// "content" block of the locale's layout.html.
+package emails
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+// The emails there are templates for.
+const (
+	OrderReceipt  = "order_receipt"
+	OrderStatus   = "order_status"
+	PasswordReset = "password_reset"
+	AccountLocked = "account_locked"
+)
+
+// Names lists every email, in the order the preview command writes them.
+var Names = []string{OrderReceipt, OrderStatus, PasswordReset, AccountLocked}
+
+//go:embed templates
+var files embed.FS
+
+// Email is a rendered email.
+type Email struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+type template struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// Templates holds the parsed templates of every locale.
+type Templates struct {
+	byLocale map[string]map[string]template
+}
+
+// Load parses the embedded templates. It fails if a locale lacks one of the
+// emails in Names.
+func Load() (*Templates, error) {
+	t := &Templates{byLocale: map[string]map[string]template{}}
+	for _, locale := range Locales {
+		funcs := funcsFor(locale)
+		dir := "templates/" + locale + "/"
+		t.byLocale[locale] = map[string]template{}
+		for _, name := range Names {
+			text, err := texttemplate.New(name+".txt").Funcs(texttemplate.FuncMap(funcs)).
+				ParseFS(files, dir+name+".txt")
+			if err != nil {
+				return nil, fmt.Errorf("parse %s/%s.txt: %w", locale, name, err)
+			}
+			if text.Lookup("subject") == nil {
+				return nil, fmt.Errorf("%s/%s.txt defines no subject", locale, name)
+			}
+			html, err := htmltemplate.New("layout.html").Funcs(htmltemplate.FuncMap(funcs)).
+				ParseFS(files, dir+"layout.html", dir+name+".html")
+			if err != nil {
+				return nil, fmt.Errorf("parse %s/%s.html: %w", locale, name, err)
+			}
+			t.byLocale[locale][name] = template{text: text, html: html}
+		}
+	}
+	return t, nil
+}
+
+// Render renders email name for a reader of locale, falling back to English
+// for locales without templates.
+func (t *Templates) Render(name, locale string, data any) (Email, error) {
+	tmpl, ok := t.byLocale[Locale(locale)][name]
+	if !ok {
+		return Email{}, fmt.Errorf("no email template %q", name)
+	}
+	var subject, text, html bytes.Buffer
+	if err := tmpl.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Email{}, fmt.Errorf("render subject of %s: %w", name, err)
+	}
+	if err := tmpl.text.Execute(&text, data); err != nil {
+		return Email{}, fmt.Errorf("render text of %s: %w", name, err)
+	}
+	if err := tmpl.html.Execute(&html, data); err != nil {
+		return Email{}, fmt.Errorf("render html of %s: %w", name, err)
+	}
+	return Email{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+		HTML:    html.String(),
+	}, nil
+}
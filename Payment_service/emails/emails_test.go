@@ -0,0 +1,104 @@
+package emails_test
+
+import (
+	"strings"
+	"testing"
+
+	"payment/emails"
+)
+
+func load(t *testing.T) *emails.Templates {
+	t.Helper()
+	templates, err := emails.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return templates
+}
+
+func TestRender_EverySampleInEveryLocale(t *testing.T) {
+	templates := load(t)
+	for _, locale := range emails.Locales {
+		for _, name := range emails.Names {
+			email, err := templates.Render(name, locale, emails.Samples[name])
+			if err != nil {
+				t.Fatalf("%s/%s: %v", locale, name, err)
+			}
+			if email.Subject == "" || strings.Contains(email.Subject, "\n") {
+				t.Errorf("%s/%s: subject = %q", locale, name, email.Subject)
+			}
+			if !strings.Contains(email.Text, "John") || !strings.Contains(email.HTML, "John") {
+				t.Errorf("%s/%s: the name is missing:\n%s\n%s", locale, name, email.Text, email.HTML)
+			}
+			if !strings.Contains(email.HTML, `<html lang="`+locale+`">`) {
+				t.Errorf("%s/%s: not rendered in the %s layout", locale, name, locale)
+			}
+		}
+	}
+}
+
+func TestRender_Receipt(t *testing.T) {
+	templates := load(t)
+
+	en, err := templates.Render(emails.OrderReceipt, "en", emails.Samples[emails.OrderReceipt])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if en.Subject != "Your QuickBite receipt for order 665f1c2b9a1e4b3c2d1e0f10" {
+		t.Errorf("subject = %q", en.Subject)
+	}
+	for _, want := range []string{"2 x Burger ($7.99)  $15.98", "Subtotal: $19.47", "Discount: -$1.95", "Delivery fee: $2.99", "Total: $20.51", "Includes VAT 12%: $2.20", "Jan 1, 2026 12:00 UTC",
+		"Delivery to: Kabanbay Batyr 53, Astana", "Requested: Jan 1, 2026 13:30 UTC", "Contact: John, +77001234567"} {
+		if !strings.Contains(en.Text, want) {
+			t.Errorf("text lacks %q:\n%s", want, en.Text)
+		}
+	}
+	// Menu item names come from admins and must not be able to inject markup.
+	if !strings.Contains(en.HTML, "Fries &amp; &lt;Dip&gt;") || strings.Contains(en.HTML, "<Dip>") {
+		t.Errorf("item name not escaped:\n%s", en.HTML)
+	}
+	// So do the notes customers type at checkout.
+	if !strings.Contains(en.HTML, "Ring twice &amp; &lt;leave at the door&gt;") || strings.Contains(en.HTML, "<leave") {
+		t.Errorf("notes not escaped:\n%s", en.HTML)
+	}
+
+	ru, err := templates.Render(emails.OrderReceipt, "ru-RU", emails.Samples[emails.OrderReceipt])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Скидка: -1,95 $", "Доставка: 2,99 $", "Итого: 20,51 $", "01.01.2026 12:00 UTC"} {
+		if !strings.Contains(ru.Text, want) {
+			t.Errorf("ru text lacks %q:\n%s", want, ru.Text)
+		}
+	}
+}
+
+func TestRender_StatusUpdate(t *testing.T) {
+	templates := load(t)
+	cases := []struct {
+		locale, status, subject string
+	}{
+		{"en", "OutForDelivery", "Your order is on its way"},
+		{"en", "Delivered", "Your order is delivered"},
+		{"ru", "Cancelled", "Ваш заказ отменён"},
+		{"ru", "OutForDelivery", "Ваш заказ в пути"},
+	}
+	for _, c := range cases {
+		email, err := templates.Render(emails.OrderStatus, c.locale, emails.StatusUpdate{Name: "John", OrderID: "o1", Status: c.status})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if email.Subject != c.subject {
+			t.Errorf("%s %s: subject = %q, want %q", c.locale, c.status, email.Subject, c.subject)
+		}
+	}
+}
+
+func TestLocale(t *testing.T) {
+	cases := map[string]string{"": "en", "en": "en", "RU": "ru", "ru_RU": "ru", "de": "en"}
+	for in, want := range cases {
+		if got := emails.Locale(in); got != want {
+			t.Errorf("Locale(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package emails
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Locales are the languages there are templates for. The first one is used
+// for users without a supported locale.
+var Locales = []string{"en", "ru"}
+
+// Locale returns the supported locale closest to s, so "ru-RU" becomes "ru"
+// and an unknown or empty locale becomes "en".
+func Locale(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if i := strings.IndexAny(s, "-_"); i >= 0 {
+		s = s[:i]
+	}
+	for _, l := range Locales {
+		if l == s {
+			return l
+		}
+	}
+	return Locales[0]
+}
+
+// statusNames are the order statuses as they read in a sentence.
+var statusNames = map[string]map[string]string{
+	"en": {
+		"Pending":        "pending",
+		"Confirmed":      "confirmed",
+		"Preparing":      "being prepared",
+		"ReadyForPickup": "ready for pickup",
+		"OutForDelivery": "out for delivery",
+		"Delivered":      "delivered",
+		"Cancelled":      "cancelled",
+		"Refunded":       "refunded",
+	},
+	"ru": {
+		"Pending":        "ожидает оплаты",
+		"Confirmed":      "подтверждён",
+		"Preparing":      "готовится",
+		"ReadyForPickup": "готов к выдаче",
+		"OutForDelivery": "в пути",
+		"Delivered":      "доставлен",
+		"Cancelled":      "отменён",
+		"Refunded":       "возвращён",
+	},
+}
+
+// funcsFor returns the template functions that format values for locale:
+// money (a float amount in dollars), percent (a rate such as 0.12), date and
+// status.
+func funcsFor(locale string) map[string]any {
+	return map[string]any{
+		"money": func(amount float64) string {
+			if locale == "ru" {
+				return strings.Replace(fmt.Sprintf("%.2f", amount), ".", ",", 1) + " $"
+			}
+			return fmt.Sprintf("$%.2f", amount)
+		},
+		"percent": func(rate float64) string {
+			p := strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", rate*100), "0"), ".")
+			if locale == "ru" {
+				p = strings.Replace(p, ".", ",", 1)
+			}
+			return p + "%"
+		},
+		"date": func(t time.Time) string {
+			if t.IsZero() {
+				return ""
+			}
+			if locale == "ru" {
+				return t.UTC().Format("02.01.2006 15:04 UTC")
+			}
+			return t.UTC().Format("Jan 2, 2006 15:04 UTC")
+		},
+		"status": func(status string) string {
+			if name, ok := statusNames[locale][status]; ok {
+				return name
+			}
+			return status
+		},
+	}
+}
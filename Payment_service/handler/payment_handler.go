@@ -0,0 +1,257 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"payment/identity"
+	"payment/notifications"
+	"payment/payments"
+	pb "payment/proto"
+	"payment/receipt"
+)
+
+const maxIdempotencyKeyLen = 255
+
+type PaymentHandler struct {
+	pb.UnimplementedPaymentServiceServer
+	svc           *payments.Service
+	receipts      *receipt.Orders
+	notifications *notifications.Notifier
+}
+
+func NewPaymentHandler(svc *payments.Service, receipts *receipt.Orders, notifier *notifications.Notifier) *PaymentHandler {
+	return &PaymentHandler{svc: svc, receipts: receipts, notifications: notifier}
+}
+
+func (h *PaymentHandler) AuthorizePayment(ctx context.Context, req *pb.AuthorizePaymentRequest) (*pb.AuthorizePaymentResponse, error) {
+	caller, ok := identity.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing caller identity")
+	}
+	if req.OrderId == "" || strings.TrimSpace(req.PaymentMethod) == "" {
+		return nil, status.Error(codes.InvalidArgument, "order_id and payment_method are required")
+	}
+
+	if len(req.IdempotencyKey) > maxIdempotencyKeyLen {
+		return nil, status.Errorf(codes.InvalidArgument, "idempotency key is longer than %d characters", maxIdempotencyKeyLen)
+	}
+
+	p, replayed, err := h.svc.Authorize(ctx, caller, payments.AuthorizeParams{
+		OrderID:        req.OrderId,
+		PaymentMethod:  strings.TrimSpace(req.PaymentMethod),
+		Capture:        req.Capture,
+		IdempotencyKey: req.IdempotencyKey,
+	})
+	if err != nil {
+		return nil, paymentError(ctx, err, "failed to authorize payment")
+	}
+	return &pb.AuthorizePaymentResponse{Payment: toPbPayment(p), Replayed: replayed}, nil
+}
+
+func (h *PaymentHandler) CapturePayment(ctx context.Context, req *pb.CapturePaymentRequest) (*pb.CapturePaymentResponse, error) {
+	caller, ok := identity.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing caller identity")
+	}
+
+	p, err := h.svc.Capture(ctx, caller, req.Id)
+	if err != nil {
+		return nil, paymentError(ctx, err, "failed to capture payment")
+	}
+	return &pb.CapturePaymentResponse{Payment: toPbPayment(p)}, nil
+}
+
+func (h *PaymentHandler) RefundPayment(ctx context.Context, req *pb.RefundPaymentRequest) (*pb.RefundPaymentResponse, error) {
+	caller, ok := identity.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing caller identity")
+	}
+	if !caller.IsAdmin() {
+		return nil, status.Error(codes.PermissionDenied, "only admins can refund payments")
+	}
+
+	p, err := h.svc.Refund(ctx, caller, req.Id, req.Amount, req.Reason)
+	if err != nil {
+		return nil, paymentError(ctx, err, "failed to refund payment")
+	}
+	return &pb.RefundPaymentResponse{Payment: toPbPayment(p)}, nil
+}
+
+func (h *PaymentHandler) GetPayment(ctx context.Context, req *pb.GetPaymentRequest) (*pb.GetPaymentResponse, error) {
+	caller, ok := identity.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing caller identity")
+	}
+
+	p, err := h.svc.Get(ctx, caller, req.Id)
+	if err != nil {
+		return nil, paymentError(ctx, err, "failed to get payment")
+	}
+	return &pb.GetPaymentResponse{Payment: toPbPayment(p)}, nil
+}
+
+func (h *PaymentHandler) GetReceipt(ctx context.Context, req *pb.GetReceiptRequest) (*pb.GetReceiptResponse, error) {
+	caller, ok := identity.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing caller identity")
+	}
+	if req.OrderId == "" {
+		return nil, status.Error(codes.InvalidArgument, "order_id is required")
+	}
+
+	r, err := h.receipts.Receipt(ctx, caller, req.OrderId)
+	switch {
+	case errors.Is(err, receipt.ErrOrderNotFound):
+		return nil, status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, receipt.ErrForbidden):
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	case err != nil:
+		slog.ErrorContext(ctx, "failed to build receipt", "order_id", req.OrderId, "err", err)
+		return nil, status.Error(codes.Internal, "failed to build receipt")
+	}
+	pdf, err := h.receipts.Store.PDF(r)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to generate receipt", "order_id", req.OrderId, "err", err)
+		return nil, status.Error(codes.Internal, "failed to generate receipt")
+	}
+	return &pb.GetReceiptResponse{Pdf: pdf, Filename: "receipt-" + r.OrderID + ".pdf"}, nil
+}
+
+// Notifications are listed defaultNotificationLimit at a time, and at most
+// maxNotificationLimit.
+const (
+	defaultNotificationLimit = 50
+	maxNotificationLimit     = 200
+)
+
+func (h *PaymentHandler) ListNotifications(ctx context.Context, req *pb.ListNotificationsRequest) (*pb.ListNotificationsResponse, error) {
+	caller, ok := identity.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing caller identity")
+	}
+	if !caller.IsAdmin() {
+		return nil, status.Error(codes.PermissionDenied, "only admins can list notifications")
+	}
+	switch req.Status {
+	case "", notifications.StatusPending, notifications.StatusSent, notifications.StatusFailed:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown notification status %q", req.Status)
+	}
+	if req.Limit < 0 || req.Offset < 0 {
+		return nil, status.Error(codes.InvalidArgument, "limit and offset must not be negative")
+	}
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultNotificationLimit
+	}
+	limit = min(limit, maxNotificationLimit)
+
+	list, err := h.notifications.List(ctx, req.Status, limit, req.Offset)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to list notifications", "err", err)
+		return nil, status.Error(codes.Internal, "failed to list notifications")
+	}
+	res := &pb.ListNotificationsResponse{Notifications: make([]*pb.Notification, 0, len(list))}
+	for i := range list {
+		res.Notifications = append(res.Notifications, toPbNotification(&list[i]))
+	}
+	return res, nil
+}
+
+func (h *PaymentHandler) ResendNotification(ctx context.Context, req *pb.ResendNotificationRequest) (*pb.ResendNotificationResponse, error) {
+	caller, ok := identity.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing caller identity")
+	}
+	if !caller.IsAdmin() {
+		return nil, status.Error(codes.PermissionDenied, "only admins can resend notifications")
+	}
+
+	n, err := h.notifications.Resend(ctx, req.Id)
+	switch {
+	case errors.Is(err, notifications.ErrNotFound):
+		return nil, status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, notifications.ErrNotFailed):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case err != nil:
+		slog.ErrorContext(ctx, "failed to resend notification", "notification_id", req.Id, "err", err)
+		return nil, status.Error(codes.Internal, "failed to resend notification")
+	}
+	slog.InfoContext(ctx, "notification resent by admin", "notification_id", n.ID, "admin_id", caller.UserID, "status", n.Status)
+	return &pb.ResendNotificationResponse{Notification: toPbNotification(n)}, nil
+}
+
+// paymentError maps service and repository errors onto gRPC status codes.
+func paymentError(ctx context.Context, err error, fallback string) error {
+	switch {
+	case errors.Is(err, payments.ErrOrderNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return status.Error(codes.NotFound, "payment not found")
+	case errors.Is(err, payments.ErrForbidden):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, payments.ErrOrderAlreadyPaid), errors.Is(err, payments.ErrIdempotencyConflict):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, payments.ErrIdempotencyInProgress):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, payments.ErrIdempotencyUnavailable):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, payments.ErrInvalidAmount):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, payments.ErrOrderNotPayable),
+		errors.Is(err, payments.ErrInvalidTransition),
+		errors.Is(err, payments.ErrStatusChanged):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	slog.ErrorContext(ctx, fallback, "err", err)
+	return status.Error(codes.Internal, fallback)
+}
+
+func toPbPayment(p *payments.Payment) *pb.Payment {
+	return &pb.Payment{
+		Id:             p.ID,
+		OrderId:        p.OrderID,
+		UserId:         p.UserID,
+		Amount:         p.Amount,
+		Currency:       p.Currency,
+		Status:         p.Status,
+		Provider:       p.Provider,
+		DeclineReason:  p.DeclineReason,
+		RefundedAmount: p.RefundedAmount,
+		CreatedAt:      p.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      p.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func toPbNotification(n *notifications.Notification) *pb.Notification {
+	return &pb.Notification{
+		Id:            n.ID,
+		Key:           n.Key,
+		To:            n.To,
+		Template:      n.Template,
+		Subject:       n.Subject,
+		Status:        n.Status,
+		Attempts:      int32(n.Attempts),
+		LastError:     n.LastError,
+		NextAttemptAt: formatTime(n.NextAttemptAt),
+		CreatedAt:     formatTime(n.CreatedAt),
+		UpdatedAt:     formatTime(n.UpdatedAt),
+		SentAt:        formatTime(n.SentAt),
+	}
+}
+
+// formatTime formats t as RFC 3339, and the zero time as "".
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
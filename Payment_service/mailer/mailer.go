@@ -1,81 +1,60 @@
 package mailer
 
 import (
-	"bytes"
-	"fmt"
-	"log"
+	"io"
 
 	"github.com/go-mail/mail"
-	"github.com/jung-kurt/gofpdf"
 )
 
+// Mailer composes emails and hands them to a Transport.
 type Mailer struct {
-	Host     string
-	Port     int
-	Username string
-	Password string
-	From     string
+	Transport Transport
+	From      string
 }
 
-func NewMailer(host string, port int, username, password, from string) *Mailer {
+func NewMailer(transport Transport, from string) *Mailer {
 	return &Mailer{
-		Host:     host,
-		Port:     port,
-		Username: username,
-		Password: password,
-		From:     from,
+		Transport: transport,
+		From:      from,
 	}
 }
 
 func (m *Mailer) Send(to string, subject string, plainBody string) error {
-	msg := mail.NewMessage()
-	msg.SetHeader("From", m.From)
-	msg.SetHeader("To", to)
-	msg.SetHeader("Subject", subject)
+	msg := m.message(to, subject)
 	msg.SetBody("text/plain", plainBody)
-
-	d := mail.NewDialer(m.Host, m.Port, m.Username, m.Password)
-	return d.DialAndSend(msg)
+	return mail.Send(m.Transport, msg)
 }
 
-func (m *Mailer) SendWithPDF(to string, subject string, htmlBody string, pdfBytes []byte) error {
-	msg := mail.NewMessage()
-	msg.SetHeader("From", m.From)
-	msg.SetHeader("To", to)
-	msg.SetHeader("Subject", subject)
-	msg.SetBody("text/html", htmlBody)
-
-	msg.AttachReader("receipt.pdf", bytes.NewReader(pdfBytes))
-
-	d := mail.NewDialer(m.Host, m.Port, m.Username, m.Password)
-	return d.DialAndSend(msg)
+// Attachment is a file sent along with an email.
+type Attachment struct {
+	Name string
+	Data []byte
 }
 
-func (m *Mailer) GeneratePDFReceipt(orderID, userID string, items []string, total float64) ([]byte, error) {
-	pdf := gofpdf.New("P", "mm", "A4", "")
-	pdf.AddPage()
-	pdf.SetFont("Arial", "", 14)
-
-	pdf.Cell(40, 10, fmt.Sprintf("Order Receipt #%s", orderID))
-	pdf.Ln(12)
-	pdf.Cell(40, 10, fmt.Sprintf("User ID: %s", userID))
-	pdf.Ln(12)
-
-	pdf.Cell(40, 10, "Items:")
-	for _, item := range items {
-		pdf.Ln(8)
-		pdf.Cell(40, 10, fmt.Sprintf("- %s", item))
+// SendMultipart sends an email whose body is plain text with an HTML
+// alternative, which mail clients prefer when they can show it.
+func (m *Mailer) SendMultipart(to, subject, textBody, htmlBody string, attachments ...Attachment) error {
+	msg := m.message(to, subject)
+	msg.SetBody("text/plain", textBody)
+	msg.AddAlternative("text/html", htmlBody)
+
+	// The transport may write the message more than once, so every write
+	// copies the attachment from the start.
+	for _, a := range attachments {
+		data := a.Data
+		msg.Attach(a.Name, mail.SetCopyFunc(func(w io.Writer) error {
+			_, err := w.Write(data)
+			return err
+		}))
 	}
 
-	pdf.Ln(12)
-	pdf.Cell(40, 10, fmt.Sprintf("Total: %.2f USD", total))
-
-	var buf bytes.Buffer
-	err := pdf.Output(&buf)
-	if err != nil {
-		log.Printf("PDF generation failed: %v", err)
-		return nil, err
-	}
+	return mail.Send(m.Transport, msg)
+}
 
-	return buf.Bytes(), nil
+func (m *Mailer) message(to, subject string) *mail.Message {
+	msg := mail.NewMessage()
+	msg.SetHeader("From", m.From)
+	msg.SetHeader("To", to)
+	msg.SetHeader("Subject", subject)
+	return msg
 }
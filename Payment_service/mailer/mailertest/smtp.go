@@ -0,0 +1,181 @@
+// Package mailertest provides a local SMTP stand-in for tests that exercise
+// mailer.Mailer end to end.
+package mailertest
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Message is one mail accepted by the Server.
+type Message struct {
+	From string
+	To   []string
+	Data string
+}
+
+// Server accepts any mail without authentication or TLS and keeps it in
+// memory.
+type Server struct {
+	Host string
+	Port int
+
+	ln          net.Listener
+	mu          sync.Mutex
+	messages    []Message
+	connections int
+	expired     map[net.Conn]bool
+	rejectNext  bool
+	received    chan struct{}
+}
+
+// NewServer starts a Server on a random local port and stops it when the
+// test ends.
+func NewServer(t *testing.T) *Server {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().(*net.TCPAddr)
+	s := &Server{Host: "127.0.0.1", Port: addr.Port, ln: ln, expired: map[net.Conn]bool{}, received: make(chan struct{}, 100)}
+	go s.serve()
+	t.Cleanup(func() { ln.Close() })
+	return s
+}
+
+// Messages returns the mails received so far.
+func (s *Server) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.messages...)
+}
+
+// Connections returns how many SMTP sessions were opened so far.
+func (s *Server) Connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connections
+}
+
+// ExpireSessions makes every open SMTP session answer its next command with
+// 421 and hang up, like a server timing idle clients out.
+func (s *Server) ExpireSessions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn := range s.expired {
+		s.expired[conn] = true
+	}
+}
+
+// RejectNextMessage makes the server read the next mail to the end, then
+// answer 451 and hang up without keeping it, like a server failing mid-DATA.
+func (s *Server) RejectNextMessage() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rejectNext = true
+}
+
+// WaitForMessage blocks until a mail arrives or timeout passes.
+func (s *Server) WaitForMessage(timeout time.Duration) (Message, bool) {
+	select {
+	case <-s.received:
+		msgs := s.Messages()
+		return msgs[len(msgs)-1], true
+	case <-time.After(timeout):
+		return Message{}, false
+	}
+}
+
+func (s *Server) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.connections++
+		s.expired[conn] = false
+		s.mu.Unlock()
+		go s.handle(conn)
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.expired, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost ESMTP mailertest")
+	var msg Message
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		expired := s.expired[conn]
+		s.mu.Unlock()
+		if expired {
+			reply("421 4.4.2 localhost Idle timeout, closing connection")
+			return
+		}
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			msg = Message{From: address(line)}
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			msg.To = append(msg.To, address(line))
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" || l == ".\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			msg.Data = data.String()
+			s.mu.Lock()
+			if s.rejectNext {
+				s.rejectNext = false
+				s.mu.Unlock()
+				reply("451 4.3.0 localhost Temporary failure, closing connection")
+				return
+			}
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+			s.received <- struct{}{}
+			reply("250 OK")
+		case cmd == "RSET", cmd == "NOOP":
+			reply("250 OK")
+		case cmd == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func address(line string) string {
+	_, addr, _ := strings.Cut(line, ":")
+	return strings.Trim(strings.TrimSpace(addr), "<>")
+}
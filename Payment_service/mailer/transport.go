@@ -0,0 +1,190 @@
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-mail/mail"
+)
+
+// Transport delivers composed emails. Its Send matches mail.Sender: msg
+// writes the whole message, headers included.
+type Transport interface {
+	Send(from string, to []string, msg io.WriterTo) error
+	Close() error
+}
+
+// SMTPTransport sends mail through an SMTP server. It keeps the connection
+// open between emails, so a burst of orders is sent over one session, and
+// closes it once it has been idle for IdleTimeout. The server may drop an
+// open session at any time; an email that fails on a reused connection is
+// tried once more on a new one.
+type SMTPTransport struct {
+	dialer      *mail.Dialer
+	idleTimeout time.Duration
+
+	mu    sync.Mutex
+	conn  mail.SendCloser
+	timer *time.Timer
+}
+
+// NewSMTPTransport returns an SMTPTransport for the server at host:port. An
+// idleTimeout of 0 keeps the connection open until Close.
+func NewSMTPTransport(host string, port int, username, password string, idleTimeout time.Duration) *SMTPTransport {
+	return &SMTPTransport{
+		dialer:      mail.NewDialer(host, port, username, password),
+		idleTimeout: idleTimeout,
+	}
+}
+
+func (t *SMTPTransport) Send(from string, to []string, msg io.WriterTo) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	reused := t.conn != nil
+	err := t.sendLocked(from, to, msg)
+	if err != nil && reused {
+		err = t.sendLocked(from, to, msg)
+	}
+	if err != nil {
+		return err
+	}
+
+	if t.idleTimeout > 0 {
+		if t.timer == nil {
+			t.timer = time.AfterFunc(t.idleTimeout, t.closeIdle)
+		} else {
+			t.timer.Reset(t.idleTimeout)
+		}
+	}
+	return nil
+}
+
+func (t *SMTPTransport) sendLocked(from string, to []string, msg io.WriterTo) error {
+	if t.conn == nil {
+		conn, err := t.dialer.Dial()
+		if err != nil {
+			return err
+		}
+		t.conn = conn
+	}
+	if err := t.conn.Send(from, to, msg); err != nil {
+		// The session may be stuck halfway through this message, so the
+		// next one starts on a new connection.
+		t.closeLocked()
+		return err
+	}
+	return nil
+}
+
+func (t *SMTPTransport) closeIdle() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closeLocked()
+}
+
+func (t *SMTPTransport) closeLocked() error {
+	if t.conn == nil {
+		return nil
+	}
+	err := t.conn.Close()
+	t.conn = nil
+	return err
+}
+
+// Close ends the open SMTP session, if any. The transport can still be used
+// afterwards; it dials again.
+func (t *SMTPTransport) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+	return t.closeLocked()
+}
+
+// FileTransport writes every email to its own .eml file in Dir instead of
+// sending it, for running the service without a mail server. The files open
+// in any mail client.
+type FileTransport struct {
+	Dir string
+
+	mu  sync.Mutex
+	seq int
+}
+
+// NewFileTransport returns a FileTransport writing to dir, creating it if
+// needed.
+func NewFileTransport(dir string) (*FileTransport, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileTransport{Dir: dir}, nil
+}
+
+func (t *FileTransport) Send(from string, to []string, msg io.WriterTo) error {
+	var buf bytes.Buffer
+	if _, err := msg.WriteTo(&buf); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	t.seq++
+	name := fmt.Sprintf("%s-%04d.eml", time.Now().UTC().Format("20060102-150405.000000"), t.seq)
+	t.mu.Unlock()
+
+	return os.WriteFile(filepath.Join(t.Dir, name), buf.Bytes(), 0o644)
+}
+
+func (t *FileTransport) Close() error { return nil }
+
+// Message is an email kept by a MemoryTransport.
+type Message struct {
+	From string
+	To   []string
+	Data string
+}
+
+// MemoryTransport keeps emails in memory instead of sending them, for tests
+// and local runs. It holds the last Limit emails, or all of them if Limit
+// is 0.
+type MemoryTransport struct {
+	Limit int
+
+	mu       sync.Mutex
+	messages []Message
+}
+
+// NewMemoryTransport returns a MemoryTransport holding up to limit emails.
+func NewMemoryTransport(limit int) *MemoryTransport {
+	return &MemoryTransport{Limit: limit}
+}
+
+func (t *MemoryTransport) Send(from string, to []string, msg io.WriterTo) error {
+	var buf bytes.Buffer
+	if _, err := msg.WriteTo(&buf); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.messages = append(t.messages, Message{From: from, To: append([]string(nil), to...), Data: buf.String()})
+	if t.Limit > 0 && len(t.messages) > t.Limit {
+		t.messages = append([]Message(nil), t.messages[len(t.messages)-t.Limit:]...)
+	}
+	return nil
+}
+
+// Messages returns the emails kept so far, oldest first.
+func (t *MemoryTransport) Messages() []Message {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Message(nil), t.messages...)
+}
+
+func (t *MemoryTransport) Close() error { return nil }
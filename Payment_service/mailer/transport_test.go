@@ -0,0 +1,158 @@
+package mailer_test
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"payment/mailer"
+	"payment/mailer/mailertest"
+)
+
+func TestSMTPTransport_ReusesConnection(t *testing.T) {
+	smtp := mailertest.NewServer(t)
+	transport := mailer.NewSMTPTransport(smtp.Host, smtp.Port, "", "", 0)
+	defer transport.Close()
+	m := mailer.NewMailer(transport, "noreply@quickbite.test")
+
+	for i := 0; i < 3; i++ {
+		if err := m.Send("john@example.com", "Hello", "Hi John"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := len(smtp.Messages()); n != 3 {
+		t.Fatalf("server got %d messages, want 3", n)
+	}
+	if n := smtp.Connections(); n != 1 {
+		t.Errorf("opened %d connections for 3 emails, want 1", n)
+	}
+
+	if err := transport.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Send("john@example.com", "Hello", "Hi again"); err != nil {
+		t.Fatalf("send after Close: %v", err)
+	}
+	if n := smtp.Connections(); n != 2 {
+		t.Errorf("connections = %d after Close, want 2", n)
+	}
+}
+
+func TestSMTPTransport_RedialsDroppedSession(t *testing.T) {
+	smtp := mailertest.NewServer(t)
+	transport := mailer.NewSMTPTransport(smtp.Host, smtp.Port, "", "", 0)
+	defer transport.Close()
+	m := mailer.NewMailer(transport, "noreply@quickbite.test")
+
+	if err := m.Send("john@example.com", "Hello", "Hi"); err != nil {
+		t.Fatal(err)
+	}
+	smtp.ExpireSessions()
+	if err := m.Send("john@example.com", "Hello", "Hi again"); err != nil {
+		t.Fatalf("send after the server dropped the session: %v", err)
+	}
+	if n := len(smtp.Messages()); n != 2 {
+		t.Errorf("server got %d messages, want 2", n)
+	}
+	if n := smtp.Connections(); n != 2 {
+		t.Errorf("connections = %d, want one redial", n)
+	}
+}
+
+func TestSMTPTransport_RetryKeepsAttachment(t *testing.T) {
+	smtp := mailertest.NewServer(t)
+	transport := mailer.NewSMTPTransport(smtp.Host, smtp.Port, "", "", 0)
+	defer transport.Close()
+	m := mailer.NewMailer(transport, "noreply@quickbite.test")
+	pdf := []byte("%PDF-1.4 receipt of order 42")
+
+	if err := m.Send("john@example.com", "Hello", "Hi"); err != nil {
+		t.Fatal(err)
+	}
+	smtp.RejectNextMessage()
+	if err := m.SendMultipart("john@example.com", "Your receipt", "Total: $5.00", "<p>Total: $5.00</p>",
+		mailer.Attachment{Name: "receipt.pdf", Data: pdf}); err != nil {
+		t.Fatalf("send after the server failed mid-DATA: %v", err)
+	}
+
+	msgs := smtp.Messages()
+	if len(msgs) != 2 {
+		t.Fatalf("server kept %d messages, want 2", len(msgs))
+	}
+	if !strings.Contains(msgs[1].Data, base64.StdEncoding.EncodeToString(pdf)) {
+		t.Errorf("resent attachment does not carry the PDF:\n%s", msgs[1].Data)
+	}
+}
+
+func TestSMTPTransport_ClosesIdleConnection(t *testing.T) {
+	smtp := mailertest.NewServer(t)
+	transport := mailer.NewSMTPTransport(smtp.Host, smtp.Port, "", "", 20*time.Millisecond)
+	defer transport.Close()
+	m := mailer.NewMailer(transport, "noreply@quickbite.test")
+
+	if err := m.Send("john@example.com", "Hello", "Hi"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if err := m.Send("john@example.com", "Hello", "Hi"); err != nil {
+		t.Fatal(err)
+	}
+	if n := smtp.Connections(); n != 2 {
+		t.Errorf("connections = %d, want a new one after the idle timeout", n)
+	}
+}
+
+func TestFileTransport(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "outbox")
+	transport, err := mailer.NewFileTransport(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := mailer.NewMailer(transport, "noreply@quickbite.test")
+
+	if err := m.SendMultipart("john@example.com", "Your receipt", "Total: $5.00", "<p>Total: $5.00</p>",
+		mailer.Attachment{Name: "receipt.pdf", Data: []byte("%PDF-")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Send("jane@example.com", "Hello", "Hi Jane"); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("files = %v, want 2 .eml files", files)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"To: john@example.com", "Subject: Your receipt", "text/html", `filename="receipt.pdf"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("%s lacks %q:\n%s", files[0], want, data)
+		}
+	}
+}
+
+func TestMemoryTransport(t *testing.T) {
+	transport := mailer.NewMemoryTransport(2)
+	m := mailer.NewMailer(transport, "noreply@quickbite.test")
+
+	for _, to := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		if err := m.Send(to, "Hello", "Hi"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	msgs := transport.Messages()
+	if len(msgs) != 2 || msgs[0].To[0] != "b@example.com" || msgs[1].To[0] != "c@example.com" {
+		t.Fatalf("messages = %+v, want the last two", msgs)
+	}
+	if msgs[1].From != "noreply@quickbite.test" || !strings.Contains(msgs[1].Data, "Subject: Hello") {
+		t.Errorf("message = %+v", msgs[1])
+	}
+}
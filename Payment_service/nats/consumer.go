@@ -1,67 +1,230 @@
 package nats
 
 import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"log"
+	"foodstore/events"
+	"foodstore/logging"
+	"log/slog"
+	"time"
 
 	"github.com/nats-io/nats.go"
+	"payment/emails"
 	"payment/mailer"
+	"payment/notifications"
+	"payment/receipt"
 )
 
-type OrderCreatedEvent struct {
-	OrderID   string   `json:"orderId"`
-	UserID    string   `json:"userId"`
-	Items     []string `json:"items"`
-	Total     float64  `json:"total"`
-	CreatedAt string   `json:"createdAt"`
+// PasswordResetRequestedEvent is published by UserService on
+// user.password_reset_requested.
+type PasswordResetRequestedEvent struct {
+	UserID    string `json:"userId"`
+	Email     string `json:"email"`
+	Username  string `json:"username"`
+	Locale    string `json:"locale,omitempty"`
+	ResetURL  string `json:"resetUrl"`
+	ExpiresAt string `json:"expiresAt"`
+}
+
+// AccountLockedEvent is published by UserService on user.account_locked.
+type AccountLockedEvent struct {
+	UserID         string `json:"userId"`
+	Email          string `json:"email"`
+	Username       string `json:"username"`
+	Locale         string `json:"locale,omitempty"`
+	FailedAttempts int64  `json:"failedAttempts"`
+	LockedUntil    string `json:"lockedUntil"`
+	ClientIP       string `json:"clientIp"`
 }
 
 type EmailWorker struct {
-	Mailer     *mailer.Mailer
-	GetEmailFn func(userID string) (string, error)
+	// Notifier sends the emails, retries them and drops repeats of an
+	// event it has already mailed.
+	Notifier  *notifications.Notifier
+	Templates *emails.Templates
+	// GetUserFn returns who to mail about the orders of a user.
+	GetUserFn func(ctx context.Context, userID string) (Recipient, error)
+	// GetMenuItemsFn names the items of orders placed before line items
+	// existed.
+	GetMenuItemsFn receipt.Menu
+	// Store is printed on receipts.
+	Store receipt.Store
+	// StatusEmails selects the status changes mailed by
+	// HandleOrderStatusChanged.
+	StatusEmails StatusTransitions
+}
+
+// Recipient is the user an email goes to.
+type Recipient struct {
+	Email  string
+	Name   string
+	Locale string
+}
+
+// msgContext carries the request id of the publisher into the handling of m.
+func msgContext(m *nats.Msg) context.Context {
+	return headerContext(context.Background(), m.Header)
 }
 
-func (e *EmailWorker) HandleOrderCreated(m *nats.Msg) {
-	var evt OrderCreatedEvent
+func headerContext(ctx context.Context, h nats.Header) context.Context {
+	if h != nil {
+		if id := h.Get(logging.RequestIDKey); id != "" {
+			ctx = logging.WithRequestID(ctx, id)
+		}
+	}
+	return ctx
+}
+
+// HandleOrderCreated mails the receipt of a new order. It is a Handler for
+// the OrderCreatedConsumer; failures are retried by the consumer.
+func (e *EmailWorker) HandleOrderCreated(ctx context.Context, data []byte) error {
+	var evt events.OrderCreated
+	env, err := events.Decode(events.TypeOrderCreated, data)
+	if err == nil {
+		err = env.Open(&evt)
+	}
+	if err != nil {
+		return Permanent(fmt.Errorf("invalid %s event: %w", events.TypeOrderCreated, err))
+	}
+
+	slog.InfoContext(ctx, "received event", "subject", events.TypeOrderCreated, "event_id", env.GetEventId(),
+		"version", env.GetVersion(), "order_id", evt.GetOrderId())
+
+	to, err := e.GetUserFn(ctx, evt.GetUserId())
+	if err != nil {
+		return fmt.Errorf("get user %s: %w", evt.GetUserId(), err)
+	}
+	lines := make([]receipt.Line, 0, len(evt.GetLines()))
+	for _, l := range evt.GetLines() {
+		lines = append(lines, receipt.Line{
+			MenuItemID: l.GetMenuItemId(),
+			Name:       l.GetName(),
+			Quantity:   l.GetQuantity(),
+			UnitPrice:  l.GetUnitPrice(),
+			Total:      l.GetLineTotal(),
+		})
+	}
+	if len(lines) == 0 {
+		// Version 0 events carry only item ids.
+		lines = receipt.LinesFromIDs(evt.GetItemIds())
+	}
+	if err := receipt.Resolve(ctx, lines, e.GetMenuItemsFn); err != nil {
+		return fmt.Errorf("get menu items: %w", err)
+	}
+	var fulfillment *receipt.Fulfillment
+	if f := evt.GetFulfillment(); f != nil {
+		fulfillment = &receipt.Fulfillment{
+			Type:        f.GetType(),
+			ContactName: f.GetContactName(),
+			Address:     f.GetAddress(),
+			Phone:       f.GetPhone(),
+			Notes:       f.GetNotes(),
+		}
+		if f.GetRequestedAt() != nil {
+			fulfillment.RequestedAt = f.GetRequestedAt().AsTime()
+		}
+	}
+	r := e.Store.Receipt(receipt.Order{
+		ID:          evt.GetOrderId(),
+		CreatedAt:   evt.GetCreatedAt().AsTime(),
+		Lines:       lines,
+		Discount:    evt.GetDiscount(),
+		DeliveryFee: evt.GetDeliveryFee(),
+		Total:       evt.GetTotal(),
+		Fulfillment: fulfillment,
+	}, to.Name)
+
+	msg, err := e.message(to.Email, emails.OrderReceipt, to.Locale, r)
+	if err != nil {
+		return Permanent(err)
+	}
+	pdf, err := e.Store.PDF(r)
+	if err != nil {
+		return Permanent(err)
+	}
+	msg.Attachments = []mailer.Attachment{{Name: "receipt.pdf", Data: pdf}}
+	return e.Notifier.Notify(ctx, eventKey(emails.OrderReceipt, cmp.Or(env.GetEventId(), dataHash(data))), msg)
+}
+
+func (e *EmailWorker) HandlePasswordResetRequested(m *nats.Msg) {
+	ctx := msgContext(m)
+	var evt PasswordResetRequestedEvent
 	if err := json.Unmarshal(m.Data, &evt); err != nil {
-		log.Printf("[NATS] Invalid event: %v", err)
+		slog.ErrorContext(ctx, "invalid event", "subject", m.Subject, "err", err)
 		return
 	}
 
-	log.Printf("[NATS] Received order.created: %s", evt.OrderID)
+	slog.InfoContext(ctx, "received event", "subject", m.Subject, "user_id", evt.UserID)
 
-	email, err := e.GetEmailFn(evt.UserID)
+	expiresAt, _ := time.Parse(time.RFC3339, evt.ExpiresAt)
+	msg, err := e.message(evt.Email, emails.PasswordReset, evt.Locale, emails.PasswordResetLink{
+		Name:      evt.Username,
+		ResetURL:  evt.ResetURL,
+		ExpiresAt: expiresAt,
+	})
+	if err == nil {
+		// The link is useless once it expired, so it is not retried after.
+		msg.ExpiresAt = expiresAt
+		err = e.Notifier.Notify(ctx, eventKey(emails.PasswordReset, dataHash(m.Data)), msg)
+	}
 	if err != nil {
-		log.Printf("[EMAIL]Failed to get email for user %s: %v", evt.UserID, err)
+		slog.ErrorContext(ctx, "failed to send password reset", "user_id", evt.UserID, "err", err)
+	}
+}
+
+func (e *EmailWorker) HandleAccountLocked(m *nats.Msg) {
+	ctx := msgContext(m)
+	var evt AccountLockedEvent
+	if err := json.Unmarshal(m.Data, &evt); err != nil {
+		slog.ErrorContext(ctx, "invalid event", "subject", m.Subject, "err", err)
 		return
 	}
 
-	html := generateHTML(evt)
-	pdf, _ := e.Mailer.GeneratePDFReceipt(evt.OrderID, evt.UserID, evt.Items, evt.Total)
+	slog.InfoContext(ctx, "received event", "subject", m.Subject, "user_id", evt.UserID)
+
+	lockedUntil, _ := time.Parse(time.RFC3339, evt.LockedUntil)
+	msg, err := e.message(evt.Email, emails.AccountLocked, evt.Locale, emails.Lockout{
+		Name:           evt.Username,
+		FailedAttempts: evt.FailedAttempts,
+		ClientIP:       evt.ClientIP,
+		LockedUntil:    lockedUntil,
+	})
+	if err == nil {
+		err = e.Notifier.Notify(ctx, eventKey(emails.AccountLocked, dataHash(m.Data)), msg)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to send lockout notice", "user_id", evt.UserID, "err", err)
+	}
+}
 
-	err = e.Mailer.SendWithPDF(email, "Order Receipt", html, pdf)
+// message renders email name for locale as a message to address.
+func (e *EmailWorker) message(address, name, locale string, data any) (notifications.Message, error) {
+	email, err := e.Templates.Render(name, locale, data)
 	if err != nil {
-		log.Printf("[EMAIL] Failed to send: %v", err)
-	} else {
-		log.Printf("[EMAIL] Receipt sent to %s", email)
+		return notifications.Message{}, err
 	}
+	return notifications.Message{
+		To:       address,
+		Template: name,
+		Subject:  email.Subject,
+		Text:     email.Text,
+		HTML:     email.HTML,
+	}, nil
 }
 
-func generateHTML(evt OrderCreatedEvent) string {
-	list := ""
-	for _, item := range evt.Items {
-		list += "<li>" + item + "</li>"
-	}
-	return `
-		<h2>Order Receipt</h2>
-		<p><strong>Order ID:</strong> ` + evt.OrderID + `</p>
-		<p><strong>Total:</strong> $` + formatPrice(evt.Total) + `</p>
-		<p><strong>Created At:</strong> ` + evt.CreatedAt + `</p>
-		<ul>` + list + `</ul>
-	`
+// eventKey names the email name about an event, so redeliveries of the
+// event are mailed once.
+func eventKey(name, eventID string) string {
+	return name + ":" + eventID
 }
 
-func formatPrice(f float64) string {
-	return fmt.Sprintf("%.2f", f)
+// dataHash identifies events published without an id, by UserService or
+// before event envelopes, by their content.
+func dataHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
 }
@@ -0,0 +1,147 @@
+package nats_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime/quotedprintable"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	natslib "github.com/nats-io/nats.go"
+
+	"payment/emails"
+	"payment/mailer"
+	"payment/mailer/mailertest"
+	"payment/nats"
+	"payment/notifications"
+	"payment/notifications/notificationstest"
+	"payment/receipt"
+)
+
+func runServer(t *testing.T) *server.Server {
+	t.Helper()
+	srv, err := server.NewServer(&server.Options{
+		Host: "127.0.0.1", Port: server.RANDOM_PORT, NoLog: true, NoSigs: true,
+		JetStream: true, StoreDir: t.TempDir(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Start()
+	if !srv.ReadyForConnections(5 * time.Second) {
+		t.Fatal("embedded NATS server did not start")
+	}
+	t.Cleanup(srv.Shutdown)
+	return srv
+}
+
+// newWorker returns an EmailWorker mailing through smtp. Every user is John,
+// reading emails in locale.
+func newWorker(t *testing.T, smtp *mailertest.Server, locale string) *nats.EmailWorker {
+	t.Helper()
+	templates, err := emails.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport := mailer.NewSMTPTransport(smtp.Host, smtp.Port, "", "", 0)
+	t.Cleanup(func() { transport.Close() })
+	notifier := notifications.NewNotifier(notificationstest.NewRepository(),
+		mailer.NewMailer(transport, "noreply@quickbite.test"), notifications.DefaultConfig())
+	return &nats.EmailWorker{
+		Notifier:  notifier,
+		Templates: templates,
+		Store:     receipt.Store{Name: "QuickBite", TaxName: "VAT", TaxRate: 0.12},
+		GetUserFn: func(ctx context.Context, userID string) (nats.Recipient, error) {
+			return nats.Recipient{Email: "john@example.com", Name: "John", Locale: locale}, nil
+		},
+	}
+}
+
+// body decodes the quoted-printable parts of a mail.
+func body(msg mailertest.Message) string {
+	b, _ := io.ReadAll(quotedprintable.NewReader(strings.NewReader(msg.Data)))
+	return string(b)
+}
+
+func TestEmailWorker_PasswordResetRequested(t *testing.T) {
+	srv := runServer(t)
+	smtp := mailertest.NewServer(t)
+
+	nc, err := natslib.Connect(srv.ClientURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nc.Close()
+
+	worker := newWorker(t, smtp, "")
+	if _, err := nc.Subscribe("user.password_reset_requested", worker.HandlePasswordResetRequested); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := json.Marshal(nats.PasswordResetRequestedEvent{
+		UserID:    "665f1c2b9a1e4b3c2d1e0f01",
+		Email:     "john@example.com",
+		Username:  "john",
+		ResetURL:  "http://localhost:8082/reset-password.html?token=abc123",
+		ExpiresAt: time.Now().Add(30 * time.Minute).Format(time.RFC3339),
+	})
+	if err := nc.Publish("user.password_reset_requested", data); err != nil {
+		t.Fatal(err)
+	}
+
+	msg, ok := smtp.WaitForMessage(5 * time.Second)
+	if !ok {
+		t.Fatal("no email was sent")
+	}
+	if len(msg.To) != 1 || msg.To[0] != "john@example.com" {
+		t.Errorf("recipients: got %v", msg.To)
+	}
+	if !strings.Contains(msg.Data, "Subject: Reset your password") {
+		t.Errorf("missing subject in:\n%s", msg.Data)
+	}
+	if text := body(msg); !strings.Contains(text, "http://localhost:8082/reset-password.html?token=abc123") ||
+		!strings.Contains(text, `href="http://localhost:8082/reset-password.html?token=abc123"`) {
+		t.Errorf("missing reset link in:\n%s", text)
+	}
+}
+
+func TestEmailWorker_AccountLocked(t *testing.T) {
+	srv := runServer(t)
+	smtp := mailertest.NewServer(t)
+
+	nc, err := natslib.Connect(srv.ClientURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nc.Close()
+
+	worker := newWorker(t, smtp, "")
+	if _, err := nc.Subscribe("user.account_locked", worker.HandleAccountLocked); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := json.Marshal(nats.AccountLockedEvent{
+		UserID:         "665f1c2b9a1e4b3c2d1e0f01",
+		Email:          "john@example.com",
+		Username:       "john",
+		Locale:         "ru",
+		FailedAttempts: 10,
+		LockedUntil:    "2026-01-01T12:15:00Z",
+		ClientIP:       "203.0.113.7",
+	})
+	if err := nc.Publish("user.account_locked", data); err != nil {
+		t.Fatal(err)
+	}
+
+	msg, ok := smtp.WaitForMessage(5 * time.Second)
+	if !ok {
+		t.Fatal("no email was sent")
+	}
+	// The event carries the locale, as UserService knows it.
+	if text := body(msg); !strings.Contains(text, "Было 10 неудачных попыток") || !strings.Contains(text, "203.0.113.7") {
+		t.Errorf("unexpected lockout notice:\n%s", text)
+	}
+}
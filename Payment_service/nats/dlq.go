@@ -0,0 +1,132 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const deadLetterPrefix = "dlq."
+
+// Headers a dead-lettered message carries next to the original ones.
+const (
+	HeaderDeadLetterSubject    = "Dlq-Subject"
+	HeaderDeadLetterConsumer   = "Dlq-Consumer"
+	HeaderDeadLetterDeliveries = "Dlq-Deliveries"
+	HeaderDeadLetterError      = "Dlq-Error"
+)
+
+// DeadLetter is a message a consumer gave up on.
+type DeadLetter struct {
+	// Sequence identifies the message in the dead-letter stream.
+	Sequence   uint64
+	Subject    string
+	Consumer   string
+	Deliveries int
+	Error      string
+	FailedAt   time.Time
+	Header     nats.Header
+	Data       []byte
+}
+
+// DeadLetters inspects and replays the dead-letter stream.
+type DeadLetters struct {
+	js jetstream.JetStream
+}
+
+func NewDeadLetters(js jetstream.JetStream) *DeadLetters {
+	return &DeadLetters{js: js}
+}
+
+// List returns the dead letters, oldest first.
+func (d *DeadLetters) List(ctx context.Context) ([]DeadLetter, error) {
+	stream, err := d.js.Stream(ctx, DeadLetterStream.Name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := stream.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var letters []DeadLetter
+	if info.State.Msgs == 0 {
+		return letters, nil
+	}
+	for seq := info.State.FirstSeq; seq <= info.State.LastSeq; seq++ {
+		raw, err := stream.GetMsg(ctx, seq)
+		if errors.Is(err, jetstream.ErrMsgNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		letters = append(letters, deadLetterFrom(raw))
+	}
+	return letters, nil
+}
+
+func (d *DeadLetters) Get(ctx context.Context, seq uint64) (DeadLetter, error) {
+	stream, err := d.js.Stream(ctx, DeadLetterStream.Name)
+	if err != nil {
+		return DeadLetter{}, err
+	}
+	raw, err := stream.GetMsg(ctx, seq)
+	if err != nil {
+		return DeadLetter{}, err
+	}
+	return deadLetterFrom(raw), nil
+}
+
+// Replay publishes a dead letter on its original subject again and removes it
+// from the dead-letter stream. Every consumer of that subject receives it.
+func (d *DeadLetters) Replay(ctx context.Context, seq uint64) error {
+	letter, err := d.Get(ctx, seq)
+	if err != nil {
+		return err
+	}
+	if letter.Subject == "" {
+		return fmt.Errorf("dead letter %d has no %s header", seq, HeaderDeadLetterSubject)
+	}
+
+	msg := nats.NewMsg(letter.Subject)
+	msg.Data = letter.Data
+	for key, values := range letter.Header {
+		// A message id would make the stream drop the replay as a duplicate.
+		if strings.HasPrefix(key, "Dlq-") || key == nats.MsgIdHdr {
+			continue
+		}
+		msg.Header[key] = values
+	}
+	if _, err := d.js.PublishMsg(ctx, msg); err != nil {
+		return fmt.Errorf("republish dead letter %d: %w", seq, err)
+	}
+	return d.Delete(ctx, seq)
+}
+
+func (d *DeadLetters) Delete(ctx context.Context, seq uint64) error {
+	stream, err := d.js.Stream(ctx, DeadLetterStream.Name)
+	if err != nil {
+		return err
+	}
+	return stream.DeleteMsg(ctx, seq)
+}
+
+func deadLetterFrom(raw *jetstream.RawStreamMsg) DeadLetter {
+	letter := DeadLetter{
+		Sequence: raw.Sequence,
+		Subject:  raw.Header.Get(HeaderDeadLetterSubject),
+		Consumer: raw.Header.Get(HeaderDeadLetterConsumer),
+		Error:    raw.Header.Get(HeaderDeadLetterError),
+		FailedAt: raw.Time,
+		Header:   raw.Header,
+		Data:     raw.Data,
+	}
+	letter.Deliveries, _ = strconv.Atoi(raw.Header.Get(HeaderDeadLetterDeliveries))
+	return letter
+}
@@ -0,0 +1,236 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"foodstore/events"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// OrdersStream keeps order events for a week, so the consumers here catch up
+// on what was published while Payment_service was down. OrderService
+// declares the same stream; whichever service starts first creates it.
+var OrdersStream = jetstream.StreamConfig{
+	Name:       "ORDERS",
+	Subjects:   []string{"order.>"},
+	Storage:    jetstream.FileStorage,
+	MaxAge:     7 * 24 * time.Hour,
+	Duplicates: 10 * time.Minute,
+}
+
+// PaymentsStream keeps payment events for a week, so OrderService confirms
+// orders paid while it was down. OrderService declares the same stream.
+var PaymentsStream = jetstream.StreamConfig{
+	Name:       "PAYMENTS",
+	Subjects:   []string{"payment.>"},
+	Storage:    jetstream.FileStorage,
+	MaxAge:     7 * 24 * time.Hour,
+	Duplicates: 10 * time.Minute,
+}
+
+// DeadLetterStream keeps the messages consumers gave up on, under
+// "dlq." followed by their original subject, until they are replayed or
+// deleted with cmd/dlq.
+var DeadLetterStream = jetstream.StreamConfig{
+	Name:     "DLQ",
+	Subjects: []string{deadLetterPrefix + ">"},
+	Storage:  jetstream.FileStorage,
+	MaxAge:   30 * 24 * time.Hour,
+}
+
+// EnsureStreams creates the streams this service reads and writes unless they
+// exist.
+func EnsureStreams(ctx context.Context, js jetstream.JetStream) error {
+	for _, cfg := range []jetstream.StreamConfig{OrdersStream, PaymentsStream, DeadLetterStream} {
+		_, err := js.CreateStream(ctx, cfg)
+		if err != nil && !errors.Is(err, jetstream.ErrStreamNameAlreadyInUse) {
+			return fmt.Errorf("create stream %s: %w", cfg.Name, err)
+		}
+	}
+	return nil
+}
+
+// Handler processes the data of one message. An error makes the consumer
+// deliver the message again later, unless it is Permanent.
+type Handler func(ctx context.Context, data []byte) error
+
+type permanentError struct {
+	err error
+}
+
+// Permanent marks an error that retrying cannot fix, such as a malformed
+// event. The message goes to the dead-letter stream right away.
+func Permanent(err error) error {
+	return permanentError{err: err}
+}
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+type ConsumerConfig struct {
+	// Name is the durable consumer name; it keeps the position in the stream
+	// across restarts.
+	Name    string
+	Stream  string
+	Subject string
+	// MaxDeliver is how many times a message is handled before it is dead
+	// lettered.
+	MaxDeliver int
+	// AckWait is how long a delivery may take before the message is sent
+	// again, e.g. because the process died while handling it.
+	AckWait time.Duration
+	// Backoff[n-1] is the wait after the nth failed delivery; the last entry
+	// repeats.
+	Backoff []time.Duration
+}
+
+// OrderCreatedConsumer sends the receipt for every new order.
+func OrderCreatedConsumer() ConsumerConfig {
+	return ConsumerConfig{
+		Name:       "payment-order-created",
+		Stream:     OrdersStream.Name,
+		Subject:    events.TypeOrderCreated,
+		MaxDeliver: 5,
+		AckWait:    30 * time.Second,
+		Backoff:    []time.Duration{time.Second, 10 * time.Second, time.Minute, 5 * time.Minute},
+	}
+}
+
+// OrderStatusChangedConsumer mails customers about the progress of their
+// orders. It retries like OrderCreatedConsumer.
+func OrderStatusChangedConsumer() ConsumerConfig {
+	cfg := OrderCreatedConsumer()
+	cfg.Name = "payment-order-status-changed"
+	cfg.Subject = events.TypeOrderStatusChanged
+	return cfg
+}
+
+// Consumer feeds the messages of a durable JetStream consumer to a Handler,
+// acking what was handled and retrying the rest with a backoff.
+type Consumer struct {
+	js     jetstream.JetStream
+	cfg    ConsumerConfig
+	handle Handler
+}
+
+func NewConsumer(js jetstream.JetStream, cfg ConsumerConfig, handle Handler) *Consumer {
+	return &Consumer{js: js, cfg: cfg, handle: handle}
+}
+
+// Run creates or updates the durable consumer and handles its messages until
+// ctx is done.
+func (c *Consumer) Run(ctx context.Context) error {
+	// The server redelivers without limit and process moves a message to the
+	// dead-letter stream once it was delivered MaxDeliver times, so nothing
+	// is dropped without a copy there.
+	cons, err := c.js.CreateOrUpdateConsumer(ctx, c.cfg.Stream, jetstream.ConsumerConfig{
+		Durable:       c.cfg.Name,
+		FilterSubject: c.cfg.Subject,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       c.cfg.AckWait,
+		DeliverPolicy: jetstream.DeliverAllPolicy,
+	})
+	if err != nil {
+		return fmt.Errorf("create consumer %s: %w", c.cfg.Name, err)
+	}
+
+	cc, err := cons.Consume(func(m jetstream.Msg) { c.process(ctx, m) })
+	if err != nil {
+		return fmt.Errorf("consume %s: %w", c.cfg.Name, err)
+	}
+	slog.Info("consuming events", "consumer", c.cfg.Name, "stream", c.cfg.Stream, "subject", c.cfg.Subject)
+
+	// Messages in flight when ctx ends are not acked and come back after
+	// AckWait.
+	<-ctx.Done()
+	cc.Stop()
+	return nil
+}
+
+func (c *Consumer) process(ctx context.Context, m jetstream.Msg) {
+	ctx = headerContext(ctx, m.Headers())
+	meta, err := m.Metadata()
+	if err != nil {
+		slog.ErrorContext(ctx, "message without JetStream metadata", "subject", m.Subject(), "err", err)
+		_ = m.Term()
+		return
+	}
+	attempt := int(meta.NumDelivered)
+
+	if attempt > c.cfg.MaxDeliver {
+		// Earlier deliveries were never acked: the handler hung or the
+		// message could not be dead lettered.
+		c.deadLetter(ctx, m, meta, fmt.Errorf("not acknowledged after %d deliveries", attempt-1))
+		return
+	}
+
+	err = c.handle(ctx, m.Data())
+	if err == nil {
+		if err := m.Ack(); err != nil {
+			slog.WarnContext(ctx, "failed to ack event", "subject", m.Subject(), "err", err)
+		}
+		return
+	}
+
+	var permanent permanentError
+	if errors.As(err, &permanent) || attempt >= c.cfg.MaxDeliver {
+		c.deadLetter(ctx, m, meta, err)
+		return
+	}
+
+	delay := c.backoff(attempt)
+	slog.WarnContext(ctx, "failed to handle event, will retry", "subject", m.Subject(), "consumer", c.cfg.Name,
+		"attempt", attempt, "max_deliver", c.cfg.MaxDeliver, "retry_in", delay, "err", err)
+	if err := m.NakWithDelay(delay); err != nil {
+		slog.WarnContext(ctx, "failed to nak event", "subject", m.Subject(), "err", err)
+	}
+}
+
+// deadLetter copies m to the dead-letter stream and terminates it. When the
+// copy fails, m is left to be delivered again.
+func (c *Consumer) deadLetter(ctx context.Context, m jetstream.Msg, meta *jetstream.MsgMetadata, cause error) {
+	msg := nats.NewMsg(deadLetterPrefix + m.Subject())
+	msg.Data = m.Data()
+	for key, values := range m.Headers() {
+		if key == nats.MsgIdHdr {
+			continue
+		}
+		msg.Header[key] = values
+	}
+	msg.Header.Set(HeaderDeadLetterSubject, m.Subject())
+	msg.Header.Set(HeaderDeadLetterConsumer, c.cfg.Name)
+	msg.Header.Set(HeaderDeadLetterDeliveries, strconv.FormatUint(meta.NumDelivered, 10))
+	msg.Header.Set(HeaderDeadLetterError, cause.Error())
+
+	// The id keeps a retried dead lettering from storing the message twice.
+	id := fmt.Sprintf("%s:%s:%d", meta.Stream, c.cfg.Name, meta.Sequence.Stream)
+	if _, err := c.js.PublishMsg(ctx, msg, jetstream.WithMsgID(id)); err != nil {
+		slog.ErrorContext(ctx, "failed to dead letter event", "subject", m.Subject(), "err", err)
+		_ = m.NakWithDelay(c.backoff(int(meta.NumDelivered)))
+		return
+	}
+	slog.ErrorContext(ctx, "event dead lettered", "subject", m.Subject(), "consumer", c.cfg.Name,
+		"deliveries", meta.NumDelivered, "err", cause)
+	if err := m.TermWithReason(cause.Error()); err != nil {
+		slog.WarnContext(ctx, "failed to terminate event", "subject", m.Subject(), "err", err)
+	}
+}
+
+func (c *Consumer) backoff(attempt int) time.Duration {
+	if len(c.cfg.Backoff) == 0 {
+		return 0
+	}
+	if attempt > len(c.cfg.Backoff) {
+		attempt = len(c.cfg.Backoff)
+	}
+	if attempt < 1 {
+		attempt = 1
+	}
+	return c.cfg.Backoff[attempt-1]
+}
@@ -0,0 +1,270 @@
+package nats_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"foodstore/events"
+	"foodstore/logging"
+	natslib "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"payment/mailer/mailertest"
+	"payment/nats"
+	"payment/receipt"
+)
+
+// recorder is a Handler that fails while failures > 0.
+type recorder struct {
+	mu         sync.Mutex
+	calls      int
+	failures   int
+	requestIDs []string
+}
+
+func (r *recorder) handle(ctx context.Context, data []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls++
+	r.requestIDs = append(r.requestIDs, logging.RequestID(ctx))
+	if r.failures > 0 {
+		r.failures--
+		return errors.New("smtp: connection refused")
+	}
+	return nil
+}
+
+func (r *recorder) setFailures(n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failures = n
+}
+
+func (r *recorder) callCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.calls
+}
+
+func setupJetStream(t *testing.T) jetstream.JetStream {
+	t.Helper()
+	srv := runServer(t)
+	nc, err := natslib.Connect(srv.ClientURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(nc.Close)
+	js, err := jetstream.New(nc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := nats.EnsureStreams(context.Background(), js); err != nil {
+		t.Fatal(err)
+	}
+	return js
+}
+
+func testConsumerConfig() nats.ConsumerConfig {
+	cfg := nats.OrderCreatedConsumer()
+	cfg.MaxDeliver = 3
+	cfg.Backoff = []time.Duration{10 * time.Millisecond, 20 * time.Millisecond}
+	return cfg
+}
+
+func runConsumer(t *testing.T, js jetstream.JetStream, cfg nats.ConsumerConfig, handle nats.Handler) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- nats.NewConsumer(js, cfg, handle).Run(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func publish(t *testing.T, js jetstream.JetStream, data string) {
+	t.Helper()
+	msg := natslib.NewMsg(events.TypeOrderCreated)
+	msg.Data = []byte(data)
+	msg.Header.Set(logging.RequestIDKey, "req-1")
+	if _, err := js.PublishMsg(context.Background(), msg); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func deadLetters(t *testing.T, js jetstream.JetStream) []nats.DeadLetter {
+	t.Helper()
+	letters, err := nats.NewDeadLetters(js).List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return letters
+}
+
+func pendingAcks(t *testing.T, js jetstream.JetStream, name string) int {
+	t.Helper()
+	cons, err := js.Consumer(context.Background(), nats.OrdersStream.Name, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := cons.Info(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info.NumAckPending + int(info.NumPending)
+}
+
+func TestConsumer_RetriesUntilHandled(t *testing.T) {
+	js := setupJetStream(t)
+	rec := &recorder{failures: 2}
+
+	// Published before the consumer runs, as if Payment_service were down.
+	publish(t, js, `{"orderId":"order1"}`)
+
+	cfg := testConsumerConfig()
+	runConsumer(t, js, cfg, rec.handle)
+
+	waitFor(t, "the third delivery", func() bool { return rec.callCount() == 3 })
+	waitFor(t, "the ack", func() bool { return pendingAcks(t, js, cfg.Name) == 0 })
+	if letters := deadLetters(t, js); len(letters) != 0 {
+		t.Errorf("dead letters = %+v, want none", letters)
+	}
+	for _, id := range rec.requestIDs {
+		if id != "req-1" {
+			t.Errorf("request id = %q, want req-1", id)
+		}
+	}
+}
+
+func TestConsumer_DeadLettersAfterMaxDeliver(t *testing.T) {
+	js := setupJetStream(t)
+	rec := &recorder{failures: 100}
+	cfg := testConsumerConfig()
+	runConsumer(t, js, cfg, rec.handle)
+
+	publish(t, js, `{"orderId":"order1"}`)
+
+	waitFor(t, "a dead letter", func() bool { return len(deadLetters(t, js)) == 1 })
+	letter := deadLetters(t, js)[0]
+	if letter.Subject != events.TypeOrderCreated || letter.Consumer != cfg.Name || letter.Deliveries != 3 {
+		t.Errorf("dead letter = %+v", letter)
+	}
+	if letter.Error != "smtp: connection refused" {
+		t.Errorf("error = %q", letter.Error)
+	}
+	if got := letter.Header.Get(logging.RequestIDKey); got != "req-1" {
+		t.Errorf("request id header = %q, want req-1", got)
+	}
+	if string(letter.Data) != `{"orderId":"order1"}` {
+		t.Errorf("data = %s", letter.Data)
+	}
+
+	// Terminated, so the message is not delivered a fourth time.
+	time.Sleep(100 * time.Millisecond)
+	if got := rec.callCount(); got != 3 {
+		t.Errorf("handler called %d times, want 3", got)
+	}
+
+	// Once the cause is fixed, replay hands the message to the consumer again.
+	rec.setFailures(0)
+	if err := nats.NewDeadLetters(js).Replay(context.Background(), letter.Sequence); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "the replay", func() bool { return rec.callCount() == 4 })
+	waitFor(t, "the ack", func() bool { return pendingAcks(t, js, cfg.Name) == 0 })
+	if letters := deadLetters(t, js); len(letters) != 0 {
+		t.Errorf("dead letters after replay = %+v, want none", letters)
+	}
+}
+
+func TestConsumer_MalformedEventIsDeadLetteredAtOnce(t *testing.T) {
+	js := setupJetStream(t)
+	smtp := mailertest.NewServer(t)
+	worker := newWorker(t, smtp, "")
+	runConsumer(t, js, testConsumerConfig(), worker.HandleOrderCreated)
+
+	publish(t, js, `{"orderId":`)
+
+	waitFor(t, "a dead letter", func() bool { return len(deadLetters(t, js)) == 1 })
+	letter := deadLetters(t, js)[0]
+	if letter.Deliveries != 1 || !strings.Contains(letter.Error, "invalid order.created event") {
+		t.Errorf("dead letter = %+v", letter)
+	}
+}
+
+func TestEmailWorker_OrderCreatedSendsReceipt(t *testing.T) {
+	js := setupJetStream(t)
+	smtp := mailertest.NewServer(t)
+	worker := newWorker(t, smtp, "")
+	worker.GetMenuItemsFn = func(ctx context.Context, ids []string) (map[string]receipt.MenuItem, error) {
+		return map[string]receipt.MenuItem{"m1": {Name: "Cheeseburger", Price: 6.5}}, nil
+	}
+	runConsumer(t, js, testConsumerConfig(), worker.HandleOrderCreated)
+
+	// A version 0 payload, as published before event envelopes, whose items
+	// are looked up in the menu, and the current envelope.
+	publish(t, js, `{"orderId":"order0","userId":"user1","items":["m1","m1"],"total":13}`)
+	data, err := events.Marshal(events.TypeOrderCreated, &events.OrderCreated{
+		OrderId: "order1",
+		UserId:  "user1",
+		ItemIds: []string{"m2"},
+		Lines: []*events.OrderLine{
+			{MenuItemId: "m2", Name: "Fries", Quantity: 1, UnitPrice: 3.49, LineTotal: 3.49},
+		},
+		Discount:    0.35,
+		PromoCode:   "WELCOME10",
+		Total:       3.14,
+		CreatedAt:   timestamppb.Now(),
+		Fulfillment: &events.Fulfillment{Type: "pickup", Phone: "+77001234567"},
+	}, "req-1", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	publish(t, js, string(data))
+
+	for _, want := range []struct {
+		orderID string
+		lines   []string
+	}{
+		{"order0", []string{"2 x Cheeseburger ($6.50)  $13.00"}},
+		{"order1", []string{"Discount</td><td style=\"text-align:right\">-$0.35", "Includes VAT 12%: $0.34", "Pickup at the store"}},
+	} {
+		msg, ok := smtp.WaitForMessage(5 * time.Second)
+		if !ok {
+			t.Fatalf("no receipt was sent for %s", want.orderID)
+		}
+		text := body(msg)
+		if !strings.Contains(msg.Data, "Subject: Your QuickBite receipt for order "+want.orderID) ||
+			!strings.Contains(text, "<td>") || !strings.Contains(msg.Data, `filename="receipt.pdf"`) {
+			t.Errorf("unexpected receipt for %s:\n%s", want.orderID, text)
+		}
+		for _, line := range want.lines {
+			if !strings.Contains(text, line) {
+				t.Errorf("receipt for %s lacks %q:\n%s", want.orderID, line, text)
+			}
+		}
+	}
+	// A redelivered or republished event is not mailed again.
+	publish(t, js, string(data))
+	if msg, ok := smtp.WaitForMessage(300 * time.Millisecond); ok {
+		t.Errorf("receipt sent twice for one event:\n%s", msg.Data)
+	}
+}
@@ -0,0 +1,81 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"foodstore/events"
+	"foodstore/logging"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"google.golang.org/protobuf/proto"
+	"payment/payments"
+)
+
+// publishTimeout bounds how long a publish waits for JetStream to confirm it
+// stored the event.
+const publishTimeout = 5 * time.Second
+
+// Publisher announces payment events on the PAYMENTS stream. It implements
+// payments.Events; a nil error means the event is stored.
+type Publisher struct {
+	js jetstream.JetStream
+}
+
+func NewPublisher(js jetstream.JetStream) *Publisher {
+	return &Publisher{js: js}
+}
+
+// PaymentCaptured publishes payment.captured. OrderService confirms the
+// order when it receives it.
+func (p *Publisher) PaymentCaptured(ctx context.Context, payment payments.Payment) error {
+	return p.publish(ctx, events.TypePaymentCaptured, &events.PaymentCaptured{
+		PaymentId: payment.ID,
+		OrderId:   payment.OrderID,
+		UserId:    payment.UserID,
+		Amount:    payment.Amount,
+		Currency:  payment.Currency,
+	}, payment.ID+":captured", payment.UpdatedAt)
+}
+
+func (p *Publisher) PaymentRefunded(ctx context.Context, payment payments.Payment, amount int64, reason string) error {
+	return p.publish(ctx, events.TypePaymentRefunded, &events.PaymentRefunded{
+		PaymentId:     payment.ID,
+		OrderId:       payment.OrderID,
+		UserId:        payment.UserID,
+		Amount:        amount,
+		TotalRefunded: payment.RefundedAmount,
+		Currency:      payment.Currency,
+		Reason:        reason,
+	}, payment.ID+":refunded:"+strconv.FormatInt(payment.RefundedAmount, 10), payment.UpdatedAt)
+}
+
+// publish stores event on subject. msgID lets the stream drop an event that
+// is published again, e.g. by Reconcile after a failed status update.
+func (p *Publisher) publish(ctx context.Context, subject string, event proto.Message, msgID string, occurredAt time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, publishTimeout)
+	defer cancel()
+
+	data, err := events.Marshal(subject, event, logging.RequestID(ctx), occurredAt)
+	if err != nil {
+		return err
+	}
+	msg := nats.NewMsg(subject)
+	msg.Data = data
+	if id := logging.RequestID(ctx); id != "" {
+		msg.Header.Set(logging.RequestIDKey, id)
+	}
+	slog.InfoContext(ctx, "publishing event", "subject", subject, "msg_id", msgID)
+	_, err = p.js.PublishMsg(ctx, msg, jetstream.WithMsgID(msgID))
+	if errors.Is(err, jetstream.ErrNoStreamResponse) {
+		// Nobody has declared the stream yet.
+		if err := EnsureStreams(ctx, p.js); err != nil {
+			return err
+		}
+		_, err = p.js.PublishMsg(ctx, msg, jetstream.WithMsgID(msgID))
+	}
+	return err
+}
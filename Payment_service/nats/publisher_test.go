@@ -0,0 +1,78 @@
+package nats_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"foodstore/events"
+	"foodstore/logging"
+	"google.golang.org/protobuf/proto"
+
+	"payment/nats"
+	"payment/payments"
+)
+
+func TestPublisher_PaymentCaptured(t *testing.T) {
+	js := setupJetStream(t)
+	ctx := logging.WithRequestID(context.Background(), "req-1")
+	payment := payments.Payment{
+		ID:        "pay1",
+		OrderID:   "order1",
+		UserID:    "user1",
+		Amount:    1998,
+		Currency:  payments.DefaultCurrency,
+		Status:    payments.StatusCaptured,
+		UpdatedAt: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+	// The second publish stands for Reconcile retrying; the stream keeps
+	// one copy.
+	for range 2 {
+		if err := nats.NewPublisher(js).PaymentCaptured(ctx, payment); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stream, err := js.Stream(ctx, nats.PaymentsStream.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := stream.Info(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.State.Msgs != 1 {
+		t.Errorf("stream has %d messages, want 1", info.State.Msgs)
+	}
+	msg, err := stream.GetLastMsgForSubject(ctx, events.TypePaymentCaptured)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env, err := events.Decode(msg.Subject, msg.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env.GetVersion() != events.Version || env.GetCorrelationId() != "req-1" || env.GetEventId() == "" {
+		t.Errorf("envelope = %v", env)
+	}
+	if got := env.GetOccurredAt().AsTime(); !got.Equal(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("occurred at = %v", got)
+	}
+	var evt events.PaymentCaptured
+	if err := env.Open(&evt); err != nil {
+		t.Fatal(err)
+	}
+	want := &events.PaymentCaptured{
+		PaymentId: "pay1",
+		OrderId:   "order1",
+		UserId:    "user1",
+		Amount:    1998,
+		Currency:  "USD",
+	}
+	if !proto.Equal(&evt, want) {
+		t.Errorf("event = %v, want %v", &evt, want)
+	}
+	if got := msg.Header.Get(logging.RequestIDKey); got != "req-1" {
+		t.Errorf("request id header = %q, want req-1", got)
+	}
+}
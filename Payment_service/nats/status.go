@@ -0,0 +1,98 @@
+package nats
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"foodstore/events"
+	"log/slog"
+	"payment/emails"
+	"slices"
+	"strings"
+)
+
+// deletedUserID owns the orders of deleted accounts; OrderService still
+// publishes their status changes, but there is nobody to mail.
+const deletedUserID = "deleted-user"
+
+// DefaultStatusEmails are the transitions customers hear about unless
+// ORDER_STATUS_EMAILS says otherwise.
+const DefaultStatusEmails = "Confirmed,ReadyForPickup,OutForDelivery,Delivered,Cancelled,Refunded"
+
+// orderStatuses are the statuses an order can be in; OrderService defines
+// them in model/order_status.go.
+var orderStatuses = []string{
+	"Pending", "Confirmed", "Preparing", "ReadyForPickup", "OutForDelivery", "Delivered", "Cancelled", "Refunded",
+}
+
+// StatusTransitions is the set of status changes that are mailed to the
+// customer.
+type StatusTransitions struct {
+	pairs map[[2]string]bool
+}
+
+// ParseStatusTransitions reads a comma-separated list whose entries are
+// either a status, meaning any change to it, or "From>To" for one transition,
+// e.g. "Confirmed,Delivered,Preparing>Cancelled". An empty list mails nothing.
+func ParseStatusTransitions(s string) (StatusTransitions, error) {
+	t := StatusTransitions{pairs: map[[2]string]bool{}}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		from, to, ok := strings.Cut(entry, ">")
+		if !ok {
+			from, to = "*", from
+		}
+		from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+		if !slices.Contains(orderStatuses, to) {
+			return StatusTransitions{}, fmt.Errorf("unknown status %q in %q", to, entry)
+		}
+		if from != "*" && !slices.Contains(orderStatuses, from) {
+			return StatusTransitions{}, fmt.Errorf("unknown status %q in %q", from, entry)
+		}
+		t.pairs[[2]string{from, to}] = true
+	}
+	return t, nil
+}
+
+// Includes reports whether a change from one status to another is mailed.
+func (t StatusTransitions) Includes(from, to string) bool {
+	return t.pairs[[2]string{"*", to}] || t.pairs[[2]string{from, to}]
+}
+
+// HandleOrderStatusChanged tells the customer about a status change listed in
+// StatusEmails. It is a Handler for the OrderStatusChangedConsumer.
+func (e *EmailWorker) HandleOrderStatusChanged(ctx context.Context, data []byte) error {
+	var evt events.OrderStatusChanged
+	env, err := events.Decode(events.TypeOrderStatusChanged, data)
+	if err == nil {
+		err = env.Open(&evt)
+	}
+	if err != nil {
+		return Permanent(fmt.Errorf("invalid %s event: %w", events.TypeOrderStatusChanged, err))
+	}
+
+	slog.InfoContext(ctx, "received event", "subject", events.TypeOrderStatusChanged, "event_id", env.GetEventId(),
+		"order_id", evt.GetOrderId(), "from", evt.GetFromStatus(), "to", evt.GetToStatus())
+
+	if !e.StatusEmails.Includes(evt.GetFromStatus(), evt.GetToStatus()) || evt.GetUserId() == deletedUserID {
+		return nil
+	}
+	to, err := e.GetUserFn(ctx, evt.GetUserId())
+	if err != nil {
+		return fmt.Errorf("get user %s: %w", evt.GetUserId(), err)
+	}
+	msg, err := e.message(to.Email, emails.OrderStatus, to.Locale, emails.StatusUpdate{
+		Name:    to.Name,
+		OrderID: evt.GetOrderId(),
+		From:    evt.GetFromStatus(),
+		Status:  evt.GetToStatus(),
+		Total:   evt.GetTotal(),
+	})
+	if err != nil {
+		return Permanent(err)
+	}
+	return e.Notifier.Notify(ctx, eventKey(emails.OrderStatus, cmp.Or(env.GetEventId(), dataHash(data))), msg)
+}
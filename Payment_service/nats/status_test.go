@@ -0,0 +1,92 @@
+package nats_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"foodstore/events"
+	natslib "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	"payment/mailer/mailertest"
+	"payment/nats"
+)
+
+func TestParseStatusTransitions(t *testing.T) {
+	transitions, err := nats.ParseStatusTransitions("Delivered, Preparing>Cancelled")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		from, to string
+		want     bool
+	}{
+		{"OutForDelivery", "Delivered", true},
+		{"ReadyForPickup", "Delivered", true},
+		{"Preparing", "Cancelled", true},
+		{"Pending", "Cancelled", false},
+		{"Pending", "Confirmed", false},
+	}
+	for _, c := range cases {
+		if got := transitions.Includes(c.from, c.to); got != c.want {
+			t.Errorf("Includes(%s, %s) = %v, want %v", c.from, c.to, got, c.want)
+		}
+	}
+
+	for _, bad := range []string{"Shipped", "Nowhere>Delivered"} {
+		if _, err := nats.ParseStatusTransitions(bad); err == nil {
+			t.Errorf("%q parsed", bad)
+		}
+	}
+	if none, err := nats.ParseStatusTransitions(""); err != nil || none.Includes("Pending", "Confirmed") {
+		t.Errorf("empty list: %v, %v", none, err)
+	}
+}
+
+func publishStatusChanged(t *testing.T, js jetstream.JetStream, evt *events.OrderStatusChanged) {
+	t.Helper()
+	data, err := events.Marshal(events.TypeOrderStatusChanged, evt, "req-1", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := natslib.NewMsg(events.TypeOrderStatusChanged)
+	msg.Data = data
+	if _, err := js.PublishMsg(context.Background(), msg); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEmailWorker_StatusChangedMailsConfiguredTransitions(t *testing.T) {
+	js := setupJetStream(t)
+	smtp := mailertest.NewServer(t)
+	transitions, err := nats.ParseStatusTransitions("OutForDelivery,Delivered")
+	if err != nil {
+		t.Fatal(err)
+	}
+	worker := newWorker(t, smtp, "")
+	worker.StatusEmails = transitions
+	cfg := nats.OrderStatusChangedConsumer()
+	cfg.Backoff = []time.Duration{10 * time.Millisecond}
+	runConsumer(t, js, cfg, worker.HandleOrderStatusChanged)
+
+	publishStatusChanged(t, js, &events.OrderStatusChanged{
+		OrderId: "order1", UserId: "user1", FromStatus: "Confirmed", ToStatus: "Preparing", Total: 9.5,
+	})
+	publishStatusChanged(t, js, &events.OrderStatusChanged{
+		OrderId: "order1", UserId: "user1", FromStatus: "Preparing", ToStatus: "OutForDelivery", Total: 9.5,
+	})
+
+	msg, ok := smtp.WaitForMessage(5 * time.Second)
+	if !ok {
+		t.Fatal("no status email was sent")
+	}
+	if !strings.Contains(msg.Data, "Subject: Your order is on its way") || !strings.Contains(msg.Data, "order1") {
+		t.Errorf("unexpected status email:\n%s", msg.Data)
+	}
+	waitFor(t, "the acks", func() bool { return pendingAcks(t, js, cfg.Name) == 0 })
+	if msg, ok := smtp.WaitForMessage(100 * time.Millisecond); ok {
+		t.Errorf("Preparing was mailed:\n%s", msg.Data)
+	}
+}
@@ -0,0 +1,66 @@
+// Package notifications records every email the service sends, so that a
+// failed one is retried instead of lost and a redelivered event is not
+// mailed twice.
+package notifications
+
+import (
+	"errors"
+	"time"
+
+	"payment/mailer"
+)
+
+const (
+	// StatusPending notifications are being sent or wait for a retry.
+	StatusPending = "Pending"
+	StatusSent    = "Sent"
+	// StatusFailed notifications ran out of attempts or expired. Only an
+	// admin resends them.
+	StatusFailed = "Failed"
+)
+
+var (
+	ErrNotFound  = errors.New("notification not found")
+	ErrDuplicate = errors.New("notification already recorded")
+	ErrNotFailed = errors.New("only failed notifications can be resent")
+	// ErrLeaseLost means a delivery attempt took longer than its lease and
+	// the notification was claimed again meanwhile.
+	ErrLeaseLost = errors.New("notification was claimed by another attempt")
+)
+
+// Message is a rendered email.
+type Message struct {
+	To          string
+	Template    string
+	Subject     string
+	Text        string
+	HTML        string
+	Attachments []mailer.Attachment
+	// ExpiresAt, if set, is when the email becomes useless, like a password
+	// reset link that no longer works. It is not retried after that.
+	ExpiresAt time.Time
+}
+
+// Notification is a Message and the state of its delivery. Key names the
+// event the email is about; there is one notification per key. The body of a
+// sent notification is dropped, and sent and failed ones are deleted at
+// PurgeAt.
+type Notification struct {
+	ID            string              `bson:"_id,omitempty"`
+	Key           string              `bson:"key"`
+	To            string              `bson:"to"`
+	Template      string              `bson:"template"`
+	Subject       string              `bson:"subject"`
+	Text          string              `bson:"text,omitempty"`
+	HTML          string              `bson:"html,omitempty"`
+	Attachments   []mailer.Attachment `bson:"attachments,omitempty"`
+	ExpiresAt     time.Time           `bson:"expires_at,omitempty"`
+	Status        string              `bson:"status"`
+	Attempts      int                 `bson:"attempts"`
+	LastError     string              `bson:"last_error,omitempty"`
+	NextAttemptAt time.Time           `bson:"next_attempt_at,omitempty"`
+	CreatedAt     time.Time           `bson:"created_at"`
+	UpdatedAt     time.Time           `bson:"updated_at"`
+	SentAt        time.Time           `bson:"sent_at,omitempty"`
+	PurgeAt       time.Time           `bson:"purge_at,omitempty"`
+}
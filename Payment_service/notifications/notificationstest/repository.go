@@ -0,0 +1,146 @@
+// Package notificationstest provides an in-memory notifications.Repository
+// for tests.
+package notificationstest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"payment/notifications"
+)
+
+// Repository keeps notifications in memory and enforces unique keys like the
+// Mongo index does.
+type Repository struct {
+	mu            sync.Mutex
+	notifications []notifications.Notification
+}
+
+func NewRepository() *Repository {
+	return &Repository{}
+}
+
+// All returns every notification, oldest first.
+func (r *Repository) All() []notifications.Notification {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]notifications.Notification(nil), r.notifications...)
+}
+
+func (r *Repository) Create(ctx context.Context, n notifications.Notification) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.notifications {
+		if existing.Key == n.Key {
+			return "", notifications.ErrDuplicate
+		}
+	}
+	n.ID = fmt.Sprintf("n%d", len(r.notifications)+1)
+	r.notifications = append(r.notifications, n)
+	return n.ID, nil
+}
+
+func (r *Repository) GetByID(ctx context.Context, id string) (*notifications.Notification, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := r.find(id)
+	if i < 0 {
+		return nil, notifications.ErrNotFound
+	}
+	n := r.notifications[i]
+	return &n, nil
+}
+
+func (r *Repository) Update(ctx context.Context, claimed, n notifications.Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := r.find(n.ID)
+	if i < 0 {
+		return notifications.ErrNotFound
+	}
+	stored := &r.notifications[i]
+	if stored.Status != claimed.Status || stored.Attempts != claimed.Attempts ||
+		!stored.NextAttemptAt.Equal(claimed.NextAttemptAt) {
+		return notifications.ErrLeaseLost
+	}
+	stored.Status = n.Status
+	stored.Attempts = n.Attempts
+	stored.LastError = n.LastError
+	stored.NextAttemptAt = n.NextAttemptAt
+	stored.UpdatedAt = n.UpdatedAt
+	stored.SentAt = n.SentAt
+	stored.PurgeAt = n.PurgeAt
+	if n.Status == notifications.StatusSent {
+		stored.Text, stored.HTML, stored.Attachments = "", "", nil
+	}
+	return nil
+}
+
+func (r *Repository) ClaimDue(ctx context.Context, now, until time.Time) (*notifications.Notification, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	due := -1
+	for i, n := range r.notifications {
+		if n.Status == notifications.StatusPending && !n.NextAttemptAt.After(now) &&
+			(due < 0 || n.NextAttemptAt.Before(r.notifications[due].NextAttemptAt)) {
+			due = i
+		}
+	}
+	if due < 0 {
+		return nil, nil
+	}
+	r.notifications[due].NextAttemptAt = until
+	n := r.notifications[due]
+	return &n, nil
+}
+
+func (r *Repository) Requeue(ctx context.Context, id string, until time.Time) (*notifications.Notification, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := r.find(id)
+	if i < 0 {
+		return nil, notifications.ErrNotFound
+	}
+	if r.notifications[i].Status != notifications.StatusFailed {
+		return nil, notifications.ErrNotFailed
+	}
+	r.notifications[i].Status = notifications.StatusPending
+	r.notifications[i].Attempts = 0
+	r.notifications[i].NextAttemptAt = until
+	r.notifications[i].PurgeAt = time.Time{}
+	n := r.notifications[i]
+	return &n, nil
+}
+
+func (r *Repository) List(ctx context.Context, status string, limit, offset int64) ([]notifications.Notification, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := []notifications.Notification{}
+	for _, n := range r.notifications {
+		if status == "" || n.Status == status {
+			n.Text, n.HTML, n.Attachments = "", "", nil
+			list = append(list, n)
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+	if offset >= int64(len(list)) {
+		return []notifications.Notification{}, nil
+	}
+	list = list[offset:]
+	if limit > 0 && limit < int64(len(list)) {
+		list = list[:limit]
+	}
+	return list, nil
+}
+
+func (r *Repository) find(id string) int {
+	for i, n := range r.notifications {
+		if n.ID == id {
+			return i
+		}
+	}
+	return -1
+}
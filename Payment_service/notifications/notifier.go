@@ -0,0 +1,196 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"payment/mailer"
+)
+
+// Config tunes delivery attempts.
+type Config struct {
+	// MaxAttempts is how many times an email is tried before it is marked
+	// failed.
+	MaxAttempts int
+	// The delay before a retry starts at BaseDelay and doubles with every
+	// failed attempt, up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// PollInterval is how often Run looks for due retries.
+	PollInterval time.Duration
+	// Lease is how long a notification being sent is hidden from other
+	// retriers. If the service dies mid-send, it is retried after Lease.
+	Lease time.Duration
+	// Retention is how long sent and failed notifications are kept. A key
+	// is only deduplicated while its notification is kept.
+	Retention time.Duration
+}
+
+// DefaultConfig tries an email 8 times over about an hour.
+func DefaultConfig() Config {
+	return Config{
+		MaxAttempts:  8,
+		BaseDelay:    30 * time.Second,
+		MaxDelay:     time.Hour,
+		PollInterval: 10 * time.Second,
+		Lease:        time.Minute,
+		Retention:    30 * 24 * time.Hour,
+	}
+}
+
+// backoff returns the delay after the given number of failed attempts.
+func (c Config) backoff(attempts int) time.Duration {
+	d := c.BaseDelay
+	for i := 1; i < attempts && d < c.MaxDelay; i++ {
+		d *= 2
+	}
+	return min(d, c.MaxDelay)
+}
+
+// Notifier sends emails through a Mailer and records each one in a
+// Repository.
+type Notifier struct {
+	repo   Repository
+	mailer *mailer.Mailer
+	cfg    Config
+}
+
+func NewNotifier(repo Repository, m *mailer.Mailer, cfg Config) *Notifier {
+	return &Notifier{repo: repo, mailer: m, cfg: cfg}
+}
+
+// Notify records msg under key and tries to send it. A failed send is
+// retried by Run, so Notify only fails if the notification could not be
+// recorded. If key was notified before, msg is dropped.
+func (n *Notifier) Notify(ctx context.Context, key string, msg Message) error {
+	now := time.Now()
+	notification := Notification{
+		Key:           key,
+		To:            msg.To,
+		Template:      msg.Template,
+		Subject:       msg.Subject,
+		Text:          msg.Text,
+		HTML:          msg.HTML,
+		Attachments:   msg.Attachments,
+		ExpiresAt:     msg.ExpiresAt,
+		Status:        StatusPending,
+		NextAttemptAt: now.Add(n.cfg.Lease),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	id, err := n.repo.Create(ctx, notification)
+	if errors.Is(err, ErrDuplicate) {
+		slog.InfoContext(ctx, "notification already sent or queued", "key", key, "template", msg.Template)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("record notification %s: %w", key, err)
+	}
+	notification.ID = id
+	n.deliver(ctx, &notification)
+	return nil
+}
+
+// deliver makes one attempt to send notification, which the caller has just
+// claimed, and stores the outcome.
+func (n *Notifier) deliver(ctx context.Context, notification *Notification) {
+	claimed := *notification
+	now := time.Now()
+	log := slog.With("notification_id", notification.ID, "key", notification.Key,
+		"template", notification.Template, "to", notification.To)
+
+	if !notification.ExpiresAt.IsZero() && now.After(notification.ExpiresAt) {
+		notification.Status = StatusFailed
+		notification.LastError = "expired before it could be sent"
+		notification.NextAttemptAt = time.Time{}
+		log.WarnContext(ctx, "notification expired", "attempts", notification.Attempts)
+	} else {
+		notification.Attempts++
+		err := n.mailer.SendMultipart(notification.To, notification.Subject, notification.Text, notification.HTML,
+			notification.Attachments...)
+		switch {
+		case err == nil:
+			notification.Status = StatusSent
+			notification.SentAt = now
+			notification.NextAttemptAt = time.Time{}
+			notification.Text, notification.HTML, notification.Attachments = "", "", nil
+			log.InfoContext(ctx, "notification sent", "attempt", notification.Attempts)
+		case notification.Attempts >= n.cfg.MaxAttempts:
+			notification.Status = StatusFailed
+			notification.LastError = err.Error()
+			notification.NextAttemptAt = time.Time{}
+			log.ErrorContext(ctx, "notification failed, giving up", "attempt", notification.Attempts, "err", err)
+		default:
+			notification.LastError = err.Error()
+			notification.NextAttemptAt = now.Add(n.cfg.backoff(notification.Attempts))
+			log.WarnContext(ctx, "notification failed, will retry", "attempt", notification.Attempts,
+				"next_attempt_at", notification.NextAttemptAt, "err", err)
+		}
+	}
+	notification.UpdatedAt = now
+	if notification.Status != StatusPending {
+		notification.PurgeAt = now.Add(n.cfg.Retention)
+	}
+
+	err := n.repo.Update(ctx, claimed, *notification)
+	switch {
+	case errors.Is(err, ErrLeaseLost):
+		// The attempt that claimed it since decides; writing this outcome
+		// over a later success would send the email again.
+		log.WarnContext(ctx, "notification outcome discarded, the lease ran out", "status", notification.Status)
+	case err != nil:
+		// The lease runs out and the retrier tries again, which may send
+		// the email a second time.
+		log.ErrorContext(ctx, "failed to record notification outcome", "status", notification.Status, "err", err)
+	}
+}
+
+// RetryDue sends every notification whose retry is due and returns how many
+// it tried.
+func (n *Notifier) RetryDue(ctx context.Context) (int, error) {
+	tried := 0
+	for {
+		now := time.Now()
+		notification, err := n.repo.ClaimDue(ctx, now, now.Add(n.cfg.Lease))
+		if err != nil || notification == nil {
+			return tried, err
+		}
+		n.deliver(ctx, notification)
+		tried++
+	}
+}
+
+// Run retries due notifications every PollInterval until ctx is done.
+func (n *Notifier) Run(ctx context.Context) error {
+	ticker := time.NewTicker(n.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if _, err := n.RetryDue(ctx); err != nil {
+				slog.ErrorContext(ctx, "failed to retry notifications", "err", err)
+			}
+		}
+	}
+}
+
+// List returns notifications with status, or all of them, newest first.
+func (n *Notifier) List(ctx context.Context, status string, limit, offset int64) ([]Notification, error) {
+	return n.repo.List(ctx, status, limit, offset)
+}
+
+// Resend gives a failed notification a fresh set of attempts, makes the
+// first one right away and returns the notification as it stands after it.
+func (n *Notifier) Resend(ctx context.Context, id string) (*Notification, error) {
+	notification, err := n.repo.Requeue(ctx, id, time.Now().Add(n.cfg.Lease))
+	if err != nil {
+		return nil, err
+	}
+	n.deliver(ctx, notification)
+	return notification, nil
+}
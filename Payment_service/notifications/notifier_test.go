@@ -0,0 +1,227 @@
+package notifications_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"payment/mailer"
+	"payment/notifications"
+	"payment/notifications/notificationstest"
+)
+
+// flakyTransport fails until it is fixed, then keeps mail in memory. sending,
+// if set, runs at the start of every send.
+type flakyTransport struct {
+	*mailer.MemoryTransport
+	mu      sync.Mutex
+	fixed   bool
+	sending func()
+}
+
+func (t *flakyTransport) Send(from string, to []string, msg io.WriterTo) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.sending != nil {
+		t.sending()
+	}
+	if !t.fixed {
+		return errors.New("smtp: connection refused")
+	}
+	return t.MemoryTransport.Send(from, to, msg)
+}
+
+func (t *flakyTransport) fix() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.fixed = true
+}
+
+func newNotifier(cfg notifications.Config) (*notifications.Notifier, *notificationstest.Repository, *flakyTransport) {
+	repo := notificationstest.NewRepository()
+	transport := &flakyTransport{MemoryTransport: mailer.NewMemoryTransport(0)}
+	return notifications.NewNotifier(repo, mailer.NewMailer(transport, "noreply@quickbite.test"), cfg), repo, transport
+}
+
+var receipt = notifications.Message{
+	To:       "john@example.com",
+	Template: "order_receipt",
+	Subject:  "Your receipt",
+	Text:     "Total: $5.00",
+	HTML:     "<p>Total: $5.00</p>",
+}
+
+func TestNotifier_NotifyDeduplicatesByKey(t *testing.T) {
+	notifier, repo, transport := newNotifier(notifications.DefaultConfig())
+	transport.fix()
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if err := notifier.Notify(ctx, "evt-1", receipt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := len(transport.Messages()); n != 1 {
+		t.Errorf("sent %d emails for one event, want 1", n)
+	}
+	all := repo.All()
+	if len(all) != 1 || all[0].Status != notifications.StatusSent || all[0].Attempts != 1 || all[0].SentAt.IsZero() {
+		t.Errorf("notifications = %+v", all)
+	}
+}
+
+func TestNotifier_RetriesWithBackoff(t *testing.T) {
+	const base = 20 * time.Millisecond
+	notifier, repo, transport := newNotifier(notifications.Config{
+		MaxAttempts: 10,
+		BaseDelay:   base,
+		MaxDelay:    3 * base,
+		Lease:       time.Minute,
+	})
+	ctx := context.Background()
+
+	if err := notifier.Notify(ctx, "evt-1", receipt); err != nil {
+		t.Fatalf("a failed send was not queued: %v", err)
+	}
+	for _, want := range []time.Duration{base, 2 * base, 3 * base} {
+		n := repo.All()[0]
+		if n.Status != notifications.StatusPending || !strings.Contains(n.LastError, "connection refused") {
+			t.Fatalf("notification = %+v", n)
+		}
+		if delay := n.NextAttemptAt.Sub(n.UpdatedAt); delay != want {
+			t.Errorf("after attempt %d the retry is in %v, want %v", n.Attempts, delay, want)
+		}
+
+		if tried, err := notifier.RetryDue(ctx); err != nil || tried != 0 {
+			t.Fatalf("retried early: %d, %v", tried, err)
+		}
+		time.Sleep(want)
+		if tried, err := notifier.RetryDue(ctx); err != nil || tried != 1 {
+			t.Fatalf("RetryDue = %d, %v, want 1 due notification", tried, err)
+		}
+	}
+
+	transport.fix()
+	time.Sleep(3 * base)
+	if _, err := notifier.RetryDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n := repo.All()[0]; n.Status != notifications.StatusSent || n.Attempts != 5 {
+		t.Errorf("notification = %+v, want sent on the 5th attempt", n)
+	}
+	if len(transport.Messages()) != 1 {
+		t.Errorf("messages = %d, want 1", len(transport.Messages()))
+	}
+}
+
+func TestNotifier_ResendFailed(t *testing.T) {
+	notifier, repo, transport := newNotifier(notifications.Config{MaxAttempts: 2, Lease: time.Minute})
+	ctx := context.Background()
+
+	if err := notifier.Notify(ctx, "evt-1", receipt); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := notifier.RetryDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+	failed, err := notifier.List(ctx, notifications.StatusFailed, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failed) != 1 || failed[0].Attempts != 2 || failed[0].Text != "" {
+		t.Fatalf("failed = %+v", failed)
+	}
+
+	transport.fix()
+	n, err := notifier.Resend(ctx, failed[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Status != notifications.StatusSent || n.Attempts != 1 {
+		t.Errorf("resent notification = %+v", n)
+	}
+	if len(transport.Messages()) != 1 {
+		t.Errorf("messages = %d, want 1", len(transport.Messages()))
+	}
+
+	if _, err := notifier.Resend(ctx, n.ID); !errors.Is(err, notifications.ErrNotFailed) {
+		t.Errorf("resending a sent notification: err = %v", err)
+	}
+	if _, err := notifier.Resend(ctx, "missing"); !errors.Is(err, notifications.ErrNotFound) {
+		t.Errorf("resending a missing notification: err = %v", err)
+	}
+	if stored := repo.All()[0]; stored.Status != notifications.StatusSent {
+		t.Errorf("stored = %+v", stored)
+	}
+}
+
+func TestNotifier_ExpiredIsNotRetried(t *testing.T) {
+	notifier, repo, _ := newNotifier(notifications.Config{MaxAttempts: 5, Lease: time.Minute})
+	ctx := context.Background()
+
+	msg := receipt
+	msg.ExpiresAt = time.Now().Add(30 * time.Millisecond)
+	if err := notifier.Notify(ctx, "evt-1", msg); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(40 * time.Millisecond)
+	if tried, err := notifier.RetryDue(ctx); err != nil || tried != 1 {
+		t.Fatalf("RetryDue = %d, %v", tried, err)
+	}
+	if n := repo.All()[0]; n.Status != notifications.StatusFailed || n.Attempts != 1 {
+		t.Errorf("notification = %+v, want failed without another attempt", n)
+	}
+}
+
+func TestNotifier_SentDropsBodyAndIsPurged(t *testing.T) {
+	cfg := notifications.DefaultConfig()
+	notifier, repo, transport := newNotifier(cfg)
+	transport.fix()
+
+	before := time.Now()
+	if err := notifier.Notify(context.Background(), "evt-1", receipt); err != nil {
+		t.Fatal(err)
+	}
+	n := repo.All()[0]
+	if n.Status != notifications.StatusSent || n.Text != "" || n.HTML != "" || n.Attachments != nil {
+		t.Errorf("sent notification kept its body: %+v", n)
+	}
+	if n.PurgeAt.Before(before.Add(cfg.Retention)) || n.PurgeAt.After(time.Now().Add(cfg.Retention)) {
+		t.Errorf("purge at = %v, want %v after sending", n.PurgeAt, cfg.Retention)
+	}
+}
+
+func TestNotifier_SlowAttemptDoesNotOverwriteLaterOne(t *testing.T) {
+	notifier, repo, transport := newNotifier(notifications.Config{MaxAttempts: 5, BaseDelay: time.Minute, MaxDelay: time.Minute, Lease: time.Minute})
+	ctx := context.Background()
+
+	// While the first attempt hangs, its lease runs out and another
+	// instance claims the notification and sends it.
+	transport.sending = func() {
+		transport.sending = nil
+		later := time.Now().Add(2 * time.Minute)
+		claimed, err := repo.ClaimDue(ctx, later, later.Add(time.Minute))
+		if err != nil || claimed == nil {
+			t.Fatalf("ClaimDue = %v, %v", claimed, err)
+		}
+		sent := *claimed
+		sent.Status, sent.Attempts, sent.NextAttemptAt, sent.SentAt = notifications.StatusSent, 1, time.Time{}, later
+		if err := repo.Update(ctx, *claimed, sent); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := notifier.Notify(ctx, "evt-1", receipt); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := repo.All()[0]; n.Status != notifications.StatusSent {
+		t.Errorf("notification = %+v, want the later attempt's outcome", n)
+	}
+	if tried, err := notifier.RetryDue(ctx); err != nil || tried != 0 {
+		t.Errorf("RetryDue = %d, %v, want nothing to send again", tried, err)
+	}
+}
@@ -0,0 +1,203 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Repository interface {
+	// Create stores a new notification and returns its id, or ErrDuplicate
+	// if there already is one with the same key.
+	Create(ctx context.Context, n Notification) (string, error)
+	GetByID(ctx context.Context, id string) (*Notification, error)
+	// Update stores the outcome n of a delivery attempt on claimed, the
+	// notification as the attempt claimed it. It fails with ErrLeaseLost if
+	// the notification was claimed again or changed since.
+	Update(ctx context.Context, claimed, n Notification) error
+	// ClaimDue returns a pending notification whose next attempt is due at
+	// now and postpones it to until, so no one else picks it up meanwhile.
+	// It returns nil when nothing is due.
+	ClaimDue(ctx context.Context, now, until time.Time) (*Notification, error)
+	// Requeue makes a failed notification pending again with no attempts,
+	// due at until, and returns it. It fails with ErrNotFailed if the
+	// notification is not failed.
+	Requeue(ctx context.Context, id string, until time.Time) (*Notification, error)
+	// List returns notifications with status, or all of them if status is
+	// empty, newest first and without their bodies.
+	List(ctx context.Context, status string, limit, offset int64) ([]Notification, error)
+}
+
+type MongoRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewMongoRepository(db *mongo.Database) *MongoRepository {
+	return &MongoRepository{Collection: db.Collection("notifications")}
+}
+
+// EnsureIndexes creates the indexes the repository relies on.
+func (r *MongoRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.Collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "key", Value: 1}},
+			Options: options.Index().SetName("key_unique").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}},
+			Options: options.Index().SetName("status_next_attempt_at"),
+		},
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("status_created_at"),
+		},
+		{
+			// Only sent and failed notifications have purge_at.
+			Keys:    bson.D{{Key: "purge_at", Value: 1}},
+			Options: options.Index().SetName("purge_at_ttl").SetExpireAfterSeconds(0),
+		},
+	})
+	return err
+}
+
+func (r *MongoRepository) Create(ctx context.Context, n Notification) (string, error) {
+	res, err := r.Collection.InsertOne(ctx, n)
+	if mongo.IsDuplicateKeyError(err) {
+		return "", ErrDuplicate
+	}
+	if err != nil {
+		return "", err
+	}
+	return res.InsertedID.(primitive.ObjectID).Hex(), nil
+}
+
+func (r *MongoRepository) GetByID(ctx context.Context, id string) (*Notification, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	var n Notification
+	err = r.Collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&n)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+func (r *MongoRepository) Update(ctx context.Context, claimed, n Notification) error {
+	objID, err := primitive.ObjectIDFromHex(n.ID)
+	if err != nil {
+		return ErrNotFound
+	}
+	set := bson.M{
+		"status":     n.Status,
+		"attempts":   n.Attempts,
+		"last_error": n.LastError,
+		"updated_at": n.UpdatedAt,
+	}
+	unset := bson.M{}
+	for field, t := range map[string]time.Time{"next_attempt_at": n.NextAttemptAt, "sent_at": n.SentAt, "purge_at": n.PurgeAt} {
+		if t.IsZero() {
+			unset[field] = ""
+		} else {
+			set[field] = t
+		}
+	}
+	if n.Status == StatusSent {
+		unset["text"], unset["html"], unset["attachments"] = "", "", ""
+	}
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	// Mongo keeps milliseconds, so the claimed lease is compared at that
+	// precision.
+	res, err := r.Collection.UpdateOne(ctx, bson.M{
+		"_id":             objID,
+		"status":          claimed.Status,
+		"attempts":        claimed.Attempts,
+		"next_attempt_at": claimed.NextAttemptAt.Truncate(time.Millisecond),
+	}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		if _, err := r.GetByID(ctx, n.ID); err != nil {
+			return err
+		}
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+func (r *MongoRepository) ClaimDue(ctx context.Context, now, until time.Time) (*Notification, error) {
+	var n Notification
+	err := r.Collection.FindOneAndUpdate(ctx,
+		bson.M{"status": StatusPending, "next_attempt_at": bson.M{"$lte": now}},
+		bson.M{"$set": bson.M{"next_attempt_at": until}},
+		options.FindOneAndUpdate().
+			SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).
+			SetReturnDocument(options.After),
+	).Decode(&n)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+func (r *MongoRepository) Requeue(ctx context.Context, id string, until time.Time) (*Notification, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	var n Notification
+	err = r.Collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": objID, "status": StatusFailed},
+		bson.M{
+			"$set":   bson.M{"status": StatusPending, "attempts": 0, "next_attempt_at": until},
+			"$unset": bson.M{"purge_at": ""},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&n)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		if _, err := r.GetByID(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, ErrNotFailed
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+func (r *MongoRepository) List(ctx context.Context, status string, limit, offset int64) ([]Notification, error) {
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+	cursor, err := r.Collection.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(offset).
+		SetLimit(limit).
+		SetProjection(bson.M{"text": 0, "html": 0, "attachments": 0}))
+	if err != nil {
+		return nil, err
+	}
+	notifications := []Notification{}
+	if err := cursor.All(ctx, &notifications); err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
@@ -0,0 +1,107 @@
+package payments
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// IdempotencyLease is how long Authorize holds a key before a retry may
+	// take it over, in case the request holding it died.
+	IdempotencyLease = time.Minute
+	// IdempotencyTTL is how long a finished key keeps answering with its
+	// payment.
+	IdempotencyTTL = 24 * time.Hour
+)
+
+// IdempotencyRecord is a document of the idempotency_keys collection, whose
+// _id is the key scoped to its user. Result is the id of the payment and is
+// set once Authorize finished; until then ReservedUntil bounds the claim.
+type IdempotencyRecord struct {
+	RequestHash   string    `bson:"request_hash"`
+	Result        string    `bson:"result,omitempty"`
+	ReservedUntil time.Time `bson:"reserved_until,omitempty"`
+}
+
+// IdempotencyStore is what Authorize needs from the key collection.
+type IdempotencyStore interface {
+	// Reserve inserts key for hash, held for IdempotencyLease. If key exists
+	// its record is returned with false, unless its lease ran out without a
+	// result: then the key is taken over for hash.
+	Reserve(ctx context.Context, key, hash string) (*IdempotencyRecord, bool, error)
+	// Complete stores the payment id of key and keeps it for IdempotencyTTL.
+	Complete(ctx context.Context, key, result string) error
+	// Release deletes key after Authorize failed.
+	Release(ctx context.Context, key string) error
+}
+
+// MongoIdempotencyStore keeps keys in a collection. A TTL index on
+// created_at, which Complete moves to the completion time, empties it.
+type MongoIdempotencyStore struct {
+	Collection *mongo.Collection
+}
+
+func NewMongoIdempotencyStore(db *mongo.Database) *MongoIdempotencyStore {
+	return &MongoIdempotencyStore{Collection: db.Collection("idempotency_keys")}
+}
+
+func (s *MongoIdempotencyStore) EnsureIndexes(ctx context.Context) error {
+	_, err := s.Collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "created_at", Value: 1}},
+		Options: options.Index().SetName("created_at_ttl").SetExpireAfterSeconds(int32(IdempotencyTTL.Seconds())),
+	})
+	return err
+}
+
+func (s *MongoIdempotencyStore) Reserve(ctx context.Context, key, hash string) (*IdempotencyRecord, bool, error) {
+	now := time.Now()
+	_, err := s.Collection.InsertOne(ctx, bson.M{
+		"_id":            key,
+		"request_hash":   hash,
+		"reserved_until": now.Add(IdempotencyLease),
+		"created_at":     now,
+	})
+	if err == nil {
+		return nil, true, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return nil, false, err
+	}
+
+	var record IdempotencyRecord
+	if err := s.Collection.FindOne(ctx, bson.M{"_id": key}).Decode(&record); err != nil {
+		return nil, false, err
+	}
+	if record.Result != "" || now.Before(record.ReservedUntil) {
+		return &record, false, nil
+	}
+	res, err := s.Collection.UpdateOne(ctx,
+		bson.M{"_id": key, "result": bson.M{"$exists": false}, "reserved_until": record.ReservedUntil},
+		bson.M{"$set": bson.M{"request_hash": hash, "reserved_until": now.Add(IdempotencyLease), "created_at": now}},
+	)
+	if err != nil {
+		return nil, false, err
+	}
+	if res.MatchedCount == 0 {
+		// Another retry took it over first.
+		return s.Reserve(ctx, key, hash)
+	}
+	return nil, true, nil
+}
+
+func (s *MongoIdempotencyStore) Complete(ctx context.Context, key, result string) error {
+	_, err := s.Collection.UpdateOne(ctx, bson.M{"_id": key}, bson.M{
+		"$set":   bson.M{"result": result, "created_at": time.Now()},
+		"$unset": bson.M{"reserved_until": ""},
+	})
+	return err
+}
+
+func (s *MongoIdempotencyStore) Release(ctx context.Context, key string) error {
+	_, err := s.Collection.DeleteOne(ctx, bson.M{"_id": key})
+	return err
+}
@@ -0,0 +1,50 @@
+// Package payments keeps payment records and drives them through the
+// authorize, capture and refund steps of a PaymentProvider.
+package payments
+
+import "time"
+
+const (
+	StatusPending           = "Pending"
+	StatusFailed            = "Failed"
+	StatusAuthorized        = "Authorized"
+	StatusCapturing         = "Capturing"
+	StatusDeclined          = "Declined"
+	StatusCaptured          = "Captured"
+	StatusPartiallyRefunded = "PartiallyRefunded"
+	StatusRefunding         = "Refunding"
+	StatusRefunded          = "Refunded"
+)
+
+// DefaultCurrency is the currency order totals are priced in.
+const DefaultCurrency = "USD"
+
+// Payment is one attempt to pay for an order. Amounts are in cents.
+type Payment struct {
+	ID             string `bson:"_id,omitempty"`
+	OrderID        string `bson:"order_id"`
+	UserID         string `bson:"user_id"`
+	Amount         int64  `bson:"amount"`
+	Currency       string `bson:"currency"`
+	Status         string `bson:"status"`
+	Provider       string `bson:"provider"`
+	ProviderRef    string `bson:"provider_ref,omitempty"`
+	DeclineReason  string `bson:"decline_reason,omitempty"`
+	RefundedAmount int64  `bson:"refunded_amount"`
+	// RefundingAmount and RefundReason describe the refund a Refunding
+	// payment is waiting on the provider for.
+	RefundingAmount int64  `bson:"refunding_amount,omitempty"`
+	RefundReason    string `bson:"refund_reason,omitempty"`
+	// AnnouncePending is set from the capture until payment.captured is
+	// stored in JetStream; Reconcile publishes what is left over.
+	AnnouncePending bool      `bson:"announce_pending,omitempty"`
+	CreatedAt       time.Time `bson:"created_at"`
+	UpdatedAt       time.Time `bson:"updated_at"`
+}
+
+// Active reports whether the payment is in flight or went through. An order
+// with an active payment cannot be paid a second time; declined and failed
+// attempts may be retried.
+func (p Payment) Active() bool {
+	return p.Status != StatusDeclined && p.Status != StatusFailed
+}
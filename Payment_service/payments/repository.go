@@ -0,0 +1,172 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	// ErrOrderAlreadyPaid is returned when an order already has a payment
+	// that was not declined.
+	ErrOrderAlreadyPaid = errors.New("order already has a payment")
+	// ErrStatusChanged means another request moved the payment first.
+	ErrStatusChanged = errors.New("payment status was changed concurrently")
+)
+
+type Repository interface {
+	Create(ctx context.Context, p Payment) (string, error)
+	GetByID(ctx context.Context, id string) (*Payment, error)
+	// Update stores the state of p if the stored payment still has the status
+	// and refunded amount of prev.
+	Update(ctx context.Context, p Payment, prev Payment) error
+	// ListStale returns up to limit payments in one of statuses that were
+	// last updated before before.
+	ListStale(ctx context.Context, statuses []string, before time.Time, limit int64) ([]Payment, error)
+	// ListUnannounced returns up to limit captured payments whose
+	// payment.captured event was not published yet.
+	ListUnannounced(ctx context.Context, limit int64) ([]Payment, error)
+}
+
+// paymentDoc adds active_order_id, which only active payments carry. A sparse
+// unique index on it lets declined attempts pile up while allowing a single
+// live payment per order.
+type paymentDoc struct {
+	Payment       `bson:",inline"`
+	ActiveOrderID string `bson:"active_order_id,omitempty"`
+}
+
+type MongoRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewMongoRepository(db *mongo.Database) *MongoRepository {
+	return &MongoRepository{Collection: db.Collection("payments")}
+}
+
+// EnsureIndexes creates the indexes the repository relies on.
+func (r *MongoRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.Collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "active_order_id", Value: 1}},
+			Options: options.Index().SetName("active_order_unique").SetUnique(true).SetSparse(true),
+		},
+		{
+			Keys:    bson.D{{Key: "order_id", Value: 1}},
+			Options: options.Index().SetName("order_id"),
+		},
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "updated_at", Value: 1}},
+			Options: options.Index().SetName("status_updated_at"),
+		},
+		{
+			Keys: bson.D{{Key: "announce_pending", Value: 1}},
+			Options: options.Index().SetName("announce_pending").
+				SetPartialFilterExpression(bson.M{"announce_pending": true}),
+		},
+	})
+	return err
+}
+
+func (r *MongoRepository) Create(ctx context.Context, p Payment) (string, error) {
+	doc := paymentDoc{Payment: p}
+	if p.Active() {
+		doc.ActiveOrderID = p.OrderID
+	}
+	res, err := r.Collection.InsertOne(ctx, doc)
+	if mongo.IsDuplicateKeyError(err) {
+		return "", ErrOrderAlreadyPaid
+	}
+	if err != nil {
+		return "", err
+	}
+	return res.InsertedID.(primitive.ObjectID).Hex(), nil
+}
+
+func (r *MongoRepository) GetByID(ctx context.Context, id string) (*Payment, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	var p Payment
+	if err := r.Collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *MongoRepository) Update(ctx context.Context, p Payment, prev Payment) error {
+	objID, err := primitive.ObjectIDFromHex(p.ID)
+	if err != nil {
+		return mongo.ErrNoDocuments
+	}
+	update := bson.M{"$set": bson.M{
+		"status":          p.Status,
+		"provider_ref":    p.ProviderRef,
+		"decline_reason":  p.DeclineReason,
+		"refunded_amount": p.RefundedAmount,
+		"updated_at":      p.UpdatedAt,
+	}}
+	unset := bson.M{}
+	if !p.Active() {
+		unset["active_order_id"] = ""
+	}
+	if p.AnnouncePending {
+		update["$set"].(bson.M)["announce_pending"] = true
+	} else {
+		unset["announce_pending"] = ""
+	}
+	if p.Status == StatusRefunding {
+		update["$set"].(bson.M)["refunding_amount"] = p.RefundingAmount
+		update["$set"].(bson.M)["refund_reason"] = p.RefundReason
+	} else {
+		unset["refunding_amount"] = ""
+		unset["refund_reason"] = ""
+	}
+	update["$unset"] = unset
+
+	res, err := r.Collection.UpdateOne(ctx, bson.M{
+		"_id":             objID,
+		"status":          prev.Status,
+		"refunded_amount": prev.RefundedAmount,
+	}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrStatusChanged
+	}
+	return nil
+}
+
+func (r *MongoRepository) ListUnannounced(ctx context.Context, limit int64) ([]Payment, error) {
+	cursor, err := r.Collection.Find(ctx, bson.M{"announce_pending": true},
+		options.Find().SetSort(bson.D{{Key: "updated_at", Value: 1}}).SetLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	var list []Payment
+	if err := cursor.All(ctx, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *MongoRepository) ListStale(ctx context.Context, statuses []string, before time.Time, limit int64) ([]Payment, error) {
+	filter := bson.M{"status": bson.M{"$in": statuses}, "updated_at": bson.M{"$lt": before}}
+	cursor, err := r.Collection.Find(ctx, filter,
+		options.Find().SetSort(bson.D{{Key: "updated_at", Value: 1}}).SetLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	var list []Payment
+	if err := cursor.All(ctx, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
@@ -0,0 +1,412 @@
+package payments
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"payment/identity"
+	orderpb "payment/proto/order"
+	"payment/provider"
+)
+
+var (
+	ErrOrderNotFound     = errors.New("order not found")
+	ErrOrderNotPayable   = errors.New("order cannot be paid")
+	ErrForbidden         = errors.New("payment belongs to another user")
+	ErrInvalidTransition = errors.New("invalid payment status transition")
+	ErrInvalidAmount     = errors.New("invalid refund amount")
+
+	ErrIdempotencyConflict    = errors.New("idempotency key was already used for a different request")
+	ErrIdempotencyInProgress  = errors.New("a request with this idempotency key is still in progress")
+	ErrIdempotencyUnavailable = errors.New("idempotency keys cannot be checked right now")
+)
+
+// orderPendingStatus is the only order status that accepts a payment.
+const orderPendingStatus = "Pending"
+
+// Events is told about money that actually moved.
+type Events interface {
+	PaymentCaptured(ctx context.Context, p Payment) error
+	PaymentRefunded(ctx context.Context, p Payment, amount int64, reason string) error
+}
+
+type Service struct {
+	repo        Repository
+	provider    provider.PaymentProvider
+	orders      orderpb.OrderServiceClient
+	events      Events
+	idempotency IdempotencyStore
+}
+
+// NewService returns a payment service. idempotency may be nil, in which case
+// idempotency keys are ignored.
+func NewService(repo Repository, p provider.PaymentProvider, orders orderpb.OrderServiceClient, events Events, idempotency IdempotencyStore) *Service {
+	return &Service{repo: repo, provider: p, orders: orders, events: events, idempotency: idempotency}
+}
+
+// AuthorizeParams describes a payment for an order.
+type AuthorizeParams struct {
+	OrderID       string
+	PaymentMethod string
+	// Capture takes the money right after a successful authorization.
+	Capture        bool
+	IdempotencyKey string
+}
+
+func (p AuthorizeParams) hash() string {
+	sum := sha256.Sum256([]byte(p.OrderID + "\x00" + p.PaymentMethod + "\x00" + strconv.FormatBool(p.Capture)))
+	return hex.EncodeToString(sum[:])
+}
+
+// Authorize reserves the total of a pending order of the caller. A declined
+// payment method is not an error: the returned payment is Declined and
+// carries the reason.
+//
+// An idempotency key makes a repeated request return the payment of the
+// first one, with replayed set; the key is scoped to the caller and bound to
+// the order, method and capture flag, so other parameters fail with
+// ErrIdempotencyConflict. If the key collection cannot be read, Authorize
+// fails with ErrIdempotencyUnavailable instead of guessing.
+func (s *Service) Authorize(ctx context.Context, caller identity.Caller, params AuthorizeParams) (p *Payment, replayed bool, err error) {
+	if params.IdempotencyKey == "" || s.idempotency == nil {
+		p, err = s.authorize(ctx, caller, params)
+		return p, false, err
+	}
+
+	key := caller.UserID + ":" + params.IdempotencyKey
+	existing, reserved, err := s.idempotency.Reserve(ctx, key, params.hash())
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to reserve idempotency key", "err", err)
+		return nil, false, ErrIdempotencyUnavailable
+	}
+	if !reserved {
+		switch {
+		case existing.RequestHash != params.hash():
+			return nil, false, ErrIdempotencyConflict
+		case existing.Result == "":
+			return nil, false, ErrIdempotencyInProgress
+		}
+		p, err = s.repo.GetByID(ctx, existing.Result)
+		return p, true, err
+	}
+
+	p, err = s.authorize(ctx, caller, params)
+	if err != nil {
+		if releaseErr := s.idempotency.Release(ctx, key); releaseErr != nil {
+			slog.WarnContext(ctx, "failed to release idempotency key", "err", releaseErr)
+		}
+		return nil, false, err
+	}
+	if err := s.idempotency.Complete(ctx, key, p.ID); err != nil {
+		slog.WarnContext(ctx, "failed to store idempotency result", "payment_id", p.ID, "err", err)
+	}
+	return p, false, nil
+}
+
+func (s *Service) authorize(ctx context.Context, caller identity.Caller, params AuthorizeParams) (*Payment, error) {
+	res, err := s.orders.GetOrder(identity.OutgoingContext(ctx, caller), &orderpb.GetOrderRequest{Id: params.OrderID})
+	if status.Code(err) == codes.NotFound {
+		return nil, ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get order: %w", err)
+	}
+	order := res.Order
+	if order.UserId != caller.UserID && !caller.IsAdmin() {
+		return nil, ErrForbidden
+	}
+	if order.Status != orderPendingStatus {
+		return nil, fmt.Errorf("%w: order is %s", ErrOrderNotPayable, order.Status)
+	}
+	amount := toCents(order.TotalPrice)
+	if amount <= 0 {
+		return nil, fmt.Errorf("%w: nothing to pay", ErrOrderNotPayable)
+	}
+
+	now := time.Now()
+	p := Payment{
+		OrderID:   order.Id,
+		UserID:    order.UserId,
+		Amount:    amount,
+		Currency:  DefaultCurrency,
+		Status:    StatusPending,
+		Provider:  s.provider.Name(),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	p.ID, err = s.repo.Create(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	prev := p
+	ref, authErr := s.provider.Authorize(ctx, provider.AuthorizeRequest{
+		PaymentID:     p.ID,
+		Amount:        p.Amount,
+		Currency:      p.Currency,
+		PaymentMethod: params.PaymentMethod,
+	})
+	var decline *provider.DeclineError
+	switch {
+	case errors.As(authErr, &decline):
+		p.Status = StatusDeclined
+		p.DeclineReason = decline.Code
+	case authErr != nil:
+		p.Status = StatusFailed
+	default:
+		p.Status = StatusAuthorized
+		p.ProviderRef = ref
+	}
+	p.UpdatedAt = time.Now()
+	if err := s.repo.Update(ctx, p, prev); err != nil {
+		return nil, err
+	}
+	if p.Status == StatusFailed {
+		return nil, fmt.Errorf("authorize payment: %w", authErr)
+	}
+	slog.InfoContext(ctx, "payment authorized", "payment_id", p.ID, "order_id", p.OrderID, "status", p.Status)
+
+	if params.Capture && p.Status == StatusAuthorized {
+		return s.capture(ctx, p)
+	}
+	return &p, nil
+}
+
+// Capture takes the money of an authorized payment and announces it, which
+// is what confirms the order. A payment left Capturing by an interrupted
+// capture is finished.
+func (s *Service) Capture(ctx context.Context, caller identity.Caller, id string) (*Payment, error) {
+	p, err := s.Get(ctx, caller, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.capture(ctx, *p)
+}
+
+// capture moves p to Capturing before asking the provider, so that money
+// taken is never forgotten: until p is Captured, Capture and Reconcile
+// repeat the provider call, which the provider treats as the same capture.
+func (s *Service) capture(ctx context.Context, p Payment) (*Payment, error) {
+	switch p.Status {
+	case StatusAuthorized:
+		prev := p
+		p.Status = StatusCapturing
+		p.UpdatedAt = time.Now()
+		if err := s.repo.Update(ctx, p, prev); err != nil {
+			return nil, err
+		}
+	case StatusCapturing:
+		slog.InfoContext(ctx, "resuming interrupted capture", "payment_id", p.ID)
+	default:
+		return nil, fmt.Errorf("%w: cannot capture a %s payment", ErrInvalidTransition, p.Status)
+	}
+	if err := s.provider.Capture(ctx, p.ProviderRef, p.Amount); err != nil {
+		return nil, fmt.Errorf("capture payment: %w", err)
+	}
+
+	prev := p
+	p.Status = StatusCaptured
+	p.AnnouncePending = true
+	p.UpdatedAt = time.Now()
+	if err := s.repo.Update(ctx, p, prev); errors.Is(err, ErrStatusChanged) {
+		// A concurrent retry finished the capture first.
+		return s.repo.GetByID(ctx, p.ID)
+	} else if err != nil {
+		return nil, err
+	}
+	p = s.announce(ctx, p)
+	return &p, nil
+}
+
+// announce publishes payment.captured for p and clears its AnnouncePending
+// flag. On failure the flag stays and Reconcile tries again later.
+func (s *Service) announce(ctx context.Context, p Payment) Payment {
+	if err := s.events.PaymentCaptured(ctx, p); err != nil {
+		slog.ErrorContext(ctx, "failed to publish payment.captured, will retry", "payment_id", p.ID, "err", err)
+		return p
+	}
+	prev := p
+	p.AnnouncePending = false
+	if err := s.repo.Update(ctx, p, prev); err != nil {
+		// Published twice at worst; OrderService confirms an order once.
+		slog.WarnContext(ctx, "failed to mark payment.captured as published", "payment_id", p.ID, "err", err)
+		return prev
+	}
+	return p
+}
+
+// StaleAfter is how long a payment may stay Pending, Capturing or Refunding
+// before Reconcile takes it over from the request that left it there.
+const StaleAfter = 2 * time.Minute
+
+// reconcileBatch bounds how many payments one Reconcile pass handles per step.
+const reconcileBatch = 100
+
+// Reconcile finishes what interrupted requests left behind:
+//   - a Pending payment whose authorization outcome was never stored is
+//     marked Failed, which frees its order for another attempt,
+//   - a Capturing payment is captured again,
+//   - a Refunding payment is refunded again, or released if that fails,
+//   - a captured payment whose event was not published is announced.
+func (s *Service) Reconcile(ctx context.Context) error {
+	stale, err := s.repo.ListStale(ctx, []string{StatusPending, StatusCapturing, StatusRefunding}, time.Now().Add(-StaleAfter), reconcileBatch)
+	if err != nil {
+		return err
+	}
+	for _, p := range stale {
+		if p.Status == StatusCapturing {
+			if _, err := s.capture(ctx, p); err != nil {
+				slog.WarnContext(ctx, "failed to finish capture", "payment_id", p.ID, "err", err)
+			}
+			continue
+		}
+		if p.Status == StatusRefunding {
+			slog.InfoContext(ctx, "resuming interrupted refund", "payment_id", p.ID)
+			if _, err := s.refund(ctx, p); err != nil {
+				slog.WarnContext(ctx, "failed to finish refund", "payment_id", p.ID, "err", err)
+			}
+			continue
+		}
+		prev := p
+		p.Status = StatusFailed
+		p.UpdatedAt = time.Now()
+		if err := s.repo.Update(ctx, p, prev); err != nil {
+			slog.WarnContext(ctx, "failed to fail abandoned payment", "payment_id", p.ID, "err", err)
+			continue
+		}
+		slog.WarnContext(ctx, "abandoned pending payment marked failed", "payment_id", p.ID, "order_id", p.OrderID)
+	}
+
+	unannounced, err := s.repo.ListUnannounced(ctx, reconcileBatch)
+	if err != nil {
+		return err
+	}
+	for _, p := range unannounced {
+		s.announce(ctx, p)
+	}
+	return nil
+}
+
+// RunReconciler calls Reconcile every interval until ctx is done.
+func (s *Service) RunReconciler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Reconcile(ctx); err != nil {
+				slog.WarnContext(ctx, "failed to reconcile payments", "err", err)
+			}
+		}
+	}
+}
+
+// Refund gives back amount of a captured payment, or all that is left of it
+// when amount is zero. The payment is Refunding while the provider is asked,
+// so a concurrent refund fails with ErrStatusChanged instead of giving the
+// money back twice.
+func (s *Service) Refund(ctx context.Context, caller identity.Caller, id string, amount int64, reason string) (*Payment, error) {
+	p, err := s.Get(ctx, caller, id)
+	if err != nil {
+		return nil, err
+	}
+	if p.Status != StatusCaptured && p.Status != StatusPartiallyRefunded {
+		return nil, fmt.Errorf("%w: cannot refund a %s payment", ErrInvalidTransition, p.Status)
+	}
+	left := p.Amount - p.RefundedAmount
+	if amount == 0 {
+		amount = left
+	}
+	if amount < 0 || amount > left {
+		return nil, fmt.Errorf("%w: %d of %d left", ErrInvalidAmount, amount, left)
+	}
+
+	prev := *p
+	p.Status = StatusRefunding
+	p.RefundingAmount = amount
+	p.RefundReason = reason
+	p.UpdatedAt = time.Now()
+	if err := s.repo.Update(ctx, *p, prev); err != nil {
+		return nil, err
+	}
+	return s.refund(ctx, *p)
+}
+
+// refund asks the provider for the refund p is Refunding and records it. If
+// the provider fails, the refund is released so that it can be asked for
+// again. The provider key only depends on how much was refunded before, so
+// a refund repeated by Reconcile, or asked for again after an unknown
+// outcome, is not given back twice.
+func (s *Service) refund(ctx context.Context, p Payment) (*Payment, error) {
+	amount, reason := p.RefundingAmount, p.RefundReason
+	key := p.ID + ":" + strconv.FormatInt(p.RefundedAmount, 10)
+	if err := s.provider.Refund(ctx, p.ProviderRef, key, amount); err != nil {
+		s.releaseRefund(ctx, p)
+		return nil, fmt.Errorf("refund payment: %w", err)
+	}
+
+	prev := p
+	p.RefundedAmount += amount
+	p.Status = StatusPartiallyRefunded
+	if p.RefundedAmount == p.Amount {
+		p.Status = StatusRefunded
+	}
+	p.RefundingAmount = 0
+	p.RefundReason = ""
+	p.UpdatedAt = time.Now()
+	if err := s.repo.Update(ctx, p, prev); errors.Is(err, ErrStatusChanged) {
+		// Reconcile finished the refund first.
+		return s.repo.GetByID(ctx, p.ID)
+	} else if err != nil {
+		return nil, err
+	}
+	if err := s.events.PaymentRefunded(ctx, p, amount, reason); err != nil {
+		slog.ErrorContext(ctx, "failed to publish payment.refunded", "payment_id", p.ID, "err", err)
+	}
+	return &p, nil
+}
+
+// releaseRefund moves a Refunding payment back to the status it had before
+// the refund was asked for.
+func (s *Service) releaseRefund(ctx context.Context, p Payment) {
+	prev := p
+	p.Status = StatusPartiallyRefunded
+	if p.RefundedAmount == 0 {
+		p.Status = StatusCaptured
+	}
+	p.RefundingAmount = 0
+	p.RefundReason = ""
+	p.UpdatedAt = time.Now()
+	if err := s.repo.Update(ctx, p, prev); err != nil {
+		slog.WarnContext(ctx, "failed to release refund", "payment_id", p.ID, "err", err)
+	}
+}
+
+// Get returns a payment of the caller; admins may read any payment.
+func (s *Service) Get(ctx context.Context, caller identity.Caller, id string) (*Payment, error) {
+	p, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if p.UserID != caller.UserID && !caller.IsAdmin() {
+		return nil, ErrForbidden
+	}
+	return p, nil
+}
+
+// toCents converts an order total in dollars to cents.
+func toCents(total float64) int64 {
+	return int64(math.Round(total * 100))
+}
@@ -0,0 +1,490 @@
+package payments_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"payment/identity"
+	"payment/payments"
+	orderpb "payment/proto/order"
+	"payment/provider"
+)
+
+// memRepo is an in-memory payments.Repository that enforces the same
+// one-active-payment-per-order rule as the Mongo index.
+type memRepo struct {
+	mu       sync.Mutex
+	payments map[string]payments.Payment
+	// failUpdateTo, if set, makes updates to that status fail.
+	failUpdateTo string
+}
+
+func newMemRepo() *memRepo {
+	return &memRepo{payments: map[string]payments.Payment{}}
+}
+
+func (r *memRepo) Create(ctx context.Context, p payments.Payment) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.payments {
+		if existing.OrderID == p.OrderID && existing.Active() && p.Active() {
+			return "", payments.ErrOrderAlreadyPaid
+		}
+	}
+	p.ID = fmt.Sprintf("pay%d", len(r.payments)+1)
+	r.payments[p.ID] = p
+	return p.ID, nil
+}
+
+func (r *memRepo) GetByID(ctx context.Context, id string) (*payments.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.payments[id]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	return &p, nil
+}
+
+func (r *memRepo) Update(ctx context.Context, p payments.Payment, prev payments.Payment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failUpdateTo != "" && p.Status == r.failUpdateTo {
+		return errors.New("mongo: connection reset")
+	}
+	stored := r.payments[p.ID]
+	if stored.Status != prev.Status || stored.RefundedAmount != prev.RefundedAmount {
+		return payments.ErrStatusChanged
+	}
+	r.payments[p.ID] = p
+	return nil
+}
+
+func (r *memRepo) ListStale(ctx context.Context, statuses []string, before time.Time, limit int64) ([]payments.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var list []payments.Payment
+	for _, p := range r.payments {
+		if slices.Contains(statuses, p.Status) && p.UpdatedAt.Before(before) && int64(len(list)) < limit {
+			list = append(list, p)
+		}
+	}
+	return list, nil
+}
+
+func (r *memRepo) ListUnannounced(ctx context.Context, limit int64) ([]payments.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var list []payments.Payment
+	for _, p := range r.payments {
+		if p.AnnouncePending && int64(len(list)) < limit {
+			list = append(list, p)
+		}
+	}
+	return list, nil
+}
+
+type stubOrders struct {
+	orderpb.OrderServiceClient
+	orders map[string]*orderpb.Order
+}
+
+func (s *stubOrders) GetOrder(ctx context.Context, in *orderpb.GetOrderRequest, opts ...grpc.CallOption) (*orderpb.GetOrderResponse, error) {
+	order, ok := s.orders[in.Id]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "order not found")
+	}
+	return &orderpb.GetOrderResponse{Order: order}, nil
+}
+
+type recordedEvents struct {
+	captured []payments.Payment
+	refunded []int64
+	// err, if set, is returned by PaymentCaptured instead of recording.
+	err error
+}
+
+func (e *recordedEvents) PaymentCaptured(ctx context.Context, p payments.Payment) error {
+	if e.err != nil {
+		return e.err
+	}
+	e.captured = append(e.captured, p)
+	return nil
+}
+
+func (e *recordedEvents) PaymentRefunded(ctx context.Context, p payments.Payment, amount int64, reason string) error {
+	e.refunded = append(e.refunded, amount)
+	return nil
+}
+
+var (
+	customer = identity.Caller{UserID: "user1", Role: "user"}
+	stranger = identity.Caller{UserID: "user2", Role: "user"}
+	admin    = identity.Caller{UserID: "admin1", Role: identity.RoleAdmin}
+)
+
+type memIdempotencyStore struct {
+	records map[string]payments.IdempotencyRecord
+	// err, if set, is returned by Reserve as if Mongo were down.
+	err error
+}
+
+func (s *memIdempotencyStore) Reserve(ctx context.Context, key, hash string) (*payments.IdempotencyRecord, bool, error) {
+	if s.err != nil {
+		return nil, false, s.err
+	}
+	if record, ok := s.records[key]; ok {
+		return &record, false, nil
+	}
+	s.records[key] = payments.IdempotencyRecord{RequestHash: hash}
+	return nil, true, nil
+}
+
+func (s *memIdempotencyStore) Complete(ctx context.Context, key, result string) error {
+	record := s.records[key]
+	record.Result = result
+	s.records[key] = record
+	return nil
+}
+
+func (s *memIdempotencyStore) Release(ctx context.Context, key string) error {
+	delete(s.records, key)
+	return nil
+}
+
+func newService() (*payments.Service, *memRepo, *recordedEvents) {
+	return newServiceWith(provider.NewFakeProvider(provider.FakeConfig{}))
+}
+
+func newServiceWith(p provider.PaymentProvider) (*payments.Service, *memRepo, *recordedEvents) {
+	repo := newMemRepo()
+	events := &recordedEvents{}
+	orders := &stubOrders{orders: map[string]*orderpb.Order{
+		"order1":    {Id: "order1", UserId: "user1", Status: "Pending", TotalPrice: 19.98},
+		"order2":    {Id: "order2", UserId: "user1", Status: "Pending", TotalPrice: 5},
+		"confirmed": {Id: "confirmed", UserId: "user1", Status: "Confirmed", TotalPrice: 5},
+	}}
+	idempotency := &memIdempotencyStore{records: map[string]payments.IdempotencyRecord{}}
+	svc := payments.NewService(repo, p, orders, events, idempotency)
+	return svc, repo, events
+}
+
+func authorize(svc *payments.Service, ctx context.Context, caller identity.Caller, orderID, method string, capture bool) (*payments.Payment, error) {
+	p, _, err := svc.Authorize(ctx, caller, payments.AuthorizeParams{
+		OrderID:       orderID,
+		PaymentMethod: method,
+		Capture:       capture,
+	})
+	return p, err
+}
+
+func TestAuthorize_CaptureImmediately(t *testing.T) {
+	svc, _, events := newService()
+
+	p, err := authorize(svc, context.Background(), customer, "order1", provider.MethodApprove, true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, payments.StatusCaptured, p.Status)
+	assert.Equal(t, int64(1998), p.Amount)
+	assert.Equal(t, payments.DefaultCurrency, p.Currency)
+	if assert.Len(t, events.captured, 1) {
+		assert.Equal(t, "order1", events.captured[0].OrderID)
+	}
+}
+
+func TestAuthorize_DeclineIsRecordedAndRetryable(t *testing.T) {
+	svc, repo, events := newService()
+	ctx := context.Background()
+
+	p, err := authorize(svc, ctx, customer, "order1", provider.MethodInsufficientFunds, true)
+	assert.NoError(t, err)
+	assert.Equal(t, payments.StatusDeclined, p.Status)
+	assert.Equal(t, provider.DeclineInsufficientFunds, p.DeclineReason)
+	assert.Empty(t, events.captured)
+
+	p, err = authorize(svc, ctx, customer, "order1", provider.MethodApprove, false)
+	assert.NoError(t, err)
+	assert.Equal(t, payments.StatusAuthorized, p.Status)
+	assert.Len(t, repo.payments, 2)
+
+	_, err = authorize(svc, ctx, customer, "order1", provider.MethodApprove, false)
+	assert.ErrorIs(t, err, payments.ErrOrderAlreadyPaid)
+}
+
+func TestAuthorize_ChecksOrder(t *testing.T) {
+	svc, _, _ := newService()
+	ctx := context.Background()
+
+	_, err := authorize(svc, ctx, stranger, "order1", provider.MethodApprove, true)
+	assert.ErrorIs(t, err, payments.ErrForbidden)
+
+	_, err = authorize(svc, ctx, customer, "confirmed", provider.MethodApprove, true)
+	assert.ErrorIs(t, err, payments.ErrOrderNotPayable)
+
+	_, err = authorize(svc, ctx, customer, "missing", provider.MethodApprove, true)
+	assert.ErrorIs(t, err, payments.ErrOrderNotFound)
+
+	p, err := authorize(svc, ctx, admin, "order1", provider.MethodApprove, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "user1", p.UserID)
+}
+
+func TestCaptureOnlyOnce(t *testing.T) {
+	svc, _, events := newService()
+	ctx := context.Background()
+
+	p, err := authorize(svc, ctx, customer, "order1", provider.MethodApprove, false)
+	assert.NoError(t, err)
+	assert.Empty(t, events.captured)
+
+	_, err = svc.Capture(ctx, stranger, p.ID)
+	assert.ErrorIs(t, err, payments.ErrForbidden)
+
+	p, err = svc.Capture(ctx, customer, p.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, payments.StatusCaptured, p.Status)
+
+	_, err = svc.Capture(ctx, customer, p.ID)
+	assert.ErrorIs(t, err, payments.ErrInvalidTransition)
+	assert.Len(t, events.captured, 1)
+}
+
+func TestCapture_AnnouncesAfterPublishFailure(t *testing.T) {
+	svc, repo, events := newService()
+	ctx := context.Background()
+	events.err = errors.New("nats: timeout")
+
+	p, err := authorize(svc, ctx, customer, "order1", provider.MethodApprove, true)
+	assert.NoError(t, err)
+	assert.Equal(t, payments.StatusCaptured, p.Status)
+	assert.True(t, repo.payments[p.ID].AnnouncePending)
+	assert.Empty(t, events.captured)
+
+	events.err = nil
+	assert.NoError(t, svc.Reconcile(ctx))
+	assert.Len(t, events.captured, 1)
+	assert.False(t, repo.payments[p.ID].AnnouncePending)
+
+	assert.NoError(t, svc.Reconcile(ctx))
+	assert.Len(t, events.captured, 1)
+}
+
+func TestCapture_ResumesAfterFailedUpdate(t *testing.T) {
+	svc, repo, events := newService()
+	ctx := context.Background()
+
+	p, err := authorize(svc, ctx, customer, "order1", provider.MethodApprove, false)
+	assert.NoError(t, err)
+
+	// The provider took the money but the Captured status was not stored.
+	repo.failUpdateTo = payments.StatusCaptured
+	_, err = svc.Capture(ctx, customer, p.ID)
+	assert.Error(t, err)
+	assert.Equal(t, payments.StatusCapturing, repo.payments[p.ID].Status)
+	assert.Empty(t, events.captured)
+
+	repo.failUpdateTo = ""
+	p, err = svc.Capture(ctx, customer, p.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, payments.StatusCaptured, p.Status)
+	assert.Len(t, events.captured, 1)
+}
+
+func TestReconcile(t *testing.T) {
+	svc, repo, events := newService()
+	ctx := context.Background()
+
+	// A capture interrupted before its outcome was stored.
+	p, err := authorize(svc, ctx, customer, "order1", provider.MethodApprove, false)
+	assert.NoError(t, err)
+	repo.failUpdateTo = payments.StatusCaptured
+	_, err = svc.Capture(ctx, customer, p.ID)
+	assert.Error(t, err)
+	repo.failUpdateTo = ""
+	capturing := repo.payments[p.ID]
+	capturing.UpdatedAt = time.Now().Add(-payments.StaleAfter - time.Minute)
+	repo.payments[p.ID] = capturing
+
+	// An authorization whose outcome was never stored blocks order2.
+	abandoned := payments.Payment{OrderID: "order2", UserID: "user1", Amount: 500, Status: payments.StatusPending,
+		UpdatedAt: time.Now().Add(-payments.StaleAfter - time.Minute)}
+	abandoned.ID, err = repo.Create(ctx, abandoned)
+	assert.NoError(t, err)
+	_, err = authorize(svc, ctx, customer, "order2", provider.MethodApprove, true)
+	assert.ErrorIs(t, err, payments.ErrOrderAlreadyPaid)
+
+	assert.NoError(t, svc.Reconcile(ctx))
+
+	assert.Equal(t, payments.StatusCaptured, repo.payments[p.ID].Status)
+	assert.Len(t, events.captured, 1)
+	assert.Equal(t, payments.StatusFailed, repo.payments[abandoned.ID].Status)
+	retry, err := authorize(svc, ctx, customer, "order2", provider.MethodApprove, true)
+	assert.NoError(t, err)
+	assert.Equal(t, payments.StatusCaptured, retry.Status)
+}
+
+func TestRefund(t *testing.T) {
+	svc, _, events := newService()
+	ctx := context.Background()
+
+	p, err := authorize(svc, ctx, customer, "order1", provider.MethodApprove, false)
+	assert.NoError(t, err)
+	_, err = svc.Refund(ctx, admin, p.ID, 0, "")
+	assert.ErrorIs(t, err, payments.ErrInvalidTransition, "refund before capture")
+
+	_, err = svc.Capture(ctx, customer, p.ID)
+	assert.NoError(t, err)
+
+	p, err = svc.Refund(ctx, admin, p.ID, 998, "missing fries")
+	assert.NoError(t, err)
+	assert.Equal(t, payments.StatusPartiallyRefunded, p.Status)
+
+	_, err = svc.Refund(ctx, admin, p.ID, 1001, "")
+	assert.ErrorIs(t, err, payments.ErrInvalidAmount)
+
+	p, err = svc.Refund(ctx, admin, p.ID, 0, "")
+	assert.NoError(t, err)
+	assert.Equal(t, payments.StatusRefunded, p.Status)
+	assert.Equal(t, p.Amount, p.RefundedAmount)
+	assert.Equal(t, []int64{998, 1000}, events.refunded)
+}
+
+func TestRefund_ConcurrentRefundsGoThroughOnce(t *testing.T) {
+	fake := provider.NewFakeProvider(provider.FakeConfig{Delay: 20 * time.Millisecond})
+	svc, repo, events := newServiceWith(fake)
+	ctx := context.Background()
+
+	p, err := authorize(svc, ctx, customer, "order1", provider.MethodApprove, true)
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = svc.Refund(ctx, admin, p.ID, 600, "cold pizza")
+		}()
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			failed++
+			assert.True(t, errors.Is(err, payments.ErrStatusChanged) || errors.Is(err, payments.ErrInvalidTransition), err)
+		}
+	}
+	assert.Equal(t, 1, failed, "exactly one refund goes through")
+	assert.Equal(t, int64(600), repo.payments[p.ID].RefundedAmount)
+	assert.Equal(t, []int64{600}, events.refunded)
+
+	// The provider gave back 600 once, so the rest can still be refunded.
+	p, err = svc.Refund(ctx, admin, p.ID, 0, "")
+	assert.NoError(t, err)
+	assert.Equal(t, payments.StatusRefunded, p.Status)
+}
+
+func TestRefund_ReleasedWhenProviderFails(t *testing.T) {
+	svc, repo, events := newService()
+	ctx := context.Background()
+
+	p, err := authorize(svc, ctx, customer, "order1", provider.MethodApprove, true)
+	assert.NoError(t, err)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = svc.Refund(canceled, admin, p.ID, 500, "")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, payments.StatusCaptured, repo.payments[p.ID].Status)
+	assert.Zero(t, repo.payments[p.ID].RefundingAmount)
+	assert.Empty(t, events.refunded)
+
+	p, err = svc.Refund(ctx, admin, p.ID, 500, "")
+	assert.NoError(t, err)
+	assert.Equal(t, payments.StatusPartiallyRefunded, p.Status)
+}
+
+func TestReconcile_FinishesInterruptedRefund(t *testing.T) {
+	fake := provider.NewFakeProvider(provider.FakeConfig{})
+	svc, repo, events := newServiceWith(fake)
+	ctx := context.Background()
+
+	p, err := authorize(svc, ctx, customer, "order1", provider.MethodApprove, true)
+	assert.NoError(t, err)
+
+	// The provider refunded 500, but the request died before storing it.
+	refunding := repo.payments[p.ID]
+	refunding.Status = payments.StatusRefunding
+	refunding.RefundingAmount = 500
+	refunding.RefundReason = "late delivery"
+	refunding.UpdatedAt = time.Now().Add(-payments.StaleAfter - time.Minute)
+	repo.payments[p.ID] = refunding
+	assert.NoError(t, fake.Refund(ctx, p.ProviderRef, p.ID+":0", 500))
+
+	assert.NoError(t, svc.Reconcile(ctx))
+
+	stored := repo.payments[p.ID]
+	assert.Equal(t, payments.StatusPartiallyRefunded, stored.Status)
+	assert.Equal(t, int64(500), stored.RefundedAmount)
+	assert.Zero(t, stored.RefundingAmount)
+	assert.Equal(t, []int64{500}, events.refunded)
+
+	p, err = svc.Refund(ctx, admin, p.ID, 0, "")
+	assert.NoError(t, err)
+	assert.Equal(t, payments.StatusRefunded, p.Status)
+}
+
+func TestAuthorize_IdempotencyKey(t *testing.T) {
+	svc, repo, events := newService()
+	ctx := context.Background()
+	params := payments.AuthorizeParams{
+		OrderID:        "order1",
+		PaymentMethod:  provider.MethodApprove,
+		Capture:        true,
+		IdempotencyKey: "key1",
+	}
+
+	first, replayed, err := svc.Authorize(ctx, customer, params)
+	assert.NoError(t, err)
+	assert.False(t, replayed)
+
+	again, replayed, err := svc.Authorize(ctx, customer, params)
+	assert.NoError(t, err)
+	assert.True(t, replayed)
+	assert.Equal(t, first.ID, again.ID)
+	assert.Len(t, repo.payments, 1)
+	assert.Len(t, events.captured, 1)
+
+	params.OrderID = "order2"
+	_, _, err = svc.Authorize(ctx, customer, params)
+	assert.ErrorIs(t, err, payments.ErrIdempotencyConflict)
+}
+
+func TestAuthorize_IdempotencyStoreDownFailsClosed(t *testing.T) {
+	repo := newMemRepo()
+	orders := &stubOrders{orders: map[string]*orderpb.Order{
+		"order1": {Id: "order1", UserId: "user1", Status: "Pending", TotalPrice: 5},
+	}}
+	idempotency := &memIdempotencyStore{records: map[string]payments.IdempotencyRecord{}, err: errors.New("mongo: no reachable servers")}
+	svc := payments.NewService(repo, provider.NewFakeProvider(provider.FakeConfig{}), orders, &recordedEvents{}, idempotency)
+
+	_, _, err := svc.Authorize(context.Background(), customer, payments.AuthorizeParams{
+		OrderID:        "order1",
+		PaymentMethod:  provider.MethodApprove,
+		IdempotencyKey: "key1",
+	})
+	assert.ErrorIs(t, err, payments.ErrIdempotencyUnavailable)
+	assert.Empty(t, repo.payments)
+}
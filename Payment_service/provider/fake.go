@@ -0,0 +1,166 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Test payment methods the fake provider always understands. Any other
+// method is approved unless FakeConfig.Declines says otherwise.
+const (
+	MethodApprove           = "tok_visa"
+	MethodDecline           = "tok_decline"
+	MethodInsufficientFunds = "tok_insufficient_funds"
+	MethodExpired           = "tok_expired"
+)
+
+var builtinDeclines = map[string]string{
+	MethodDecline:           DeclineCardDeclined,
+	MethodInsufficientFunds: DeclineInsufficientFunds,
+	MethodExpired:           DeclineExpiredCard,
+}
+
+// FakeConfig tunes the fake provider for local runs and tests.
+type FakeConfig struct {
+	// Delay is added to every call to mimic a remote processor.
+	Delay time.Duration
+	// Declines maps extra payment methods to the decline code they get.
+	Declines map[string]string
+	// MaxAmount declines larger authorizations; zero means no limit.
+	MaxAmount int64
+}
+
+type fakeAuthorization struct {
+	amount   int64
+	captured int64
+	refunded int64
+	// refunds holds the amount of every refund key seen.
+	refunds map[string]int64
+}
+
+// FakeProvider is a deterministic in-memory PaymentProvider: the outcome of a
+// call depends only on its input and configuration, never on chance.
+type FakeProvider struct {
+	cfg FakeConfig
+
+	mu    sync.Mutex
+	auths map[string]*fakeAuthorization
+}
+
+func NewFakeProvider(cfg FakeConfig) *FakeProvider {
+	return &FakeProvider{cfg: cfg, auths: map[string]*fakeAuthorization{}}
+}
+
+func (f *FakeProvider) Name() string {
+	return "fake"
+}
+
+func (f *FakeProvider) Authorize(ctx context.Context, req AuthorizeRequest) (string, error) {
+	if err := f.wait(ctx); err != nil {
+		return "", err
+	}
+	if code, ok := f.declineCode(req.PaymentMethod); ok {
+		return "", &DeclineError{Code: code}
+	}
+	if f.cfg.MaxAmount > 0 && req.Amount > f.cfg.MaxAmount {
+		return "", &DeclineError{Code: DeclineAmountTooLarge}
+	}
+
+	reference := "fake_" + req.PaymentID
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.auths[reference]; !ok {
+		f.auths[reference] = &fakeAuthorization{amount: req.Amount}
+	}
+	return reference, nil
+}
+
+func (f *FakeProvider) Capture(ctx context.Context, reference string, amount int64) error {
+	if err := f.wait(ctx); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	auth, ok := f.auths[reference]
+	if !ok {
+		return ErrUnknownReference
+	}
+	if auth.captured == auth.amount && amount == auth.amount {
+		return nil
+	}
+	if auth.captured+amount > auth.amount {
+		return fmt.Errorf("capture of %d exceeds authorized %d", amount, auth.amount-auth.captured)
+	}
+	auth.captured += amount
+	return nil
+}
+
+func (f *FakeProvider) Refund(ctx context.Context, reference, key string, amount int64) error {
+	if err := f.wait(ctx); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	auth, ok := f.auths[reference]
+	if !ok {
+		return ErrUnknownReference
+	}
+	if done, ok := auth.refunds[key]; ok {
+		if done != amount {
+			return fmt.Errorf("refund %s was for %d, not %d", key, done, amount)
+		}
+		return nil
+	}
+	if auth.refunded+amount > auth.captured {
+		return fmt.Errorf("refund of %d exceeds captured %d", amount, auth.captured-auth.refunded)
+	}
+	if auth.refunds == nil {
+		auth.refunds = map[string]int64{}
+	}
+	auth.refunds[key] = amount
+	auth.refunded += amount
+	return nil
+}
+
+func (f *FakeProvider) declineCode(method string) (string, bool) {
+	if code, ok := f.cfg.Declines[method]; ok {
+		return code, true
+	}
+	code, ok := builtinDeclines[method]
+	return code, ok
+}
+
+func (f *FakeProvider) wait(ctx context.Context) error {
+	if f.cfg.Delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(f.cfg.Delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ParseDeclines reads method=code pairs separated by commas, the format of
+// FAKE_PROVIDER_DECLINES.
+func ParseDeclines(s string) (map[string]string, error) {
+	declines := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		method, code, ok := strings.Cut(pair, "=")
+		if !ok || method == "" || code == "" {
+			return nil, fmt.Errorf("invalid decline %q, want method=code", pair)
+		}
+		declines[method] = code
+	}
+	return declines, nil
+}
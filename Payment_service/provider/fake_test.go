@@ -0,0 +1,88 @@
+package provider_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"payment/provider"
+)
+
+func TestFakeProvider_Declines(t *testing.T) {
+	fake := provider.NewFakeProvider(provider.FakeConfig{
+		Declines:  map[string]string{"tok_custom": provider.DeclineCardDeclined},
+		MaxAmount: 10000,
+	})
+
+	tests := []struct {
+		method string
+		amount int64
+		code   string
+	}{
+		{provider.MethodApprove, 500, ""},
+		{"tok_anything_else", 500, ""},
+		{provider.MethodDecline, 500, provider.DeclineCardDeclined},
+		{provider.MethodInsufficientFunds, 500, provider.DeclineInsufficientFunds},
+		{provider.MethodExpired, 500, provider.DeclineExpiredCard},
+		{"tok_custom", 500, provider.DeclineCardDeclined},
+		{provider.MethodApprove, 10001, provider.DeclineAmountTooLarge},
+	}
+	for _, tt := range tests {
+		ref, err := fake.Authorize(context.Background(), provider.AuthorizeRequest{
+			PaymentID:     "p1",
+			Amount:        tt.amount,
+			PaymentMethod: tt.method,
+		})
+		if tt.code == "" {
+			assert.NoError(t, err, tt.method)
+			assert.Equal(t, "fake_p1", ref)
+			continue
+		}
+		var decline *provider.DeclineError
+		if assert.True(t, errors.As(err, &decline), tt.method) {
+			assert.Equal(t, tt.code, decline.Code)
+		}
+	}
+}
+
+func TestFakeProvider_CaptureAndRefundLimits(t *testing.T) {
+	fake := provider.NewFakeProvider(provider.FakeConfig{})
+	ctx := context.Background()
+
+	ref, err := fake.Authorize(ctx, provider.AuthorizeRequest{PaymentID: "p1", Amount: 1000, PaymentMethod: provider.MethodApprove})
+	assert.NoError(t, err)
+
+	assert.Error(t, fake.Refund(ctx, ref, "r0", 1), "refund before capture")
+	assert.Error(t, fake.Capture(ctx, ref, 1001))
+	assert.NoError(t, fake.Capture(ctx, ref, 1000))
+	assert.NoError(t, fake.Capture(ctx, ref, 1000), "retried full capture")
+	assert.Error(t, fake.Capture(ctx, ref, 1))
+	assert.NoError(t, fake.Refund(ctx, ref, "r1", 400))
+	assert.Error(t, fake.Refund(ctx, ref, "r2", 601))
+	assert.NoError(t, fake.Refund(ctx, ref, "r2", 600))
+	assert.NoError(t, fake.Refund(ctx, ref, "r1", 400), "retried refund")
+	assert.Error(t, fake.Refund(ctx, ref, "r1", 1), "retried refund with another amount")
+
+	assert.ErrorIs(t, fake.Capture(ctx, "fake_unknown", 1), provider.ErrUnknownReference)
+}
+
+func TestFakeProvider_DelayHonorsContext(t *testing.T) {
+	fake := provider.NewFakeProvider(provider.FakeConfig{Delay: time.Second})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := fake.Authorize(ctx, provider.AuthorizeRequest{PaymentID: "p1", Amount: 100, PaymentMethod: provider.MethodApprove})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestParseDeclines(t *testing.T) {
+	declines, err := provider.ParseDeclines(" tok_a=card_declined, tok_b=expired_card ,")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"tok_a": "card_declined", "tok_b": "expired_card"}, declines)
+
+	_, err = provider.ParseDeclines("tok_a")
+	assert.Error(t, err)
+}
@@ -0,0 +1,56 @@
+// Package provider abstracts the payment processor that actually moves money.
+package provider
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrUnknownReference is returned for references the provider never issued.
+var ErrUnknownReference = errors.New("unknown provider reference")
+
+// DeclineError is a payment the provider refused. Unlike other errors it is
+// a final answer for this payment method, so it must not be retried.
+type DeclineError struct {
+	Code string
+}
+
+func (e *DeclineError) Error() string {
+	return "payment declined: " + e.Code
+}
+
+// Decline codes the fake provider reports; real providers map theirs onto
+// these so the rest of the service does not depend on a vendor's wording.
+const (
+	DeclineCardDeclined      = "card_declined"
+	DeclineInsufficientFunds = "insufficient_funds"
+	DeclineExpiredCard       = "expired_card"
+	DeclineAmountTooLarge    = "amount_too_large"
+)
+
+// AuthorizeRequest describes the amount to reserve. PaymentID is passed on as
+// the idempotency key, so retrying an authorization does not reserve twice.
+type AuthorizeRequest struct {
+	PaymentID     string
+	Amount        int64
+	Currency      string
+	PaymentMethod string
+}
+
+// PaymentProvider is implemented by every payment processor integration.
+// Amounts are in minor currency units.
+type PaymentProvider interface {
+	Name() string
+	// Authorize reserves the amount and returns the provider's reference for
+	// the reservation, or a *DeclineError.
+	Authorize(ctx context.Context, req AuthorizeRequest) (string, error)
+	// Capture takes amount of the reservation. Capturing a reservation that
+	// was already captured in full succeeds again, so a capture whose outcome
+	// is unknown can be retried.
+	Capture(ctx context.Context, reference string, amount int64) error
+	// Refund gives back amount of the captured money. key identifies the
+	// refund: repeating a refund with the same key succeeds without giving
+	// the money back twice, so a refund whose outcome is unknown can be
+	// retried.
+	Refund(ctx context.Context, reference, key string, amount int64) error
+}
@@ -0,0 +1,106 @@
+package receipt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"payment/identity"
+	orderpb "payment/proto/order"
+)
+
+var (
+	ErrOrderNotFound = errors.New("order not found")
+	ErrForbidden     = errors.New("order belongs to another user")
+)
+
+// Orders builds receipts of the orders kept by OrderService, for downloads.
+type Orders struct {
+	Store  Store
+	Client orderpb.OrderServiceClient
+	Menu   Menu
+	// CustomerName returns the name printed for the owner of an order, or ""
+	// when the user no longer exists.
+	CustomerName func(ctx context.Context, userID string) (string, error)
+}
+
+// Receipt returns the receipt of order id, which must belong to caller unless
+// caller is an admin.
+func (o *Orders) Receipt(ctx context.Context, caller identity.Caller, id string) (Receipt, error) {
+	res, err := o.Client.GetOrder(identity.OutgoingContext(ctx, caller), &orderpb.GetOrderRequest{Id: id})
+	if status.Code(err) == codes.NotFound {
+		return Receipt{}, ErrOrderNotFound
+	}
+	if err != nil {
+		return Receipt{}, fmt.Errorf("get order: %w", err)
+	}
+	order := res.Order
+	if order.UserId != caller.UserID && !caller.IsAdmin() {
+		return Receipt{}, ErrForbidden
+	}
+
+	var lines []Line
+	for _, item := range order.Items {
+		lines = append(lines, Line{
+			MenuItemID: item.MenuItemId,
+			Name:       item.Name,
+			Quantity:   item.Quantity,
+			UnitPrice:  item.UnitPrice,
+			Total:      item.LineTotal,
+		})
+	}
+	if len(lines) == 0 {
+		lines = LinesFromIDs(order.ItemIds)
+	}
+	if err := Resolve(ctx, lines, o.Menu); err != nil {
+		return Receipt{}, fmt.Errorf("get menu items: %w", err)
+	}
+	name, err := o.CustomerName(ctx, order.UserId)
+	if err != nil {
+		return Receipt{}, fmt.Errorf("get customer: %w", err)
+	}
+	createdAt, err := time.Parse(time.RFC3339, order.CreatedAt)
+	if err != nil {
+		return Receipt{}, fmt.Errorf("order created_at: %w", err)
+	}
+	details, err := fulfillment(order.Fulfillment)
+	if err != nil {
+		return Receipt{}, err
+	}
+
+	return o.Store.Receipt(Order{
+		ID:          order.Id,
+		CreatedAt:   createdAt,
+		Lines:       lines,
+		Discount:    order.Discount,
+		DeliveryFee: order.DeliveryFee,
+		Total:       order.TotalPrice,
+		Fulfillment: details,
+	}, name), nil
+}
+
+func fulfillment(f *orderpb.Fulfillment) (*Fulfillment, error) {
+	if f == nil {
+		return nil, nil
+	}
+	var requestedAt time.Time
+	if f.RequestedTime != "" {
+		var err error
+		requestedAt, err = time.Parse(time.RFC3339, f.RequestedTime)
+		if err != nil {
+			return nil, fmt.Errorf("order requested_time: %w", err)
+		}
+	}
+	return &Fulfillment{
+		Type:        f.Type,
+		ContactName: f.ContactName,
+		Address:     f.Address,
+		Phone:       f.Phone,
+		Notes:       f.Notes,
+		RequestedAt: requestedAt,
+	}, nil
+}
@@ -0,0 +1,166 @@
+package receipt
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Layout of an A4 page, in millimetres.
+const (
+	margin     = 15.0
+	lineHeight = 7.0
+	nameWidth  = 95.0
+	qtyWidth   = 20.0
+	priceWidth = 30.0
+	totalWidth = 35.0
+)
+
+var brandColor = [3]int{228, 87, 46}
+
+// PDF lays r out as an A4 document. The output depends only on r and s, so a
+// receipt generated again is identical to the one mailed. The core fonts only
+// cover Windows-1252; other characters are printed as '?'.
+func (s Store) PDF(r Receipt) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(margin, margin, margin)
+	pdf.SetAutoPageBreak(true, 25)
+	pdf.SetCreationDate(r.CreatedAt)
+	pdf.SetModificationDate(r.CreatedAt)
+	pdf.SetCatalogSort(true)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetTitle(tr("Receipt for order "+r.OrderID), false)
+	pdf.SetAuthor(tr(s.Name), false)
+	pdf.AliasNbPages("")
+
+	pdf.SetHeaderFunc(func() {
+		pdf.SetFont("Arial", "B", 20)
+		pdf.SetTextColor(brandColor[0], brandColor[1], brandColor[2])
+		pdf.CellFormat(0, 10, tr(s.Name), "", 1, "L", false, 0, "")
+		pdf.SetFont("Arial", "", 9)
+		pdf.SetTextColor(110, 110, 110)
+		for _, line := range []string{s.Address, s.Contact} {
+			if line != "" {
+				pdf.CellFormat(0, 5, tr(line), "", 1, "L", false, 0, "")
+			}
+		}
+		pdf.SetDrawColor(brandColor[0], brandColor[1], brandColor[2])
+		pdf.SetLineWidth(0.6)
+		y := pdf.GetY() + 2
+		pdf.Line(margin, y, 210-margin, y)
+		pdf.SetY(y + 6)
+		pdf.SetTextColor(0, 0, 0)
+		pdf.SetLineWidth(0.2)
+	})
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-18)
+		pdf.SetFont("Arial", "", 8)
+		pdf.SetTextColor(110, 110, 110)
+		if s.Footer != "" {
+			pdf.CellFormat(0, 5, tr(s.Footer), "", 1, "C", false, 0, "")
+		}
+		pdf.CellFormat(0, 5, fmt.Sprintf("Page %d/{nb}", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+
+	pdf.SetFont("Arial", "B", 14)
+	pdf.CellFormat(0, 8, "Receipt", "", 1, "L", false, 0, "")
+	pdf.SetFont("Arial", "", 10)
+	info := [][2]string{{"Order", r.OrderID}, {"Date", r.CreatedAt.UTC().Format("Jan 2, 2006 15:04 UTC")}}
+	if r.CustomerName != "" {
+		info = append(info, [2]string{"Customer", r.CustomerName})
+	}
+	if f := r.Fulfillment; f != nil {
+		info = append(info, fulfillmentInfo(*f)...)
+	}
+	for _, row := range info {
+		pdf.CellFormat(25, 6, row[0]+":", "", 0, "L", false, 0, "")
+		pdf.MultiCell(0, 6, tr(row[1]), "", "L", false)
+	}
+	pdf.Ln(4)
+
+	pdf.SetFont("Arial", "B", 10)
+	pdf.SetFillColor(240, 240, 240)
+	pdf.SetDrawColor(210, 210, 210)
+	pdf.CellFormat(nameWidth, lineHeight, "Item", "B", 0, "L", true, 0, "")
+	pdf.CellFormat(qtyWidth, lineHeight, "Qty", "B", 0, "C", true, 0, "")
+	pdf.CellFormat(priceWidth, lineHeight, "Unit price", "B", 0, "R", true, 0, "")
+	pdf.CellFormat(totalWidth, lineHeight, "Amount", "B", 1, "R", true, 0, "")
+	pdf.SetFont("Arial", "", 10)
+	for _, l := range r.Lines {
+		pdf.CellFormat(nameWidth, lineHeight, fit(pdf, tr(l.Name), nameWidth-2), "B", 0, "L", false, 0, "")
+		pdf.CellFormat(qtyWidth, lineHeight, fmt.Sprint(l.Quantity), "B", 0, "C", false, 0, "")
+		pdf.CellFormat(priceWidth, lineHeight, money(l.UnitPrice), "B", 0, "R", false, 0, "")
+		pdf.CellFormat(totalWidth, lineHeight, money(l.Total), "B", 1, "R", false, 0, "")
+	}
+	pdf.Ln(3)
+
+	labelWidth := nameWidth + qtyWidth + priceWidth
+	total := func(label, amount string) {
+		pdf.CellFormat(labelWidth, 6, label, "", 0, "R", false, 0, "")
+		pdf.CellFormat(totalWidth, 6, amount, "", 1, "R", false, 0, "")
+	}
+	total("Subtotal", money(r.Subtotal))
+	discount := money(r.Discount)
+	if r.Discount > 0 {
+		discount = "-" + discount
+	}
+	total("Discount", discount)
+	total("Delivery fee", money(r.DeliveryFee))
+	pdf.SetFont("Arial", "B", 12)
+	total("Total", money(r.Total))
+	pdf.SetFont("Arial", "", 9)
+	pdf.SetTextColor(110, 110, 110)
+	total(tr(fmt.Sprintf("Includes %s %s", r.TaxName, percent(r.TaxRate))), money(r.Tax))
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("generate receipt of order %s: %w", r.OrderID, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// fulfillmentInfo lists the checkout details of an order, leaving out what
+// the customer did not fill in.
+func fulfillmentInfo(f Fulfillment) [][2]string {
+	var info [][2]string
+	if f.Delivery() {
+		info = append(info, [2]string{"Delivery", f.Address})
+	} else {
+		info = append(info, [2]string{"Pickup", "at the store"})
+	}
+	when := "As soon as possible"
+	if !f.RequestedAt.IsZero() {
+		when = f.RequestedAt.UTC().Format("Jan 2, 2006 15:04 UTC")
+	}
+	info = append(info, [2]string{"Requested", when})
+	if f.ContactName != "" {
+		info = append(info, [2]string{"Contact", f.ContactName})
+	}
+	info = append(info, [2]string{"Phone", f.Phone})
+	if f.Notes != "" {
+		info = append(info, [2]string{"Notes", f.Notes})
+	}
+	return info
+}
+
+// fit shortens s with an ellipsis until it fits into width.
+func fit(pdf *gofpdf.Fpdf, s string, width float64) string {
+	if pdf.GetStringWidth(s) <= width {
+		return s
+	}
+	for s != "" && pdf.GetStringWidth(s+"...") > width {
+		s = s[:len(s)-1]
+	}
+	return strings.TrimSpace(s) + "..."
+}
+
+func money(amount float64) string {
+	return fmt.Sprintf("$%.2f", amount)
+}
+
+func percent(rate float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", rate*100), "0"), ".") + "%"
+}
@@ -0,0 +1,171 @@
+// Package receipt builds order receipts. The receipt mailed after an order is
+// placed and the one downloaded through the gateway are made by the same
+// code, so they are the same document.
+package receipt
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Line is one menu item of a receipt.
+type Line struct {
+	MenuItemID string
+	Name       string
+	Quantity   int32
+	UnitPrice  float64
+	Total      float64
+}
+
+// Fulfillment is how an order reaches the customer, as entered at checkout.
+type Fulfillment struct {
+	// Type is "delivery" or "pickup".
+	Type        string
+	ContactName string
+	Address     string
+	Phone       string
+	Notes       string
+	// RequestedAt is zero for as soon as possible.
+	RequestedAt time.Time
+}
+
+// Delivery reports whether the order is brought to Address.
+func (f Fulfillment) Delivery() bool {
+	return f.Type == "delivery"
+}
+
+// Order is what a receipt is made of.
+type Order struct {
+	ID          string
+	CreatedAt   time.Time
+	Lines       []Line
+	Discount    float64
+	DeliveryFee float64
+	// Total is what the customer pays; it is taken as is, never recomputed.
+	Total float64
+	// Fulfillment is nil for orders placed without checkout details.
+	Fulfillment *Fulfillment
+}
+
+// Receipt is an order with its amounts broken down.
+type Receipt struct {
+	OrderID      string
+	CustomerName string
+	CreatedAt    time.Time
+	Lines        []Line
+	Subtotal     float64
+	Discount     float64
+	DeliveryFee  float64
+	// Tax is the part of Total that is tax, at TaxRate; menu prices
+	// include it.
+	Tax         float64
+	TaxName     string
+	TaxRate     float64
+	Total       float64
+	Fulfillment *Fulfillment
+}
+
+// Store is the seller printed on receipts.
+type Store struct {
+	Name    string
+	Address string
+	// Contact is a line with the phone, email or website of the store.
+	Contact string
+	Footer  string
+	TaxName string
+	// TaxRate is the tax included in menu prices, e.g. 0.12 for 12%.
+	TaxRate float64
+}
+
+// Receipt breaks o down for the customer with the given name.
+func (s Store) Receipt(o Order, customerName string) Receipt {
+	r := Receipt{
+		OrderID:      o.ID,
+		CustomerName: customerName,
+		CreatedAt:    o.CreatedAt,
+		Lines:        o.Lines,
+		Discount:     o.Discount,
+		DeliveryFee:  o.DeliveryFee,
+		TaxName:      s.TaxName,
+		TaxRate:      s.TaxRate,
+		Total:        o.Total,
+		Fulfillment:  o.Fulfillment,
+	}
+	for _, l := range o.Lines {
+		r.Subtotal += l.Total
+	}
+	r.Subtotal = round(r.Subtotal)
+	if s.TaxRate > 0 {
+		r.Tax = round(o.Total * s.TaxRate / (1 + s.TaxRate))
+	}
+	return r
+}
+
+func round(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// MenuItem is what a receipt shows of a menu item.
+type MenuItem struct {
+	Name  string
+	Price float64
+}
+
+// Menu looks up menu items by id; items missing from the result were deleted
+// from the menu.
+type Menu func(ctx context.Context, ids []string) (map[string]MenuItem, error)
+
+// LinesFromIDs groups the item ids of orders placed before line items existed
+// into unnamed lines, counting a repeated id as one more unit.
+func LinesFromIDs(ids []string) []Line {
+	var lines []Line
+	index := map[string]int{}
+	for _, id := range ids {
+		if i, ok := index[id]; ok {
+			lines[i].Quantity++
+			continue
+		}
+		index[id] = len(lines)
+		lines = append(lines, Line{MenuItemID: id, Quantity: 1})
+	}
+	return lines
+}
+
+// Resolve names the lines without a name from menu, and prices those without
+// a price. Items no longer on the menu are shown by id. menu may be nil.
+func Resolve(ctx context.Context, lines []Line, menu Menu) error {
+	var missing []string
+	for _, l := range lines {
+		if l.Name == "" {
+			missing = append(missing, l.MenuItemID)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	var items map[string]MenuItem
+	if menu != nil {
+		var err error
+		if items, err = menu(ctx, missing); err != nil {
+			return err
+		}
+	}
+	for i := range lines {
+		l := &lines[i]
+		if l.Name != "" {
+			continue
+		}
+		item, ok := items[l.MenuItemID]
+		if !ok {
+			l.Name = l.MenuItemID
+			continue
+		}
+		l.Name = item.Name
+		if l.UnitPrice == 0 {
+			l.UnitPrice = item.Price
+			l.Total = round(item.Price * float64(l.Quantity))
+		}
+	}
+	return nil
+}
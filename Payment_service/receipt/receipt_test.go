@@ -0,0 +1,205 @@
+package receipt_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"payment/identity"
+	orderpb "payment/proto/order"
+	"payment/receipt"
+)
+
+var store = receipt.Store{
+	Name:    "QuickBite",
+	Address: "Abay 10, Almaty",
+	Contact: "support@quickbite.test",
+	Footer:  "Thank you!",
+	TaxName: "VAT",
+	TaxRate: 0.12,
+}
+
+func menu(ctx context.Context, ids []string) (map[string]receipt.MenuItem, error) {
+	return map[string]receipt.MenuItem{"m1": {Name: "Burger", Price: 7.99}}, nil
+}
+
+func TestStore_Receipt(t *testing.T) {
+	r := store.Receipt(receipt.Order{
+		ID: "o1",
+		Lines: []receipt.Line{
+			{Name: "Burger", Quantity: 2, UnitPrice: 7.99, Total: 15.98},
+			{Name: "Fries", Quantity: 1, UnitPrice: 3.49, Total: 3.49},
+		},
+		Total: 19.47,
+	}, "John")
+
+	if r.Subtotal != 19.47 || r.Total != 19.47 || r.CustomerName != "John" {
+		t.Errorf("receipt = %+v", r)
+	}
+	// Prices include tax: 19.47 * 0.12 / 1.12.
+	if r.Tax != 2.09 || r.TaxName != "VAT" || r.TaxRate != 0.12 {
+		t.Errorf("tax = %v %s %v, want 2.09 VAT 0.12", r.Tax, r.TaxName, r.TaxRate)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	lines := receipt.LinesFromIDs([]string{"m1", "gone", "m1"})
+	if err := receipt.Resolve(context.Background(), lines, menu); err != nil {
+		t.Fatal(err)
+	}
+	want := []receipt.Line{
+		{MenuItemID: "m1", Name: "Burger", Quantity: 2, UnitPrice: 7.99, Total: 15.98},
+		{MenuItemID: "gone", Name: "gone", Quantity: 1},
+	}
+	if len(lines) != len(want) || lines[0] != want[0] || lines[1] != want[1] {
+		t.Errorf("lines = %+v, want %+v", lines, want)
+	}
+
+	failing := func(ctx context.Context, ids []string) (map[string]receipt.MenuItem, error) {
+		return nil, errors.New("menu is down")
+	}
+	named := []receipt.Line{{MenuItemID: "m1", Name: "Burger", Quantity: 1}}
+	if err := receipt.Resolve(context.Background(), named, failing); err != nil {
+		t.Errorf("named lines looked up: %v", err)
+	}
+	if err := receipt.Resolve(context.Background(), receipt.LinesFromIDs([]string{"m1"}), failing); err == nil {
+		t.Error("menu error was swallowed")
+	}
+}
+
+func TestStore_PDFIsReproducible(t *testing.T) {
+	r := store.Receipt(receipt.Order{
+		ID:        "o1",
+		CreatedAt: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC),
+		Lines:     []receipt.Line{{Name: "Crème brûlée with a name far too long for the item column of the table", Quantity: 1, UnitPrice: 5, Total: 5}},
+		Total:     5,
+	}, "John")
+
+	first, err := store.PDF(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(first, []byte("%PDF-")) {
+		t.Fatalf("not a PDF: %q", first[:min(len(first), 20)])
+	}
+	second, err := store.PDF(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Error("generating the same receipt twice gave different documents")
+	}
+
+	r.Fulfillment = &receipt.Fulfillment{Type: "pickup", Phone: "+77001234567", Notes: "No onions, please. " + strings.Repeat("Extra napkins. ", 12)}
+	withDetails, err := store.PDF(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(first, withDetails) {
+		t.Error("checkout details gave the same document")
+	}
+
+	r.DeliveryFee = 1
+	r.Total = 6
+	other, err := store.PDF(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(first, other) {
+		t.Error("a different total gave the same document")
+	}
+}
+
+// orderClient serves one order.
+type orderClient struct {
+	orderpb.OrderServiceClient
+	order *orderpb.Order
+}
+
+func (c orderClient) GetOrder(ctx context.Context, req *orderpb.GetOrderRequest, opts ...grpc.CallOption) (*orderpb.GetOrderResponse, error) {
+	if req.Id != c.order.Id {
+		return nil, status.Error(codes.NotFound, "order not found")
+	}
+	return &orderpb.GetOrderResponse{Order: c.order}, nil
+}
+
+func TestOrders_Receipt(t *testing.T) {
+	orders := &receipt.Orders{
+		Store: store,
+		Client: orderClient{order: &orderpb.Order{
+			Id:          "o1",
+			UserId:      "user1",
+			ItemIds:     []string{"m1", "m1"},
+			Discount:    1.6,
+			DeliveryFee: 2.5,
+			TotalPrice:  16.88,
+			CreatedAt:   "2026-01-01T12:00:00Z",
+			Fulfillment: &orderpb.Fulfillment{
+				Type:          "delivery",
+				Address:       "Abay 1, Almaty",
+				Phone:         "+77001234567",
+				RequestedTime: "2026-01-01T13:30:00Z",
+			},
+		}},
+		Menu: menu,
+		CustomerName: func(ctx context.Context, userID string) (string, error) {
+			return "John", nil
+		},
+	}
+	ctx := context.Background()
+
+	r, err := orders.Receipt(ctx, identity.Caller{UserID: "user1"}, "o1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.CustomerName != "John" || len(r.Lines) != 1 || r.Lines[0].Name != "Burger" || r.Lines[0].Quantity != 2 {
+		t.Errorf("receipt = %+v", r)
+	}
+	if r.Subtotal != 15.98 || r.Discount != 1.6 || r.DeliveryFee != 2.5 || r.Total != 16.88 {
+		t.Errorf("amounts = %v - %v + %v = %v, want 15.98 - 1.6 + 2.5 = 16.88", r.Subtotal, r.Discount, r.DeliveryFee, r.Total)
+	}
+	if !r.CreatedAt.Equal(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("created at = %v", r.CreatedAt)
+	}
+	if f := r.Fulfillment; f == nil || !f.Delivery() || f.Address != "Abay 1, Almaty" || f.Phone != "+77001234567" ||
+		!f.RequestedAt.Equal(time.Date(2026, 1, 1, 13, 30, 0, 0, time.UTC)) {
+		t.Errorf("fulfillment = %+v", r.Fulfillment)
+	}
+
+	if _, err := orders.Receipt(ctx, identity.Caller{UserID: "user2"}, "o1"); !errors.Is(err, receipt.ErrForbidden) {
+		t.Errorf("another user: err = %v", err)
+	}
+	if _, err := orders.Receipt(ctx, identity.Caller{UserID: "admin1", Role: identity.RoleAdmin}, "o1"); err != nil {
+		t.Errorf("admin: err = %v", err)
+	}
+	if _, err := orders.Receipt(ctx, identity.Caller{UserID: "user1"}, "o2"); !errors.Is(err, receipt.ErrOrderNotFound) {
+		t.Errorf("missing order: err = %v", err)
+	}
+}
+
+func TestOrders_ReceiptRejectsMalformedCreatedAt(t *testing.T) {
+	orders := &receipt.Orders{
+		Store: store,
+		Client: orderClient{order: &orderpb.Order{
+			Id:        "o1",
+			UserId:    "user1",
+			ItemIds:   []string{"m1"},
+			CreatedAt: "2026-01-01 12:00:00 +0000 UTC",
+		}},
+		Menu: menu,
+		CustomerName: func(ctx context.Context, userID string) (string, error) {
+			return "John", nil
+		},
+	}
+
+	if _, err := orders.Receipt(context.Background(), identity.Caller{UserID: "user1"}, "o1"); err == nil {
+		t.Error("a receipt dated 0001-01-01 was built")
+	}
+}
@@ -0,0 +1,54 @@
+package users
+
+import (
+	"sync"
+	"time"
+)
+
+type cacheEntry struct {
+	contact   Contact
+	fetchedAt time.Time
+}
+
+// cache keeps up to size contacts, dropping the oldest when it is full.
+type cache struct {
+	size int
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+func newCache(size int) *cache {
+	return &cache{size: size, entries: make(map[string]cacheEntry)}
+}
+
+func (c *cache) get(id string) (Contact, time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[id]
+	return e.contact, e.fetchedAt, ok
+}
+
+func (c *cache) put(id string, contact Contact) {
+	if c.size <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[id]; !ok && len(c.entries) >= c.size {
+		oldest := ""
+		for k, e := range c.entries {
+			if oldest == "" || e.fetchedAt.Before(c.entries[oldest].fetchedAt) {
+				oldest = k
+			}
+		}
+		delete(c.entries, oldest)
+	}
+	c.entries[id] = cacheEntry{contact: contact, fetchedAt: time.Now()}
+}
+
+func (c *cache) remove(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, id)
+}
@@ -0,0 +1,177 @@
+// Package users looks up who to mail in UserService. Lookups have deadlines
+// and are retried, a circuit breaker stops calling a UserService that keeps
+// failing, and contact details are cached so emails keep going out during
+// short outages.
+package users
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	userpb "payment/proto/user"
+)
+
+// SubjectUserUpdated is published by UserService when a profile changes or
+// an account is deleted.
+const SubjectUserUpdated = "user.updated"
+
+var (
+	ErrNotFound = errors.New("user not found")
+	// ErrUnavailable is returned while the circuit breaker is open and
+	// there is no cached copy of the user.
+	ErrUnavailable = errors.New("user service unavailable")
+)
+
+// Contact is what emails need to know about a user.
+type Contact struct {
+	Email  string
+	Name   string
+	Locale string
+}
+
+// Config tunes how UserService is called.
+type Config struct {
+	// Timeout is the deadline of a single GetUser call.
+	Timeout time.Duration
+	// Attempts is how many times a call that failed with Unavailable or
+	// hit its deadline is made. Retries wait a random time up to
+	// RetryDelay, doubled for every earlier attempt.
+	Attempts   int
+	RetryDelay time.Duration
+	// After BreakerThreshold lookups in a row failed, UserService is not
+	// called for BreakerCooldown.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+	// CacheTTL is how long a user is served from the cache. Older entries
+	// are only used while UserService is down. At most CacheSize users are
+	// kept.
+	CacheTTL  time.Duration
+	CacheSize int
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Timeout:          2 * time.Second,
+		Attempts:         3,
+		RetryDelay:       100 * time.Millisecond,
+		BreakerThreshold: 5,
+		BreakerCooldown:  30 * time.Second,
+		CacheTTL:         5 * time.Minute,
+		CacheSize:        1000,
+	}
+}
+
+type Client struct {
+	users   userpb.UserServiceClient
+	cfg     Config
+	breaker *breaker
+	cache   *cache
+}
+
+func NewClient(users userpb.UserServiceClient, cfg Config) *Client {
+	return &Client{
+		users:   users,
+		cfg:     cfg,
+		breaker: &breaker{threshold: cfg.BreakerThreshold, cooldown: cfg.BreakerCooldown},
+		cache:   newCache(cfg.CacheSize),
+	}
+}
+
+// Get returns the contact details of user id. A fresh cached copy is used
+// without asking UserService; a stale one only when UserService cannot be
+// reached. A user that does not exist fails with ErrNotFound.
+func (c *Client) Get(ctx context.Context, id string) (Contact, error) {
+	cached, fetchedAt, ok := c.cache.get(id)
+	if ok && time.Since(fetchedAt) < c.cfg.CacheTTL {
+		return cached, nil
+	}
+
+	contact, err := c.fetch(ctx, id)
+	switch {
+	case err == nil:
+		c.cache.put(id, contact)
+		return contact, nil
+	case errors.Is(err, ErrNotFound):
+		c.cache.remove(id)
+		return Contact{}, err
+	case ok:
+		slog.WarnContext(ctx, "user service unavailable, using cached user", "user_id", id,
+			"age", time.Since(fetchedAt).Round(time.Second), "err", err)
+		return cached, nil
+	}
+	return Contact{}, err
+}
+
+// fetch calls UserService through the breaker, retrying transient failures.
+func (c *Client) fetch(ctx context.Context, id string) (Contact, error) {
+	if !c.breaker.allow() {
+		return Contact{}, ErrUnavailable
+	}
+	var err error
+	for attempt := 1; ; attempt++ {
+		var res *userpb.GetUserResponse
+		callCtx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+		res, err = c.users.GetUser(callCtx, &userpb.GetUserRequest{Id: id})
+		cancel()
+
+		if err == nil {
+			c.breaker.success()
+			return Contact{Email: res.User.Email, Name: res.User.Username, Locale: res.User.Locale}, nil
+		}
+		if !transient(err) {
+			// UserService answered, so it is healthy; only outages count
+			// toward the breaker.
+			c.breaker.success()
+			if status.Code(err) == codes.NotFound {
+				return Contact{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+			}
+			return Contact{}, fmt.Errorf("get user %s: %w", id, err)
+		}
+		if attempt >= c.cfg.Attempts || ctx.Err() != nil {
+			break
+		}
+
+		delay := rand.N(c.cfg.RetryDelay<<(attempt-1) + 1)
+		slog.WarnContext(ctx, "user lookup failed, retrying", "user_id", id, "attempt", attempt, "delay", delay, "err", err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+		}
+	}
+	c.breaker.failure()
+	return Contact{}, fmt.Errorf("get user %s: %w", id, err)
+}
+
+// transient reports whether a failed call is worth repeating.
+func transient(err error) bool {
+	code := status.Code(err)
+	return code == codes.Unavailable || code == codes.DeadlineExceeded
+}
+
+// Invalidate drops the cached copy of user id.
+func (c *Client) Invalidate(id string) {
+	c.cache.remove(id)
+}
+
+// HandleUserUpdated drops the cached copy of the user a user.updated event
+// is about. Events are not guaranteed to arrive, so CacheTTL still bounds
+// how long a changed address can be used.
+func (c *Client) HandleUserUpdated(m *nats.Msg) {
+	var evt struct {
+		UserID string `json:"userId"`
+	}
+	if err := json.Unmarshal(m.Data, &evt); err != nil || evt.UserID == "" {
+		slog.Error("invalid event", "subject", m.Subject, "err", err)
+		return
+	}
+	c.Invalidate(evt.UserID)
+}
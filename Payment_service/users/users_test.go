@@ -0,0 +1,234 @@
+package users_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	userpb "payment/proto/user"
+	"payment/users"
+)
+
+// userClient answers GetUser with the errors in fail, one per call, and then
+// with the user.
+type userClient struct {
+	userpb.UserServiceClient
+
+	mu    sync.Mutex
+	calls int
+	fail  []error
+	user  *userpb.User
+	hang  bool
+}
+
+func (c *userClient) GetUser(ctx context.Context, in *userpb.GetUserRequest, opts ...grpc.CallOption) (*userpb.GetUserResponse, error) {
+	c.mu.Lock()
+	c.calls++
+	hang := c.hang
+	var err error
+	if len(c.fail) > 0 {
+		err, c.fail = c.fail[0], c.fail[1:]
+	}
+	user := c.user
+	c.mu.Unlock()
+
+	if hang {
+		<-ctx.Done()
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.Id != in.Id {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	return &userpb.GetUserResponse{User: user}, nil
+}
+
+func (c *userClient) set(fn func(c *userClient)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fn(c)
+}
+
+func (c *userClient) callCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls
+}
+
+var (
+	unavailable = status.Error(codes.Unavailable, "connection refused")
+	john        = &userpb.User{Id: "user1", Username: "John", Email: "john@example.com", Locale: "ru"}
+)
+
+func testConfig() users.Config {
+	return users.Config{
+		Timeout:          time.Second,
+		Attempts:         3,
+		RetryDelay:       time.Millisecond,
+		BreakerThreshold: 2,
+		BreakerCooldown:  50 * time.Millisecond,
+		CacheTTL:         time.Minute,
+		CacheSize:        10,
+	}
+}
+
+func TestClient_RetriesUnavailable(t *testing.T) {
+	client := &userClient{user: john, fail: []error{unavailable, unavailable}}
+	c := users.NewClient(client, testConfig())
+
+	contact, err := c.Get(context.Background(), "user1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contact != (users.Contact{Email: "john@example.com", Name: "John", Locale: "ru"}) {
+		t.Errorf("contact = %+v", contact)
+	}
+	if n := client.callCount(); n != 3 {
+		t.Errorf("calls = %d, want 3", n)
+	}
+
+	client.set(func(c *userClient) { c.fail = []error{status.Error(codes.Internal, "boom")} })
+	c.Invalidate("user1")
+	if _, err := c.Get(context.Background(), "user1"); status.Code(errors.Unwrap(err)) != codes.Internal {
+		t.Errorf("err = %v, want the Internal error without retries", err)
+	}
+	if n := client.callCount(); n != 4 {
+		t.Errorf("calls = %d, want 4", n)
+	}
+}
+
+func TestClient_CachesAndServesStaleDuringOutage(t *testing.T) {
+	client := &userClient{user: john}
+	cfg := testConfig()
+	cfg.CacheTTL = 20 * time.Millisecond
+	c := users.NewClient(client, cfg)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := c.Get(ctx, "user1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := client.callCount(); n != 1 {
+		t.Errorf("calls = %d, want 1 while cached", n)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	client.set(func(c *userClient) { c.fail = []error{unavailable, unavailable, unavailable} })
+	contact, err := c.Get(ctx, "user1")
+	if err != nil || contact.Email != "john@example.com" {
+		t.Errorf("during an outage: %+v, %v, want the cached user", contact, err)
+	}
+	if _, err := c.Get(ctx, "user2"); err == nil {
+		t.Error("an uncached user was found during an outage")
+	}
+}
+
+func TestClient_NotFound(t *testing.T) {
+	client := &userClient{user: john}
+	c := users.NewClient(client, testConfig())
+	ctx := context.Background()
+
+	if _, err := c.Get(ctx, "user1"); err != nil {
+		t.Fatal(err)
+	}
+	client.set(func(c *userClient) { c.user = nil })
+	c.Invalidate("user1")
+	if _, err := c.Get(ctx, "user1"); !errors.Is(err, users.ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound", err)
+	}
+	if n := client.callCount(); n != 2 {
+		t.Errorf("calls = %d, want NotFound not to be retried", n)
+	}
+}
+
+func TestClient_BreakerOpensAndRecovers(t *testing.T) {
+	client := &userClient{user: john, fail: []error{unavailable, unavailable, unavailable, unavailable, unavailable, unavailable}}
+	c := users.NewClient(client, testConfig())
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.Get(ctx, "user1"); err == nil {
+			t.Fatal("lookup succeeded during an outage")
+		}
+	}
+	calls := client.callCount()
+	if _, err := c.Get(ctx, "user1"); !errors.Is(err, users.ErrUnavailable) {
+		t.Errorf("err = %v, want ErrUnavailable while the breaker is open", err)
+	}
+	if client.callCount() != calls {
+		t.Error("UserService was called while the breaker was open")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if _, err := c.Get(ctx, "user1"); err != nil {
+		t.Errorf("after the cooldown: %v", err)
+	}
+}
+
+func TestClient_AnsweredErrorsKeepBreakerClosed(t *testing.T) {
+	internal := status.Error(codes.Internal, "boom")
+	client := &userClient{user: john, fail: []error{internal, internal, internal}}
+	c := users.NewClient(client, testConfig())
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := c.Get(ctx, "user1"); status.Code(errors.Unwrap(err)) != codes.Internal {
+			t.Fatalf("err = %v, want the Internal error", err)
+		}
+	}
+	if _, err := c.Get(ctx, "user1"); err != nil {
+		t.Errorf("err = %v, want the breaker to stay closed", err)
+	}
+}
+
+func TestClient_CallDeadline(t *testing.T) {
+	client := &userClient{user: john, hang: true}
+	cfg := testConfig()
+	cfg.Timeout = 20 * time.Millisecond
+	cfg.Attempts = 2
+	c := users.NewClient(client, cfg)
+
+	start := time.Now()
+	_, err := c.Get(context.Background(), "user1")
+	if status.Code(errors.Unwrap(err)) != codes.DeadlineExceeded {
+		t.Errorf("err = %v, want DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("lookup took %v", elapsed)
+	}
+	if n := client.callCount(); n != 2 {
+		t.Errorf("calls = %d, want 2", n)
+	}
+}
+
+func TestClient_HandleUserUpdated(t *testing.T) {
+	client := &userClient{user: john}
+	c := users.NewClient(client, testConfig())
+	ctx := context.Background()
+
+	if _, err := c.Get(ctx, "user1"); err != nil {
+		t.Fatal(err)
+	}
+	client.set(func(c *userClient) {
+		c.user = &userpb.User{Id: "user1", Username: "John", Email: "new@example.com"}
+	})
+	c.HandleUserUpdated(&nats.Msg{Subject: users.SubjectUserUpdated, Data: []byte(`{"userId":"user1"}`)})
+
+	contact, err := c.Get(ctx, "user1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contact.Email != "new@example.com" {
+		t.Errorf("email = %q after user.updated, want the new one", contact.Email)
+	}
+}
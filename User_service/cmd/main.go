@@ -1,35 +1,86 @@
 package main
 
 import (
-	"fmt"
-	"log"
+	"foodstore/logging"
+	"log/slog"
 	"net"
 	"user/config"
+	"user/internal/auth"
 	"user/internal/dao"
 	"user/internal/handler"
+	"user/internal/migration"
+	"user/internal/nats"
 	"user/internal/service"
 	pb "user/proto"
+	orderpb "user/proto/order"
 
+	"github.com/go-redis/redis/v8"
 	"google.golang.org/grpc"
 )
 
 func main() {
+	logging.Setup("user")
 	cfg := config.LoadConfig()
 
+	if cfg.JWTHMACKeys == "" && cfg.JWTPrivateKeyFile == "" {
+		slog.Warn("no JWT keys configured, using the insecure development key")
+	} else {
+		keys, err := auth.NewKeySet(auth.KeyConfig{
+			Algorithm:      cfg.JWTAlgorithm,
+			KeyID:          cfg.JWTKeyID,
+			HMACKeys:       cfg.JWTHMACKeys,
+			PrivateKeyFile: cfg.JWTPrivateKeyFile,
+			PublicKeysDir:  cfg.JWTPublicKeysDir,
+		})
+		if err != nil {
+			logging.Fatal("failed to load JWT keys", "err", err)
+		}
+		auth.SetKeySet(keys)
+	}
+
+	auth.AccessTokenTTL = cfg.AccessTokenTTL
+
 	db := config.ConnectToMongo(cfg.MongoURI, cfg.DatabaseName)
+	if err := migrations.Run(db); err != nil {
+		logging.Fatal("failed to migrate the users collection", "err", err)
+	}
+	cache := redis.NewClient(&redis.Options{
+		Addr: cfg.RedisAddr,
+	})
+
 	repo := dao.NewUserRepository(db)
-	svc := service.NewUserService(repo)
+	tokens := dao.NewTokenStore(cache)
+
+	orderConn, err := grpc.Dial(cfg.OrderServiceAddr, grpc.WithInsecure(),
+		grpc.WithUnaryInterceptor(logging.UnaryClientInterceptor()))
+	if err != nil {
+		logging.Fatal("failed to connect to OrderService", "err", err)
+	}
+	natsPublisher, err := nats.NewPublisher(cfg.NatsURL)
+	if err != nil {
+		logging.Fatal("failed to connect to NATS", "err", err)
+	}
+	defer natsPublisher.Close()
+
+	attempts := dao.NewLoginAttempts(cache)
+	svc := service.NewUserService(repo, tokens, attempts, orderpb.NewOrderServiceClient(orderConn), natsPublisher, service.Config{
+		RefreshTokenTTL: cfg.RefreshTokenTTL,
+		ResetTokenTTL:   cfg.ResetTokenTTL,
+		ResetURL:        cfg.ResetURL,
+		EmailLogin:      service.DefaultEmailLoginPolicy,
+		IPLogin:         service.DefaultIPLoginPolicy,
+	})
 
 	lis, err := net.Listen("tcp", ":50052")
 	if err != nil {
-		log.Fatalf("Failed to listen: %v", err)
+		logging.Fatal("failed to listen", "err", err)
 	}
 
-	grpcServer := grpc.NewServer()
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(logging.UnaryServerInterceptor()))
 	pb.RegisterUserServiceServer(grpcServer, handler.NewUserHandler(svc))
 
-	fmt.Println("UserService started on :50052")
+	slog.Info("UserService started", "addr", ":50052")
 	if err := grpcServer.Serve(lis); err != nil {
-		log.Fatalf("gRPC server error: %v", err)
+		logging.Fatal("gRPC server error", "err", err)
 	}
 }
@@ -2,7 +2,8 @@ package config
 
 import (
 	"context"
-	"log"
+	"foodstore/logging"
+	"log/slog"
 	"os"
 	"time"
 
@@ -11,17 +12,54 @@ import (
 )
 
 type Config struct {
-	MongoURI     string
-	DatabaseName string
+	MongoURI          string
+	DatabaseName      string
+	JWTAlgorithm      string
+	JWTKeyID          string
+	JWTHMACKeys       string
+	JWTPrivateKeyFile string
+	JWTPublicKeysDir  string
+	AccessTokenTTL    time.Duration
+	RefreshTokenTTL   time.Duration
+	RedisAddr         string
+	OrderServiceAddr  string
+	NatsURL           string
+	ResetTokenTTL     time.Duration
+	ResetURL          string
 }
 
 func LoadConfig() *Config {
 	return &Config{
-		MongoURI:     getEnv("MONGO_URI", "mongodb://localhost:27017"),
-		DatabaseName: getEnv("MONGO_DB", "userservice"),
+		MongoURI:          getEnv("MONGO_URI", "mongodb://localhost:27017"),
+		DatabaseName:      getEnv("MONGO_DB", "userservice"),
+		JWTAlgorithm:      getEnv("JWT_ALG", "HS256"),
+		JWTKeyID:          getEnv("JWT_KID", ""),
+		JWTHMACKeys:       getEnv("JWT_HMAC_KEYS", ""),
+		JWTPrivateKeyFile: getEnv("JWT_PRIVATE_KEY_FILE", ""),
+		JWTPublicKeysDir:  getEnv("JWT_PUBLIC_KEYS_DIR", ""),
+		AccessTokenTTL:    getDuration("JWT_ACCESS_TTL", 15*time.Minute),
+		RefreshTokenTTL:   getDuration("JWT_REFRESH_TTL", 7*24*time.Hour),
+		RedisAddr:         getEnv("REDIS_ADDR", "localhost:6379"),
+		OrderServiceAddr:  getEnv("ORDER_SERVICE_ADDR", "localhost:50053"),
+		NatsURL:           getEnv("NATS_URL", "nats://localhost:4222"),
+		ResetTokenTTL:     getDuration("PASSWORD_RESET_TTL", 30*time.Minute),
+		ResetURL:          getEnv("PASSWORD_RESET_URL", "http://localhost:8082/reset-password.html"),
 	}
 }
 
+func getDuration(key string, defaultValue time.Duration) time.Duration {
+	val, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(val)
+	if err != nil {
+		slog.Warn("invalid duration, using default", "key", key, "value", val, "default", defaultValue.String())
+		return defaultValue
+	}
+	return d
+}
+
 func getEnv(key, defaultValue string) string {
 	if val, exists := os.LookupEnv(key); exists {
 		return val
@@ -36,13 +74,13 @@ func ConnectToMongo(uri string, dbName string) *mongo.Database {
 	clientOptions := options.Client().ApplyURI(uri)
 	client, err := mongo.Connect(ctx, clientOptions)
 	if err != nil {
-		log.Fatalf("Failed to connect to MongoDB: %v", err)
+		logging.Fatal("failed to connect to MongoDB", "err", err)
 	}
 
 	if err := client.Ping(ctx, nil); err != nil {
-		log.Fatalf("Failed to ping MongoDB: %v", err)
+		logging.Fatal("failed to ping MongoDB", "err", err)
 	}
 
-	log.Println("Connected to MongoDB:", dbName)
+	slog.Info("connected to MongoDB", "database", dbName)
 	return client.Database(dbName)
 }
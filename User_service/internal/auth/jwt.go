@@ -1,11 +1,23 @@
 package auth
 
 import (
+	"crypto/rand"
+	"encoding/base64"
 	"github.com/golang-jwt/jwt/v5"
 	"time"
 )
 
-var jwtKey = []byte("super_secret_key")
+var keys = NewDevKeySet()
+
+// AccessTokenTTL is how long access tokens issued by GenerateToken are valid.
+// Sessions are extended with refresh tokens instead of long-lived JWTs.
+var AccessTokenTTL = 15 * time.Minute
+
+// SetKeySet replaces the development key set used by GenerateToken and
+// ParseToken with the configured one.
+func SetKeySet(ks *KeySet) {
+	keys = ks
+}
 
 type Claims struct {
 	UserID string `json:"user_id"`
@@ -14,26 +26,39 @@ type Claims struct {
 }
 
 func GenerateToken(userID, role string) (string, error) {
+	jti, err := RandomToken(16)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
 	claims := &Claims{
 		UserID: userID,
 		Role:   role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			ID:        jti,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenTTL)),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtKey)
+	return keys.Sign(claims)
 }
 
 func ParseToken(tokenStr string) (*Claims, error) {
 	claims := &Claims{}
-	token, err := jwt.ParseWithClaims(tokenStr, claims, func(t *jwt.Token) (interface{}, error) {
-		return jwtKey, nil
-	})
+	token, err := keys.Parse(tokenStr, claims)
 
 	if err != nil || !token.Valid {
 		return nil, err
 	}
 	return claims, nil
 }
+
+// RandomToken returns n random bytes encoded as URL-safe base64.
+func RandomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,195 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DefaultKeyID is the kid assumed for tokens issued before kid headers were
+// added, and for the development key used when nothing is configured.
+const DefaultKeyID = "default"
+
+const devSecret = "super_secret_key"
+
+// KeyConfig describes where signing and verification keys come from.
+//
+// For HS256, HMACKeys holds comma separated kid=secret pairs; every pair is
+// accepted for verification and KeyID picks the one used for signing. For
+// RS256 and EdDSA, PrivateKeyFile is a PEM private key signed with as KeyID,
+// and PublicKeysDir may hold additional <kid>.pem public keys that are still
+// accepted during a rotation.
+type KeyConfig struct {
+	Algorithm      string
+	KeyID          string
+	HMACKeys       string
+	PrivateKeyFile string
+	PublicKeysDir  string
+}
+
+// KeySet signs tokens with one active key and verifies them with any key it
+// knows, selected by the kid header.
+type KeySet struct {
+	method     jwt.SigningMethod
+	signingKID string
+	signingKey interface{}
+	verifyKeys map[string]interface{}
+}
+
+func NewKeySet(cfg KeyConfig) (*KeySet, error) {
+	alg := strings.ToUpper(cfg.Algorithm)
+	if alg == "" {
+		alg = "HS256"
+	}
+
+	ks := &KeySet{verifyKeys: map[string]interface{}{}}
+	switch alg {
+	case "HS256":
+		ks.method = jwt.SigningMethodHS256
+		if err := ks.loadHMACKeys(cfg); err != nil {
+			return nil, err
+		}
+	case "RS256":
+		ks.method = jwt.SigningMethodRS256
+		if err := ks.loadAsymmetricKeys(cfg); err != nil {
+			return nil, err
+		}
+	case "EDDSA":
+		ks.method = jwt.SigningMethodEdDSA
+		if err := ks.loadAsymmetricKeys(cfg); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", cfg.Algorithm)
+	}
+	return ks, nil
+}
+
+// NewDevKeySet returns the HS256 development key set that matches the secret
+// the services used before keys became configurable.
+func NewDevKeySet() *KeySet {
+	return &KeySet{
+		method:     jwt.SigningMethodHS256,
+		signingKID: DefaultKeyID,
+		signingKey: []byte(devSecret),
+		verifyKeys: map[string]interface{}{DefaultKeyID: []byte(devSecret)},
+	}
+}
+
+func (k *KeySet) loadHMACKeys(cfg KeyConfig) error {
+	if cfg.HMACKeys == "" {
+		return errors.New("JWT_HMAC_KEYS is required for HS256")
+	}
+	var first string
+	for _, pair := range strings.Split(cfg.HMACKeys, ",") {
+		kid, secret, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || kid == "" || secret == "" {
+			return fmt.Errorf("invalid JWT_HMAC_KEYS entry %q, want kid=secret", pair)
+		}
+		if first == "" {
+			first = kid
+		}
+		k.verifyKeys[kid] = []byte(secret)
+	}
+
+	k.signingKID = cfg.KeyID
+	if k.signingKID == "" {
+		k.signingKID = first
+	}
+	key, ok := k.verifyKeys[k.signingKID]
+	if !ok {
+		return fmt.Errorf("JWT_KID %q is not one of JWT_HMAC_KEYS", k.signingKID)
+	}
+	k.signingKey = key
+	return nil
+}
+
+func (k *KeySet) loadAsymmetricKeys(cfg KeyConfig) error {
+	if cfg.PrivateKeyFile == "" {
+		return fmt.Errorf("JWT_PRIVATE_KEY_FILE is required for %s", k.method.Alg())
+	}
+	if cfg.KeyID == "" {
+		return errors.New("JWT_KID is required when signing with a private key")
+	}
+	data, err := os.ReadFile(cfg.PrivateKeyFile)
+	if err != nil {
+		return fmt.Errorf("read private key: %w", err)
+	}
+
+	var public crypto.PublicKey
+	if k.method == jwt.SigningMethodRS256 {
+		key, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+		if err != nil {
+			return fmt.Errorf("parse RSA private key: %w", err)
+		}
+		k.signingKey, public = key, &key.PublicKey
+	} else {
+		key, err := jwt.ParseEdPrivateKeyFromPEM(data)
+		if err != nil {
+			return fmt.Errorf("parse Ed25519 private key: %w", err)
+		}
+		k.signingKey, public = key, key.(ed25519.PrivateKey).Public()
+	}
+	k.signingKID = cfg.KeyID
+
+	if cfg.PublicKeysDir != "" {
+		if err := k.loadPublicKeys(cfg.PublicKeysDir); err != nil {
+			return err
+		}
+	}
+	k.verifyKeys[k.signingKID] = public
+	return nil
+}
+
+// loadPublicKeys reads every <kid>.pem file in dir as a verification key.
+func (k *KeySet) loadPublicKeys(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("read public key %s: %w", file, err)
+		}
+		var key interface{}
+		if k.method == jwt.SigningMethodRS256 {
+			key, err = jwt.ParseRSAPublicKeyFromPEM(data)
+		} else {
+			key, err = jwt.ParseEdPublicKeyFromPEM(data)
+		}
+		if err != nil {
+			return fmt.Errorf("parse public key %s: %w", file, err)
+		}
+		k.verifyKeys[strings.TrimSuffix(filepath.Base(file), ".pem")] = key
+	}
+	return nil
+}
+
+func (k *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.method, claims)
+	token.Header["kid"] = k.signingKID
+	return token.SignedString(k.signingKey)
+}
+
+func (k *KeySet) Parse(tokenStr string, claims jwt.Claims) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenStr, claims, k.keyFunc, jwt.WithValidMethods([]string{k.method.Alg()}))
+}
+
+func (k *KeySet) keyFunc(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+	if kid == "" {
+		kid = DefaultKeyID
+	}
+	key, ok := k.verifyKeys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	return key, nil
+}
@@ -2,13 +2,23 @@ package dao
 
 import (
 	"context"
-	"fmt"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"user/internal/model"
 )
 
+// UserStore keeps user accounts. Lookups and updates of a user that does not
+// exist fail with mongo.ErrNoDocuments.
+type UserStore interface {
+	CreateUser(ctx context.Context, user model.User) (string, error)
+	GetUserByEmail(ctx context.Context, email string) (*model.User, error)
+	GetUserByID(ctx context.Context, id string) (*model.User, error)
+	UpdateProfile(ctx context.Context, user model.User) error
+	UpdatePassword(ctx context.Context, id string, hashedPassword string) error
+	DeleteUser(ctx context.Context, id string) error
+}
+
 type UserRepository struct {
 	Collection *mongo.Collection
 }
@@ -39,7 +49,7 @@ func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (*mod
 func (r *UserRepository) GetUserByID(ctx context.Context, id string) (*model.User, error) {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return nil, fmt.Errorf("invalid ObjectID: %v", err)
+		return nil, mongo.ErrNoDocuments
 	}
 
 	var user model.User
@@ -50,3 +60,48 @@ func (r *UserRepository) GetUserByID(ctx context.Context, id string) (*model.Use
 
 	return &user, nil
 }
+
+// UpdateProfile stores the editable fields of user; an empty Locale leaves the
+// stored locale alone.
+func (r *UserRepository) UpdateProfile(ctx context.Context, user model.User) error {
+	fields := bson.M{
+		"username":  user.Username,
+		"email":     user.Email,
+		"phone":     user.Phone,
+		"addresses": user.Addresses,
+	}
+	if user.Locale != "" {
+		fields["locale"] = user.Locale
+	}
+	return r.updateByID(ctx, user.ID, fields)
+}
+
+func (r *UserRepository) UpdatePassword(ctx context.Context, id string, hashedPassword string) error {
+	return r.updateByID(ctx, id, bson.M{"password": hashedPassword})
+}
+
+func (r *UserRepository) DeleteUser(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return mongo.ErrNoDocuments
+	}
+
+	res, err := r.Collection.DeleteOne(ctx, bson.M{"_id": objID})
+	if err == nil && res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return err
+}
+
+func (r *UserRepository) updateByID(ctx context.Context, id string, set bson.M) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return mongo.ErrNoDocuments
+	}
+
+	res, err := r.Collection.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": set})
+	if err == nil && res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return err
+}
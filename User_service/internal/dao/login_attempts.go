@@ -0,0 +1,63 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// LoginAttempts counts failed logins per key (an email or a client IP) and
+// keeps the locks derived from them. The policy lives in the service.
+type LoginAttempts struct {
+	Cache *redis.Client
+}
+
+func NewLoginAttempts(cache *redis.Client) *LoginAttempts {
+	return &LoginAttempts{Cache: cache}
+}
+
+func failuresKey(key string) string {
+	return "login:fail:" + key
+}
+
+func lockKey(key string) string {
+	return "login:lock:" + key
+}
+
+// LockedFor returns how long the longest lock among keys still lasts.
+func (a *LoginAttempts) LockedFor(ctx context.Context, keys ...string) (time.Duration, error) {
+	var longest time.Duration
+	for _, key := range keys {
+		ttl, err := a.Cache.PTTL(ctx, lockKey(key)).Result()
+		if err != nil {
+			return 0, err
+		}
+		if ttl > longest {
+			longest = ttl
+		}
+	}
+	return longest, nil
+}
+
+// AddFailure counts a failed login for key and returns the failures within
+// window, which starts at the first failure.
+func (a *LoginAttempts) AddFailure(ctx context.Context, key string, window time.Duration) (int64, error) {
+	count, err := a.Cache.Incr(ctx, failuresKey(key)).Result()
+	if err != nil {
+		return 0, err
+	}
+	if count == 1 {
+		err = a.Cache.Expire(ctx, failuresKey(key), window).Err()
+	}
+	return count, err
+}
+
+func (a *LoginAttempts) Lock(ctx context.Context, key string, d time.Duration) error {
+	return a.Cache.Set(ctx, lockKey(key), 1, d).Err()
+}
+
+// Reset forgets the failures and lock of key.
+func (a *LoginAttempts) Reset(ctx context.Context, key string) error {
+	return a.Cache.Del(ctx, failuresKey(key), lockKey(key)).Err()
+}
@@ -0,0 +1,83 @@
+package dao_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+
+	"user/internal/dao"
+)
+
+func newLoginAttempts(t *testing.T) (*dao.LoginAttempts, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return dao.NewLoginAttempts(client), mr
+}
+
+func TestLoginAttempts_CountsFailuresWithinWindow(t *testing.T) {
+	attempts, mr := newLoginAttempts(t)
+	ctx := context.Background()
+
+	for want := int64(1); want <= 3; want++ {
+		got, err := attempts.AddFailure(ctx, "email:john@example.com", time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("failures = %d, want %d", got, want)
+		}
+		// The window starts at the first failure and is not extended.
+		mr.FastForward(10 * time.Second)
+	}
+
+	mr.FastForward(30 * time.Second)
+	got, err := attempts.AddFailure(ctx, "email:john@example.com", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 1 {
+		t.Errorf("failures after the window = %d, want 1", got)
+	}
+}
+
+func TestLoginAttempts_LockAndReset(t *testing.T) {
+	attempts, mr := newLoginAttempts(t)
+	ctx := context.Background()
+	email, ip := "email:john@example.com", "ip:10.0.0.1"
+
+	if d, err := attempts.LockedFor(ctx, email, ip); err != nil || d != 0 {
+		t.Fatalf("LockedFor = %s, %v before any lock", d, err)
+	}
+	if err := attempts.Lock(ctx, email, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := attempts.Lock(ctx, ip, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if d, err := attempts.LockedFor(ctx, email, ip); err != nil || d != time.Minute {
+		t.Errorf("LockedFor = %s, %v, want the longest lock of 1m", d, err)
+	}
+
+	mr.FastForward(time.Second)
+	if d, err := attempts.LockedFor(ctx, email); err != nil || d != 0 {
+		t.Errorf("LockedFor = %s, %v after the lock expired", d, err)
+	}
+
+	if _, err := attempts.AddFailure(ctx, ip, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := attempts.Reset(ctx, ip); err != nil {
+		t.Fatal(err)
+	}
+	if d, err := attempts.LockedFor(ctx, ip); err != nil || d != 0 {
+		t.Errorf("LockedFor = %s, %v after Reset", d, err)
+	}
+	if got, err := attempts.AddFailure(ctx, ip, time.Hour); err != nil || got != 1 {
+		t.Errorf("failures after Reset = %d, %v, want 1", got, err)
+	}
+}
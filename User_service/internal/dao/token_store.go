@@ -0,0 +1,162 @@
+package dao
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+var (
+	ErrRefreshTokenNotFound = errors.New("refresh token not found or expired")
+	ErrResetTokenNotFound   = errors.New("reset token not found or expired")
+)
+
+// RefreshSession is what a refresh token stands for.
+type RefreshSession struct {
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+// TokenStore keeps refresh tokens and the access token revocation list in
+// Redis. The API gateway reads the revoked:* keys, so their layout is shared
+// with it.
+type TokenStore struct {
+	Cache *redis.Client
+}
+
+func NewTokenStore(cache *redis.Client) *TokenStore {
+	return &TokenStore{Cache: cache}
+}
+
+// Refresh tokens are stored by hash so a Redis dump does not leak usable tokens.
+func refreshKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return "refresh:" + hex.EncodeToString(sum[:])
+}
+
+func userRefreshKey(userID string) string {
+	return "refresh:user:" + userID
+}
+
+func (s *TokenStore) SaveRefreshToken(ctx context.Context, token string, session RefreshSession, ttl time.Duration) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	key := refreshKey(token)
+
+	pipe := s.Cache.TxPipeline()
+	pipe.Set(ctx, key, data, ttl)
+	pipe.SAdd(ctx, userRefreshKey(session.UserID), key)
+	pipe.Expire(ctx, userRefreshKey(session.UserID), ttl)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// GetRefreshToken returns the session of a refresh token without using it up.
+func (s *TokenStore) GetRefreshToken(ctx context.Context, token string) (*RefreshSession, error) {
+	data, err := s.Cache.Get(ctx, refreshKey(token)).Result()
+	if err == redis.Nil {
+		return nil, ErrRefreshTokenNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var session RefreshSession
+	if err := json.Unmarshal([]byte(data), &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// TakeRefreshToken returns the session of a refresh token and deletes it, so
+// every refresh token can be used only once.
+func (s *TokenStore) TakeRefreshToken(ctx context.Context, token string) (*RefreshSession, error) {
+	key := refreshKey(token)
+	data, err := s.Cache.GetDel(ctx, key).Result()
+	if err == redis.Nil {
+		return nil, ErrRefreshTokenNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var session RefreshSession
+	if err := json.Unmarshal([]byte(data), &session); err != nil {
+		return nil, err
+	}
+	s.Cache.SRem(ctx, userRefreshKey(session.UserID), key)
+	return &session, nil
+}
+
+// RevokeAccessToken puts a token id on the revocation list until the token
+// would have expired anyway.
+func (s *TokenStore) RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if jti == "" || ttl <= 0 {
+		return nil
+	}
+	return s.Cache.Set(ctx, "revoked:jti:"+jti, 1, ttl).Err()
+}
+
+// RevokeUserSessions deletes every refresh token of a user and rejects all of
+// their access tokens issued up to now. accessTTL bounds how long the marker
+// has to be kept.
+func (s *TokenStore) RevokeUserSessions(ctx context.Context, userID string, accessTTL time.Duration) error {
+	keys, err := s.Cache.SMembers(ctx, userRefreshKey(userID)).Result()
+	if err != nil {
+		return err
+	}
+
+	pipe := s.Cache.TxPipeline()
+	if len(keys) > 0 {
+		pipe.Del(ctx, keys...)
+	}
+	pipe.Del(ctx, userRefreshKey(userID))
+	pipe.Set(ctx, "revoked:user:"+userID, strconv.FormatInt(time.Now().Unix(), 10), accessTTL)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func resetKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return "reset:" + hex.EncodeToString(sum[:])
+}
+
+// SaveResetToken stores a password reset token for userID. Only the newest
+// token of a user is valid; requesting another one invalidates the previous.
+func (s *TokenStore) SaveResetToken(ctx context.Context, token, userID string, ttl time.Duration) error {
+	userKey := "reset:user:" + userID
+	previous, err := s.Cache.Get(ctx, userKey).Result()
+	if err != nil && err != redis.Nil {
+		return err
+	}
+
+	pipe := s.Cache.TxPipeline()
+	if previous != "" {
+		pipe.Del(ctx, previous)
+	}
+	pipe.Set(ctx, resetKey(token), userID, ttl)
+	pipe.Set(ctx, userKey, resetKey(token), ttl)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// TakeResetToken returns the user a reset token was issued to and deletes it.
+func (s *TokenStore) TakeResetToken(ctx context.Context, token string) (string, error) {
+	userID, err := s.Cache.GetDel(ctx, resetKey(token)).Result()
+	if err == redis.Nil {
+		return "", ErrResetTokenNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	s.Cache.Del(ctx, "reset:user:"+userID)
+	return userID, nil
+}
@@ -0,0 +1,102 @@
+package dao_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+
+	"user/internal/dao"
+)
+
+func newTokenStore(t *testing.T) (*dao.TokenStore, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return dao.NewTokenStore(client), mr
+}
+
+func TestTokenStore_RefreshTokenIsSingleUse(t *testing.T) {
+	store, _ := newTokenStore(t)
+	ctx := context.Background()
+	if err := store.SaveRefreshToken(ctx, "old", dao.RefreshSession{UserID: "u1", Role: "user"}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	session, err := store.TakeRefreshToken(ctx, "old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.UserID != "u1" || session.Role != "user" {
+		t.Errorf("session = %+v, want u1/user", session)
+	}
+	// Rotation: the exchange stores a new token in place of the old one.
+	if err := store.SaveRefreshToken(ctx, "new", *session, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.TakeRefreshToken(ctx, "old"); !errors.Is(err, dao.ErrRefreshTokenNotFound) {
+		t.Errorf("second use of old token: err = %v, want ErrRefreshTokenNotFound", err)
+	}
+	if _, err := store.TakeRefreshToken(ctx, "new"); err != nil {
+		t.Errorf("rotated token: err = %v, want nil", err)
+	}
+}
+
+func TestTokenStore_GetRefreshTokenDoesNotUseItUp(t *testing.T) {
+	store, _ := newTokenStore(t)
+	ctx := context.Background()
+	if err := store.SaveRefreshToken(ctx, "tok", dao.RefreshSession{UserID: "u1"}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.GetRefreshToken(ctx, "tok"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.TakeRefreshToken(ctx, "tok"); err != nil {
+		t.Errorf("token after peek: err = %v, want nil", err)
+	}
+}
+
+func TestTokenStore_RevokeUserSessions(t *testing.T) {
+	store, mr := newTokenStore(t)
+	ctx := context.Background()
+	for _, tok := range []string{"a", "b"} {
+		if err := store.SaveRefreshToken(ctx, tok, dao.RefreshSession{UserID: "u1"}, time.Hour); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.SaveRefreshToken(ctx, "other", dao.RefreshSession{UserID: "u2"}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	before := time.Now().Unix()
+	if err := store.RevokeUserSessions(ctx, "u1", 15*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tok := range []string{"a", "b"} {
+		if _, err := store.TakeRefreshToken(ctx, tok); !errors.Is(err, dao.ErrRefreshTokenNotFound) {
+			t.Errorf("token %q: err = %v, want ErrRefreshTokenNotFound", tok, err)
+		}
+	}
+	if _, err := store.TakeRefreshToken(ctx, "other"); err != nil {
+		t.Errorf("token of another user: err = %v, want nil", err)
+	}
+
+	marker, err := mr.Get("revoked:user:u1")
+	if err != nil {
+		t.Fatalf("revoked:user:u1 not set: %v", err)
+	}
+	if ts, err := strconv.ParseInt(marker, 10, 64); err != nil || ts < before {
+		t.Errorf("marker = %q, want the unix time of the revocation", marker)
+	}
+	if got := mr.TTL("revoked:user:u1"); got != 15*time.Minute {
+		t.Errorf("marker ttl = %s, want 15m", got)
+	}
+}
@@ -2,11 +2,14 @@ package handler
 
 import (
 	"context"
-	"golang.org/x/crypto/bcrypt"
+	"errors"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
-	"log"
-	"user/internal/auth"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"log/slog"
+	"user/internal/identity"
 	"user/internal/model"
 	"user/internal/service"
 	pb "user/proto"
@@ -27,58 +30,232 @@ func (h *UserHandler) Register(ctx context.Context, req *pb.RegisterRequest) (*p
 		Email:    req.Email,
 		Password: req.Password,
 		Role:     "user",
+		Locale:   req.Locale,
 	}
 	id, err := h.svc.Register(ctx, user)
-	if err != nil {
-		return nil, err
+	var validationErr *service.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return nil, validationStatus(validationErr)
+	case errors.Is(err, service.ErrEmailTaken):
+		return nil, status.Error(codes.AlreadyExists, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.Internal, "failed to register user")
 	}
 	return &pb.RegisterResponse{Id: id}, nil
 }
 
-func (h *UserHandler) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
-	user, err := h.svc.GetUserByEmail(ctx, req.Email)
-	if err != nil {
-		return nil, status.Error(codes.Unauthenticated, "invalid credentials")
+// validationStatus turns a ValidationError into InvalidArgument with one
+// BadRequest field violation per invalid field.
+func validationStatus(err *service.ValidationError) error {
+	violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(err.Violations))
+	for _, v := range err.Violations {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       v.Field,
+			Description: v.Reason,
+		})
 	}
+	st, detailErr := status.New(codes.InvalidArgument, "invalid request data").
+		WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if detailErr != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return st.Err()
+}
 
-	log.Printf("Trying login for: %s", req.Email)
-	log.Printf("Input password: %s", req.Password)
-	log.Printf("Stored hash: %s", user.Password)
-
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
-	if err != nil {
-		log.Println("❌ Password mismatch")
+func (h *UserHandler) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
+	user, err := h.svc.Login(ctx, req.Email, req.Password, identity.ClientIP(ctx))
+	var lockedErr *service.LockedError
+	switch {
+	case errors.As(err, &lockedErr):
+		return nil, lockedStatus(lockedErr)
+	case errors.Is(err, service.ErrInvalidCredentials):
 		return nil, status.Error(codes.Unauthenticated, "invalid credentials")
+	case err != nil:
+		slog.ErrorContext(ctx, "login failed", "err", err)
+		return nil, status.Error(codes.Internal, "failed to log in")
 	}
 
-	token, err := auth.GenerateToken(user.ID, user.Role)
+	session, err := h.svc.IssueSession(ctx, user)
 	if err != nil {
 		return nil, status.Error(codes.Internal, "failed to generate token")
 	}
-	log.Printf("Login attempt for: %s", req.Email)
-	log.Printf("Stored hashed password: %s", user.Password)
-	log.Printf("Provided password: %s", req.Password)
 
 	return &pb.LoginResponse{
-		Message: "Login successful",
-		Token:   token,
-		UserId:  user.ID,
-		Role:    user.Role,
+		Message:      "Login successful",
+		Token:        session.AccessToken,
+		UserId:       user.ID,
+		Role:         user.Role,
+		RefreshToken: session.RefreshToken,
+		ExpiresIn:    session.ExpiresIn,
 	}, nil
 }
 
+// lockedStatus tells the client when it may try to log in again.
+func lockedStatus(err *service.LockedError) error {
+	st, detailErr := status.New(codes.ResourceExhausted, err.Error()).
+		WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(err.RetryAfter)})
+	if detailErr != nil {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return st.Err()
+}
+
+func (h *UserHandler) RefreshToken(ctx context.Context, req *pb.RefreshTokenRequest) (*pb.RefreshTokenResponse, error) {
+	session, err := h.svc.RefreshSession(ctx, req.RefreshToken)
+	if errors.Is(err, service.ErrInvalidToken) {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to refresh session")
+	}
+
+	return &pb.RefreshTokenResponse{
+		Token:        session.AccessToken,
+		RefreshToken: session.RefreshToken,
+		ExpiresIn:    session.ExpiresIn,
+	}, nil
+}
+
+func (h *UserHandler) Logout(ctx context.Context, req *pb.LogoutRequest) (*pb.LogoutResponse, error) {
+	err := h.svc.Logout(ctx, req.AccessToken, req.RefreshToken, req.AllSessions)
+	if errors.Is(err, service.ErrInvalidToken) {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to log out")
+	}
+
+	return &pb.LogoutResponse{Message: "Logged out"}, nil
+}
+
 func (h *UserHandler) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.GetUserResponse, error) {
 	user, err := h.svc.GetUserByID(ctx, req.Id)
 	if err != nil {
+		return nil, accountError(ctx, err, "failed to get user")
+	}
+	return &pb.GetUserResponse{User: toPbUser(user)}, nil
+}
+
+func (h *UserHandler) UpdateProfile(ctx context.Context, req *pb.UpdateProfileRequest) (*pb.UpdateProfileResponse, error) {
+	if _, err := authorize(ctx, req.Id); err != nil {
 		return nil, err
 	}
-	return &pb.GetUserResponse{
-		User: &pb.User{
-			Id:       user.ID,
-			Username: user.Username,
-			Email:    user.Email,
-			Password: "",
-			Role:     user.Role,
-		},
+
+	user := model.User{
+		ID:       req.Id,
+		Username: req.Username,
+		Email:    req.Email,
+		Phone:    req.Phone,
+		Locale:   req.Locale,
+	}
+	for _, a := range req.Addresses {
+		user.Addresses = append(user.Addresses, model.Address{
+			Label:      a.Label,
+			Street:     a.Street,
+			City:       a.City,
+			PostalCode: a.PostalCode,
+		})
+	}
+
+	updated, err := h.svc.UpdateProfile(ctx, user)
+	if err != nil {
+		return nil, accountError(ctx, err, "failed to update profile")
+	}
+	return &pb.UpdateProfileResponse{User: toPbUser(updated)}, nil
+}
+
+func (h *UserHandler) ChangePassword(ctx context.Context, req *pb.ChangePasswordRequest) (*pb.ChangePasswordResponse, error) {
+	caller, err := authorize(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = h.svc.ChangePassword(ctx, caller, req.Id, req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		return nil, accountError(ctx, err, "failed to change password")
+	}
+	return &pb.ChangePasswordResponse{Message: "Password changed, please log in again"}, nil
+}
+
+func (h *UserHandler) DeleteAccount(ctx context.Context, req *pb.DeleteAccountRequest) (*pb.DeleteAccountResponse, error) {
+	caller, err := authorize(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = h.svc.DeleteAccount(ctx, caller, req.Id, req.Password)
+	if err != nil {
+		return nil, accountError(ctx, err, "failed to delete account")
+	}
+	return &pb.DeleteAccountResponse{Message: "Account deleted"}, nil
+}
+
+func (h *UserHandler) RequestPasswordReset(ctx context.Context, req *pb.RequestPasswordResetRequest) (*pb.RequestPasswordResetResponse, error) {
+	if err := h.svc.RequestPasswordReset(ctx, req.Email); err != nil {
+		slog.ErrorContext(ctx, "password reset request failed", "err", err)
+		return nil, status.Error(codes.Internal, "failed to request password reset")
+	}
+	return &pb.RequestPasswordResetResponse{
+		Message: "If the email is registered, a reset link has been sent to it",
 	}, nil
 }
+
+func (h *UserHandler) ResetPassword(ctx context.Context, req *pb.ResetPasswordRequest) (*pb.ResetPasswordResponse, error) {
+	err := h.svc.ResetPassword(ctx, req.Token, req.NewPassword)
+	if errors.Is(err, service.ErrInvalidToken) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, accountError(ctx, err, "failed to reset password")
+	}
+	return &pb.ResetPasswordResponse{Message: "Password changed, please log in"}, nil
+}
+
+// authorize lets callers manage only their own account, unless they are admins.
+func authorize(ctx context.Context, userID string) (identity.Caller, error) {
+	caller, ok := identity.FromContext(ctx)
+	if !ok {
+		return caller, status.Error(codes.Unauthenticated, "missing caller identity")
+	}
+	if caller.UserID != userID && !caller.IsAdmin() {
+		return caller, status.Error(codes.PermissionDenied, "cannot manage another user's account")
+	}
+	return caller, nil
+}
+
+func accountError(ctx context.Context, err error, fallback string) error {
+	var validationErr *service.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return validationStatus(validationErr)
+	case errors.Is(err, service.ErrEmailTaken):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, service.ErrWrongPassword):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return status.Error(codes.NotFound, "user not found")
+	}
+	slog.ErrorContext(ctx, fallback, "err", err)
+	return status.Error(codes.Internal, fallback)
+}
+
+func toPbUser(user *model.User) *pb.User {
+	res := &pb.User{
+		Id:       user.ID,
+		Username: user.Username,
+		Email:    user.Email,
+		Role:     user.Role,
+		Phone:    user.Phone,
+		Locale:   user.Locale,
+	}
+	for _, a := range user.Addresses {
+		res.Addresses = append(res.Addresses, &pb.Address{
+			Label:      a.Label,
+			Street:     a.Street,
+			City:       a.City,
+			PostalCode: a.PostalCode,
+		})
+	}
+	return res
+}
@@ -0,0 +1,25 @@
+package handler_test
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"user/internal/dao"
+	"user/internal/handler"
+	"user/internal/service"
+	pb "user/proto"
+)
+
+func TestGetUser_UnknownIDIsNotFound(t *testing.T) {
+	// An id that is no ObjectID is looked up without reaching Mongo.
+	svc := service.NewUserService(&dao.UserRepository{}, nil, nil, nil, nil, service.Config{})
+	h := handler.NewUserHandler(svc)
+
+	_, err := h.GetUser(context.Background(), &pb.GetUserRequest{Id: "missing"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("err = %v, want NotFound", err)
+	}
+}
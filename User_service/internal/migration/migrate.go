@@ -0,0 +1,90 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"user/internal/service"
+)
+
+// Run brings the users collection up to date. An error means the service
+// must not start: without the unique email index two accounts can share an
+// address.
+func Run(db *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	usersCol := db.Collection("users")
+	if err := normalizeEmails(ctx, usersCol); err != nil {
+		return err
+	}
+
+	// Emails are stored lowercased, so a plain unique index is enough.
+	_, err := usersCol.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.M{"email": 1},
+		Options: options.Index().SetUnique(true).SetName("email_unique"),
+	})
+	if err != nil {
+		return fmt.Errorf("create unique email index: %w", err)
+	}
+	return nil
+}
+
+// normalizeEmails rewrites emails registered before they were normalized.
+// Accounts whose emails only differ in case or spacing are not touched: they
+// are reported, and have to be merged by hand before the index can be built.
+func normalizeEmails(ctx context.Context, usersCol *mongo.Collection) error {
+	cursor, err := usersCol.Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"email": 1}))
+	if err != nil {
+		return fmt.Errorf("list user emails: %w", err)
+	}
+	var users []struct {
+		ID    interface{} `bson:"_id"`
+		Email string      `bson:"email"`
+	}
+	if err := cursor.All(ctx, &users); err != nil {
+		return fmt.Errorf("list user emails: %w", err)
+	}
+
+	owners := map[string][]string{}
+	for _, u := range users {
+		email := service.NormalizeEmail(u.Email)
+		owners[email] = append(owners[email], fmt.Sprint(u.ID))
+	}
+	var collisions []string
+	for email, ids := range owners {
+		if len(ids) > 1 {
+			collisions = append(collisions, fmt.Sprintf("%s (users %s)", email, strings.Join(ids, ", ")))
+		}
+	}
+
+	updated := 0
+	for _, u := range users {
+		email := service.NormalizeEmail(u.Email)
+		if email == u.Email || len(owners[email]) > 1 {
+			continue
+		}
+		_, err := usersCol.UpdateOne(ctx, bson.M{"_id": u.ID, "email": u.Email}, bson.M{"$set": bson.M{"email": email}})
+		if err != nil {
+			return fmt.Errorf("normalize email of user %v: %w", u.ID, err)
+		}
+		updated++
+	}
+	if updated > 0 {
+		slog.Info("normalized stored emails", "count", updated)
+	}
+
+	if len(collisions) > 0 {
+		sort.Strings(collisions)
+		return fmt.Errorf("%d emails are used by several accounts, merge them before starting: %s",
+			len(collisions), strings.Join(collisions, "; "))
+	}
+	return nil
+}
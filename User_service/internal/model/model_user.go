@@ -1,9 +1,20 @@
 package model
 
 type User struct {
-	ID       string `bson:"_id,omitempty"`
-	Username string `bson:"username"`
-	Email    string `bson:"email"`
-	Password string `bson:"password"`
-	Role     string `bson:"role"`
+	ID        string    `bson:"_id,omitempty"`
+	Username  string    `bson:"username"`
+	Email     string    `bson:"email"`
+	Password  string    `bson:"password"`
+	Role      string    `bson:"role"`
+	Phone     string    `bson:"phone,omitempty"`
+	Addresses []Address `bson:"addresses,omitempty"`
+	// Locale is the language of the user's emails; empty means English.
+	Locale string `bson:"locale,omitempty"`
+}
+
+type Address struct {
+	Label      string `bson:"label"`
+	Street     string `bson:"street"`
+	City       string `bson:"city"`
+	PostalCode string `bson:"postal_code"`
 }
@@ -0,0 +1,93 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"foodstore/logging"
+	"log/slog"
+
+	"github.com/nats-io/nats.go"
+)
+
+const (
+	SubjectPasswordResetRequested = "user.password_reset_requested"
+	SubjectAccountLocked          = "user.account_locked"
+	SubjectUserUpdated            = "user.updated"
+)
+
+// PasswordResetRequested carries everything the mail consumer needs, so it
+// does not have to call back into UserService. ResetURL contains the token.
+type PasswordResetRequested struct {
+	UserID    string `json:"userId"`
+	Email     string `json:"email"`
+	Username  string `json:"username"`
+	Locale    string `json:"locale,omitempty"`
+	ResetURL  string `json:"resetUrl"`
+	ExpiresAt string `json:"expiresAt"`
+}
+
+// AccountLocked is published when repeated failed logins lock an account.
+type AccountLocked struct {
+	UserID         string `json:"userId"`
+	Email          string `json:"email"`
+	Username       string `json:"username"`
+	Locale         string `json:"locale,omitempty"`
+	FailedAttempts int64  `json:"failedAttempts"`
+	LockedUntil    string `json:"lockedUntil"`
+	ClientIP       string `json:"clientIp"`
+}
+
+// UserUpdated is published after a profile changed or an account was
+// deleted, so services that cache users drop their copy.
+type UserUpdated struct {
+	UserID  string `json:"userId"`
+	Deleted bool   `json:"deleted,omitempty"`
+}
+
+type Publisher struct {
+	conn *nats.Conn
+}
+
+func NewPublisher(url string) (*Publisher, error) {
+	nc, err := nats.Connect(url)
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("connected to NATS", "url", url)
+	return &Publisher{conn: nc}, nil
+}
+
+func (p *Publisher) PublishPasswordResetRequested(ctx context.Context, evt PasswordResetRequested) error {
+	return p.publish(ctx, SubjectPasswordResetRequested, evt.UserID, evt)
+}
+
+func (p *Publisher) PublishAccountLocked(ctx context.Context, evt AccountLocked) error {
+	return p.publish(ctx, SubjectAccountLocked, evt.UserID, evt)
+}
+
+func (p *Publisher) PublishUserUpdated(ctx context.Context, evt UserUpdated) error {
+	return p.publish(ctx, SubjectUserUpdated, evt.UserID, evt)
+}
+
+// publish sends evt with the request id of ctx as a header. Payloads carry
+// emails and reset links, so only the subject and user are logged.
+func (p *Publisher) publish(ctx context.Context, subject, userID string, evt interface{}) error {
+	data, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+	msg := nats.NewMsg(subject)
+	msg.Data = data
+	if id := logging.RequestID(ctx); id != "" {
+		msg.Header.Set(logging.RequestIDKey, id)
+	}
+	slog.InfoContext(ctx, "publishing event", "subject", subject, "user_id", userID)
+	return p.conn.PublishMsg(msg)
+}
+
+func (p *Publisher) Close() {
+	if p.conn != nil && !p.conn.IsClosed() {
+		p.conn.Close()
+		slog.Info("NATS connection closed")
+	}
+}
@@ -0,0 +1,118 @@
+package nats_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"foodstore/logging"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	natslib "github.com/nats-io/nats.go"
+
+	"user/internal/nats"
+)
+
+func runServer(t *testing.T) *server.Server {
+	t.Helper()
+	srv, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: server.RANDOM_PORT, NoLog: true, NoSigs: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Start()
+	if !srv.ReadyForConnections(5 * time.Second) {
+		t.Fatal("embedded NATS server did not start")
+	}
+	t.Cleanup(srv.Shutdown)
+	return srv
+}
+
+func TestPublisher_PasswordResetRequested(t *testing.T) {
+	srv := runServer(t)
+
+	nc, err := natslib.Connect(srv.ClientURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nc.Close()
+	sub, err := nc.SubscribeSync(nats.SubjectPasswordResetRequested)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pub, err := nats.NewPublisher(srv.ClientURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pub.Close()
+
+	want := nats.PasswordResetRequested{
+		UserID:    "665f1c2b9a1e4b3c2d1e0f01",
+		Email:     "john@example.com",
+		Username:  "john",
+		ResetURL:  "http://localhost:8082/reset-password.html?token=abc",
+		ExpiresAt: "2026-01-01T12:30:00Z",
+	}
+	var logs bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(logging.New(&logs, "user", slog.LevelDebug))
+
+	ctx := logging.WithRequestID(context.Background(), "req-42")
+	if err := pub.PublishPasswordResetRequested(ctx, want); err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := sub.NextMsg(2 * time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got nats.PasswordResetRequested
+	if err := json.Unmarshal(msg.Data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("event: got %+v, want %+v", got, want)
+	}
+	if id := msg.Header.Get(logging.RequestIDKey); id != "req-42" {
+		t.Errorf("request id header: got %q", id)
+	}
+	for _, secret := range []string{want.Email, want.ResetURL, "token=abc"} {
+		if strings.Contains(logs.String(), secret) {
+			t.Errorf("log output contains %q: %s", secret, logs.String())
+		}
+	}
+}
+
+func TestPublisher_UserUpdated(t *testing.T) {
+	srv := runServer(t)
+
+	nc, err := natslib.Connect(srv.ClientURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nc.Close()
+	sub, err := nc.SubscribeSync(nats.SubjectUserUpdated)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pub, err := nats.NewPublisher(srv.ClientURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pub.Close()
+
+	if err := pub.PublishUserUpdated(context.Background(), nats.UserUpdated{UserID: "665f1c2b9a1e4b3c2d1e0f01", Deleted: true}); err != nil {
+		t.Fatal(err)
+	}
+	msg, err := sub.NextMsg(2 * time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(msg.Data); got != `{"userId":"665f1c2b9a1e4b3c2d1e0f01","deleted":true}` {
+		t.Errorf("event: got %s", got)
+	}
+}
@@ -0,0 +1,219 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/nats-io/nats-server/v2/server"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc"
+
+	"user/internal/dao"
+	"user/internal/identity"
+	"user/internal/model"
+	"user/internal/nats"
+	"user/internal/service"
+	orderpb "user/proto/order"
+)
+
+// memUsers keeps users in memory, failing like the Mongo repository does.
+type memUsers struct {
+	mu    sync.Mutex
+	users map[string]model.User
+}
+
+func newMemUsers(users ...model.User) *memUsers {
+	m := &memUsers{users: map[string]model.User{}}
+	for _, u := range users {
+		m.users[u.ID] = u
+	}
+	return m
+}
+
+func (m *memUsers) CreateUser(ctx context.Context, user model.User) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.users[user.ID] = user
+	return user.ID, nil
+}
+
+func (m *memUsers) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, u := range m.users {
+		if u.Email == email {
+			return &u, nil
+		}
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+func (m *memUsers) GetUserByID(ctx context.Context, id string) (*model.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	u, ok := m.users[id]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	return &u, nil
+}
+
+func (m *memUsers) UpdateProfile(ctx context.Context, user model.User) error {
+	return errors.New("not implemented")
+}
+
+func (m *memUsers) UpdatePassword(ctx context.Context, id string, hashedPassword string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	u, ok := m.users[id]
+	if !ok {
+		return mongo.ErrNoDocuments
+	}
+	u.Password = hashedPassword
+	m.users[id] = u
+	return nil
+}
+
+func (m *memUsers) DeleteUser(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.users[id]; !ok {
+		return mongo.ErrNoDocuments
+	}
+	delete(m.users, id)
+	return nil
+}
+
+// orderClient records which users had their orders anonymized.
+type orderClient struct {
+	orderpb.OrderServiceClient
+	anonymized []string
+}
+
+func (c *orderClient) AnonymizeUserOrders(ctx context.Context, in *orderpb.AnonymizeUserOrdersRequest, opts ...grpc.CallOption) (*orderpb.AnonymizeUserOrdersResponse, error) {
+	c.anonymized = append(c.anonymized, in.UserId)
+	return &orderpb.AnonymizeUserOrdersResponse{}, nil
+}
+
+func hashPassword(t *testing.T, password string) string {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(hash)
+}
+
+func newPublisher(t *testing.T) *nats.Publisher {
+	t.Helper()
+	srv, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: server.RANDOM_PORT, NoLog: true, NoSigs: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Start()
+	if !srv.ReadyForConnections(5 * time.Second) {
+		t.Fatal("embedded NATS server did not start")
+	}
+	t.Cleanup(srv.Shutdown)
+	pub, err := nats.NewPublisher(srv.ClientURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pub.Close)
+	return pub
+}
+
+func newAccountService(t *testing.T, users *memUsers) (*service.UserService, *orderClient) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	orders := &orderClient{}
+	svc := service.NewUserService(users, dao.NewTokenStore(client), nil, orders, newPublisher(t), service.Config{})
+	return svc, orders
+}
+
+var (
+	john  = identity.Caller{UserID: "u1", Role: "user"}
+	admin = identity.Caller{UserID: "a1", Role: identity.RoleAdmin}
+)
+
+func TestChangePassword_OwnerNeedsCurrentPassword(t *testing.T) {
+	users := newMemUsers(model.User{ID: "u1", Password: hashPassword(t, "old-secret1")})
+	svc, _ := newAccountService(t, users)
+	ctx := context.Background()
+
+	if err := svc.ChangePassword(ctx, john, "u1", "wrong", "new-secret1"); !errors.Is(err, service.ErrWrongPassword) {
+		t.Errorf("wrong current password: err = %v, want ErrWrongPassword", err)
+	}
+	if err := svc.ChangePassword(ctx, john, "u1", "old-secret1", "new-secret1"); err != nil {
+		t.Fatal(err)
+	}
+	u, _ := users.GetUserByID(ctx, "u1")
+	if bcrypt.CompareHashAndPassword([]byte(u.Password), []byte("new-secret1")) != nil {
+		t.Error("password was not changed")
+	}
+}
+
+func TestChangePassword_AdminSkipsCurrentPasswordOfOthers(t *testing.T) {
+	users := newMemUsers(
+		model.User{ID: "u1", Password: hashPassword(t, "old-secret1")},
+		model.User{ID: "a1", Role: identity.RoleAdmin, Password: hashPassword(t, "admin-secret1")},
+	)
+	svc, _ := newAccountService(t, users)
+	ctx := context.Background()
+
+	if err := svc.ChangePassword(ctx, admin, "u1", "", "new-secret1"); err != nil {
+		t.Fatalf("admin changing another user's password: %v", err)
+	}
+	u, _ := users.GetUserByID(ctx, "u1")
+	if bcrypt.CompareHashAndPassword([]byte(u.Password), []byte("new-secret1")) != nil {
+		t.Error("password was not changed")
+	}
+
+	if err := svc.ChangePassword(ctx, admin, "a1", "", "new-secret1"); !errors.Is(err, service.ErrWrongPassword) {
+		t.Errorf("admin changing their own password without it: err = %v, want ErrWrongPassword", err)
+	}
+}
+
+func TestDeleteAccount_OwnerNeedsPassword(t *testing.T) {
+	users := newMemUsers(model.User{ID: "u1", Password: hashPassword(t, "secret1")})
+	svc, orders := newAccountService(t, users)
+	ctx := context.Background()
+
+	if err := svc.DeleteAccount(ctx, john, "u1", ""); !errors.Is(err, service.ErrWrongPassword) {
+		t.Errorf("missing password: err = %v, want ErrWrongPassword", err)
+	}
+	if len(orders.anonymized) != 0 {
+		t.Errorf("orders anonymized before the password was checked: %v", orders.anonymized)
+	}
+
+	if err := svc.DeleteAccount(ctx, john, "u1", "secret1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := users.GetUserByID(ctx, "u1"); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Error("account was not deleted")
+	}
+}
+
+func TestDeleteAccount_AdminSkipsPasswordOfOthers(t *testing.T) {
+	users := newMemUsers(model.User{ID: "u1", Password: hashPassword(t, "secret1")})
+	svc, orders := newAccountService(t, users)
+	ctx := context.Background()
+
+	if err := svc.DeleteAccount(ctx, admin, "u1", ""); err != nil {
+		t.Fatalf("admin deleting another user's account: %v", err)
+	}
+	if _, err := users.GetUserByID(ctx, "u1"); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Error("account was not deleted")
+	}
+	if len(orders.anonymized) != 1 || orders.anonymized[0] != "u1" {
+		t.Errorf("anonymized = %v, want the orders of u1", orders.anonymized)
+	}
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/crypto/bcrypt"
+	"user/internal/model"
+	"user/internal/nats"
+)
+
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// dummyPasswordHash is checked for unknown emails, so that they take as long
+// to reject as a wrong password and do not reveal who has an account. Its
+// cost matches the hashes made at registration.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("no such user"), bcrypt.DefaultCost)
+
+// LockedError is returned while logins for an email or client IP are
+// throttled after too many failures.
+type LockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, retry in %s", e.RetryAfter.Round(time.Second))
+}
+
+// LoginPolicy decides how long logins are blocked after a number of failed
+// attempts within Window. The first FreeAttempts failures cost nothing, every
+// further one doubles the delay starting at BaseDelay, and LockoutAttempts
+// failures lock logins for LockoutDuration.
+type LoginPolicy struct {
+	FreeAttempts    int64
+	LockoutAttempts int64
+	BaseDelay       time.Duration
+	LockoutDuration time.Duration
+	Window          time.Duration
+}
+
+var (
+	DefaultEmailLoginPolicy = LoginPolicy{
+		FreeAttempts:    5,
+		LockoutAttempts: 10,
+		BaseDelay:       2 * time.Second,
+		LockoutDuration: 15 * time.Minute,
+		Window:          15 * time.Minute,
+	}
+	// A client IP may be shared by many users, so it gets more room.
+	DefaultIPLoginPolicy = LoginPolicy{
+		FreeAttempts:    20,
+		LockoutAttempts: 100,
+		BaseDelay:       time.Second,
+		LockoutDuration: 15 * time.Minute,
+		Window:          15 * time.Minute,
+	}
+)
+
+// Delay returns how long logins are blocked after failures failed attempts.
+func (p LoginPolicy) Delay(failures int64) time.Duration {
+	if p.LockoutAttempts > 0 && failures >= p.LockoutAttempts {
+		return p.LockoutDuration
+	}
+	if failures <= p.FreeAttempts {
+		return 0
+	}
+	shift := failures - p.FreeAttempts - 1
+	if shift > 30 {
+		return p.LockoutDuration
+	}
+	d := p.BaseDelay << shift
+	if d > p.LockoutDuration {
+		return p.LockoutDuration
+	}
+	return d
+}
+
+// Login checks the credentials of email, throttling failed attempts per email
+// and per clientIP. Unknown emails are throttled the same way as known ones.
+// When the attempt counters are unavailable logins are still allowed.
+func (s *UserService) Login(ctx context.Context, email, password, clientIP string) (*model.User, error) {
+	email = NormalizeEmail(email)
+	emailKey, ipKey := "email:"+email, "ip:"+clientIP
+
+	keys := []string{emailKey}
+	if clientIP != "" {
+		keys = append(keys, ipKey)
+	}
+	locked, err := s.attempts.LockedFor(ctx, keys...)
+	if err != nil {
+		slog.WarnContext(ctx, "login attempts unavailable, allowing login", "err", err)
+	} else if locked > 0 {
+		return nil, &LockedError{RetryAfter: locked}
+	}
+
+	user, err := s.repo.GetUserByEmail(ctx, email)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+	hash := dummyPasswordHash
+	if err == nil {
+		hash = []byte(user.Password)
+	} else {
+		user = nil
+	}
+	if bcrypt.CompareHashAndPassword(hash, []byte(password)) == nil && user != nil {
+		if err := s.attempts.Reset(ctx, emailKey); err != nil {
+			slog.WarnContext(ctx, "failed to reset login attempts", "err", err)
+		}
+		return user, nil
+	}
+
+	failures, retryAfter := s.addLoginFailure(ctx, emailKey, s.cfg.EmailLogin)
+	if failures == s.cfg.EmailLogin.LockoutAttempts && user != nil {
+		s.publishAccountLocked(ctx, user, failures, retryAfter, clientIP)
+	}
+	if clientIP != "" {
+		if _, ipRetryAfter := s.addLoginFailure(ctx, ipKey, s.cfg.IPLogin); ipRetryAfter > retryAfter {
+			retryAfter = ipRetryAfter
+		}
+	}
+
+	if retryAfter > 0 {
+		return nil, &LockedError{RetryAfter: retryAfter}
+	}
+	return nil, ErrInvalidCredentials
+}
+
+// addLoginFailure counts a failure for key and locks it as policy demands.
+func (s *UserService) addLoginFailure(ctx context.Context, key string, policy LoginPolicy) (int64, time.Duration) {
+	failures, err := s.attempts.AddFailure(ctx, key, policy.Window)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to count login failure", "err", err)
+		return 0, 0
+	}
+	d := policy.Delay(failures)
+	if d <= 0 {
+		return failures, 0
+	}
+	if err := s.attempts.Lock(ctx, key, d); err != nil {
+		slog.WarnContext(ctx, "failed to lock logins", "err", err)
+		return failures, 0
+	}
+	return failures, d
+}
+
+func (s *UserService) publishAccountLocked(ctx context.Context, user *model.User, failures int64, d time.Duration, clientIP string) {
+	err := s.events.PublishAccountLocked(ctx, nats.AccountLocked{
+		UserID:         user.ID,
+		Email:          user.Email,
+		Username:       user.Username,
+		Locale:         user.Locale,
+		FailedAttempts: failures,
+		LockedUntil:    time.Now().Add(d).UTC().Format(time.RFC3339),
+		ClientIP:       clientIP,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to publish account lockout", "err", err)
+	}
+}
@@ -0,0 +1,132 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+
+	"user/internal/dao"
+	"user/internal/model"
+	"user/internal/service"
+)
+
+func TestLoginPolicy_Delay(t *testing.T) {
+	p := service.LoginPolicy{
+		FreeAttempts:    5,
+		LockoutAttempts: 10,
+		BaseDelay:       2 * time.Second,
+		LockoutDuration: 15 * time.Minute,
+	}
+
+	tests := []struct {
+		failures int64
+		want     time.Duration
+	}{
+		{1, 0},
+		{5, 0},
+		{6, 2 * time.Second},
+		{7, 4 * time.Second},
+		{9, 16 * time.Second},
+		{10, 15 * time.Minute},
+		{500, 15 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := p.Delay(tt.failures); got != tt.want {
+			t.Errorf("Delay(%d) = %s, want %s", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestLoginPolicy_DelayIsCappedBeforeLockout(t *testing.T) {
+	p := service.DefaultIPLoginPolicy
+
+	for failures := p.FreeAttempts + 1; failures < p.LockoutAttempts; failures++ {
+		if d := p.Delay(failures); d <= 0 || d > p.LockoutDuration {
+			t.Fatalf("Delay(%d) = %s, want within (0, %s]", failures, d, p.LockoutDuration)
+		}
+	}
+}
+
+// newLoginService delays logins for an email by 1s after its 3rd failure and
+// locks them for a minute at the 4th. Client IPs get the default policy.
+func newLoginService(t *testing.T, users *memUsers) (*service.UserService, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	cfg := service.Config{
+		EmailLogin: service.LoginPolicy{
+			FreeAttempts:    2,
+			LockoutAttempts: 4,
+			BaseDelay:       time.Second,
+			LockoutDuration: time.Minute,
+			Window:          15 * time.Minute,
+		},
+		IPLogin: service.DefaultIPLoginPolicy,
+	}
+	svc := service.NewUserService(users, dao.NewTokenStore(client), dao.NewLoginAttempts(client), nil, newPublisher(t), cfg)
+	return svc, mr
+}
+
+func lockedFor(t *testing.T, err error) time.Duration {
+	t.Helper()
+	var locked *service.LockedError
+	if !errors.As(err, &locked) {
+		t.Fatalf("err = %v, want LockedError", err)
+	}
+	return locked.RetryAfter
+}
+
+// mustFail drops the user returned by Login.
+func mustFail(_ *model.User, err error) error {
+	return err
+}
+
+func TestLogin_LocksOutAndUnlocks(t *testing.T) {
+	users := newMemUsers(model.User{ID: "u1", Email: "john@example.com", Password: hashPassword(t, "secret1")})
+	svc, mr := newLoginService(t, users)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := svc.Login(ctx, "john@example.com", "wrong", "10.0.0.1"); !errors.Is(err, service.ErrInvalidCredentials) {
+			t.Fatalf("free attempt %d: err = %v, want ErrInvalidCredentials", i+1, err)
+		}
+	}
+	if d := lockedFor(t, mustFail(svc.Login(ctx, "john@example.com", "wrong", "10.0.0.1"))); d != time.Second {
+		t.Errorf("3rd failure: retry after %s, want 1s", d)
+	}
+	// Even the right password is refused while locked.
+	lockedFor(t, mustFail(svc.Login(ctx, "John@Example.com", "secret1", "10.0.0.2")))
+
+	mr.FastForward(time.Second)
+	if d := lockedFor(t, mustFail(svc.Login(ctx, "john@example.com", "wrong", "10.0.0.1"))); d != time.Minute {
+		t.Errorf("4th failure: retry after %s, want the 1m lockout", d)
+	}
+
+	mr.FastForward(time.Minute)
+	if _, err := svc.Login(ctx, "john@example.com", "secret1", "10.0.0.1"); err != nil {
+		t.Fatalf("login after the lockout: %v", err)
+	}
+	// A successful login forgets the earlier failures.
+	if _, err := svc.Login(ctx, "john@example.com", "wrong", "10.0.0.1"); !errors.Is(err, service.ErrInvalidCredentials) {
+		t.Errorf("failure after a successful login: err = %v, want ErrInvalidCredentials", err)
+	}
+}
+
+func TestLogin_UnknownEmailIsThrottledLikeKnownOne(t *testing.T) {
+	svc, _ := newLoginService(t, newMemUsers())
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := svc.Login(ctx, "nobody@example.com", "secret1", ""); !errors.Is(err, service.ErrInvalidCredentials) {
+			t.Fatalf("attempt %d: err = %v, want ErrInvalidCredentials", i+1, err)
+		}
+	}
+	if d := lockedFor(t, mustFail(svc.Login(ctx, "nobody@example.com", "secret1", ""))); d != time.Second {
+		t.Errorf("3rd failure: retry after %s, want 1s", d)
+	}
+}
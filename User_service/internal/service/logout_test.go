@@ -0,0 +1,96 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+
+	"user/internal/auth"
+	"user/internal/dao"
+	"user/internal/model"
+	"user/internal/service"
+)
+
+func newSessionService(t *testing.T) (*service.UserService, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	// Sessions are issued and revoked without touching the user repository.
+	svc := service.NewUserService(nil, dao.NewTokenStore(client), nil, nil, nil, service.Config{RefreshTokenTTL: time.Hour})
+	return svc, mr
+}
+
+func issue(t *testing.T, svc *service.UserService, userID string) *service.Session {
+	t.Helper()
+	session, err := svc.IssueSession(context.Background(), &model.User{ID: userID, Role: "user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return session
+}
+
+func TestLogout_RevokesOwnTokens(t *testing.T) {
+	svc, mr := newSessionService(t)
+	s := issue(t, svc, "u1")
+
+	if err := svc.Logout(context.Background(), s.AccessToken, s.RefreshToken, false); err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := auth.ParseToken(s.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !mr.Exists("revoked:jti:" + claims.ID) {
+		t.Error("access token is not on the revocation list")
+	}
+	if _, err := svc.RefreshSession(context.Background(), s.RefreshToken); !errors.Is(err, service.ErrInvalidToken) {
+		t.Errorf("refresh after logout: err = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestLogout_RejectsRefreshTokenOfAnotherUser(t *testing.T) {
+	svc, mr := newSessionService(t)
+	attacker := issue(t, svc, "u1")
+	victim := issue(t, svc, "u2")
+
+	err := svc.Logout(context.Background(), attacker.AccessToken, victim.RefreshToken, false)
+	if !errors.Is(err, service.ErrInvalidToken) {
+		t.Errorf("err = %v, want ErrInvalidToken", err)
+	}
+
+	// The victim's session is untouched.
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	session, err := dao.NewTokenStore(client).GetRefreshToken(context.Background(), victim.RefreshToken)
+	if err != nil {
+		t.Fatalf("victim's refresh token: err = %v, want it to stay valid", err)
+	}
+	if session.UserID != "u2" {
+		t.Errorf("session user = %q, want u2", session.UserID)
+	}
+}
+
+func TestLogout_AllSessionsInvalidatesEarlierTokens(t *testing.T) {
+	svc, mr := newSessionService(t)
+	first := issue(t, svc, "u1")
+	second := issue(t, svc, "u1")
+
+	if err := svc.Logout(context.Background(), second.AccessToken, "", true); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []*service.Session{first, second} {
+		if _, err := svc.RefreshSession(context.Background(), s.RefreshToken); !errors.Is(err, service.ErrInvalidToken) {
+			t.Errorf("refresh after logout-all: err = %v, want ErrInvalidToken", err)
+		}
+	}
+	if !mr.Exists("revoked:user:u1") {
+		t.Error("access tokens of u1 are not revoked")
+	}
+}
@@ -2,20 +2,67 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/mongo"
 	"golang.org/x/crypto/bcrypt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+	"user/internal/auth"
 	"user/internal/dao"
+	"user/internal/identity"
 	"user/internal/model"
+	"user/internal/nats"
+	orderpb "user/proto/order"
 )
 
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// Config holds the token lifetimes and links UserService hands out.
+type Config struct {
+	RefreshTokenTTL time.Duration
+	ResetTokenTTL   time.Duration
+	// ResetURL is the frontend page that receives the reset token as ?token=.
+	ResetURL   string
+	EmailLogin LoginPolicy
+	IPLogin    LoginPolicy
+}
+
 type UserService struct {
-	repo *dao.UserRepository
+	repo     dao.UserStore
+	tokens   *dao.TokenStore
+	attempts *dao.LoginAttempts
+	orders   orderpb.OrderServiceClient
+	events   *nats.Publisher
+	cfg      Config
 }
 
-func NewUserService(repo *dao.UserRepository) *UserService {
-	return &UserService{repo: repo}
+func NewUserService(repo dao.UserStore, tokens *dao.TokenStore, attempts *dao.LoginAttempts, orders orderpb.OrderServiceClient, events *nats.Publisher, cfg Config) *UserService {
+	return &UserService{repo: repo, tokens: tokens, attempts: attempts, orders: orders, events: events, cfg: cfg}
+}
+
+// Session is an access token together with the refresh token that renews it.
+type Session struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int64
 }
 
 func (s *UserService) Register(ctx context.Context, user model.User) (string, error) {
+	user.Username = strings.TrimSpace(user.Username)
+	user.Email = NormalizeEmail(user.Email)
+	user.Locale = strings.ToLower(strings.TrimSpace(user.Locale))
+
+	v := &ValidationError{}
+	validateUsername(v, user.Username)
+	validateEmail(v, user.Email)
+	validatePassword(v, "password", user.Password)
+	validateLocale(v, user.Locale)
+	if err := v.orNil(); err != nil {
+		return "", err
+	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -23,12 +70,257 @@ func (s *UserService) Register(ctx context.Context, user model.User) (string, er
 	}
 	user.Password = string(hashedPassword)
 
-	return s.repo.CreateUser(ctx, user)
+	id, err := s.repo.CreateUser(ctx, user)
+	if mongo.IsDuplicateKeyError(err) {
+		return "", ErrEmailTaken
+	}
+	return id, err
 }
 func (s *UserService) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
-	return s.repo.GetUserByEmail(ctx, email)
+	return s.repo.GetUserByEmail(ctx, NormalizeEmail(email))
 }
 
 func (s *UserService) GetUserByID(ctx context.Context, id string) (*model.User, error) {
 	return s.repo.GetUserByID(ctx, id)
 }
+
+// IssueSession creates a new access and refresh token pair for user.
+func (s *UserService) IssueSession(ctx context.Context, user *model.User) (*Session, error) {
+	accessToken, err := auth.GenerateToken(user.ID, user.Role)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := auth.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.tokens.SaveRefreshToken(ctx, refreshToken, dao.RefreshSession{UserID: user.ID, Role: user.Role}, s.cfg.RefreshTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Session{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(auth.AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+// RefreshSession exchanges a refresh token for a new pair. The old refresh
+// token is consumed, and the role is re-read so role changes take effect.
+func (s *UserService) RefreshSession(ctx context.Context, refreshToken string) (*Session, error) {
+	session, err := s.tokens.TakeRefreshToken(ctx, refreshToken)
+	if errors.Is(err, dao.ErrRefreshTokenNotFound) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.repo.GetUserByID(ctx, session.UserID)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	return s.IssueSession(ctx, user)
+}
+
+// Logout revokes the given access token and refresh token, or every session
+// of the token's user when allSessions is set. A refresh token of another
+// user is rejected with ErrInvalidToken and left valid.
+func (s *UserService) Logout(ctx context.Context, accessToken, refreshToken string, allSessions bool) error {
+	claims, err := auth.ParseToken(accessToken)
+	if err != nil || claims == nil {
+		return ErrInvalidToken
+	}
+
+	if allSessions {
+		return s.tokens.RevokeUserSessions(ctx, claims.UserID, auth.AccessTokenTTL)
+	}
+
+	if refreshToken != "" {
+		session, err := s.tokens.GetRefreshToken(ctx, refreshToken)
+		switch {
+		case errors.Is(err, dao.ErrRefreshTokenNotFound):
+			// Already used or expired.
+		case err != nil:
+			return err
+		case session.UserID != claims.UserID:
+			return ErrInvalidToken
+		default:
+			_, err := s.tokens.TakeRefreshToken(ctx, refreshToken)
+			if err != nil && !errors.Is(err, dao.ErrRefreshTokenNotFound) {
+				return err
+			}
+		}
+	}
+	return s.tokens.RevokeAccessToken(ctx, claims.ID, claims.ExpiresAt.Time)
+}
+
+// UpdateProfile replaces the editable fields of user.ID with those of user.
+// An empty Locale keeps the stored one, for clients that do not offer it.
+func (s *UserService) UpdateProfile(ctx context.Context, user model.User) (*model.User, error) {
+	user.Username = strings.TrimSpace(user.Username)
+	user.Email = NormalizeEmail(user.Email)
+	user.Phone = strings.TrimSpace(user.Phone)
+	user.Locale = strings.ToLower(strings.TrimSpace(user.Locale))
+	for i := range user.Addresses {
+		a := &user.Addresses[i]
+		a.Label, a.Street = strings.TrimSpace(a.Label), strings.TrimSpace(a.Street)
+		a.City, a.PostalCode = strings.TrimSpace(a.City), strings.TrimSpace(a.PostalCode)
+	}
+
+	v := &ValidationError{}
+	validateUsername(v, user.Username)
+	validateEmail(v, user.Email)
+	validatePhone(v, user.Phone)
+	validateAddresses(v, user.Addresses)
+	validateLocale(v, user.Locale)
+	if err := v.orNil(); err != nil {
+		return nil, err
+	}
+
+	err := s.repo.UpdateProfile(ctx, user)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, ErrEmailTaken
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.publishUserUpdated(ctx, nats.UserUpdated{UserID: user.ID})
+	return s.repo.GetUserByID(ctx, user.ID)
+}
+
+// publishUserUpdated announces a change that is already stored, so a failure
+// is only logged; caches of the user expire on their own.
+func (s *UserService) publishUserUpdated(ctx context.Context, evt nats.UserUpdated) {
+	if err := s.events.PublishUserUpdated(ctx, evt); err != nil {
+		slog.ErrorContext(ctx, "failed to publish user update", "user_id", evt.UserID, "err", err)
+	}
+}
+
+// ChangePassword replaces the password of user id and revokes all of their
+// sessions, including the current one. currentPassword is only checked when
+// callers change their own password.
+func (s *UserService) ChangePassword(ctx context.Context, caller identity.Caller, id, currentPassword, newPassword string) error {
+	user, err := s.account(ctx, caller, id, currentPassword)
+	if err != nil {
+		return err
+	}
+
+	v := &ValidationError{}
+	validatePassword(v, "new_password", newPassword)
+	if err := v.orNil(); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.UpdatePassword(ctx, user.ID, string(hashedPassword)); err != nil {
+		return err
+	}
+	return s.tokens.RevokeUserSessions(ctx, user.ID, auth.AccessTokenTTL)
+}
+
+// DeleteAccount anonymizes the orders of user id in Order_service, then
+// deletes the user and revokes their sessions. The orders go first so a
+// failure leaves the account in place to retry rather than orphaned orders.
+// password is only checked when callers delete their own account.
+func (s *UserService) DeleteAccount(ctx context.Context, caller identity.Caller, id, password string) error {
+	user, err := s.account(ctx, caller, id, password)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.orders.AnonymizeUserOrders(identity.OutgoingContext(ctx, caller), &orderpb.AnonymizeUserOrdersRequest{UserId: user.ID})
+	if err != nil {
+		return fmt.Errorf("anonymize orders: %w", err)
+	}
+
+	if err := s.repo.DeleteUser(ctx, user.ID); err != nil {
+		return err
+	}
+	s.publishUserUpdated(ctx, nats.UserUpdated{UserID: user.ID, Deleted: true})
+	return s.tokens.RevokeUserSessions(ctx, user.ID, auth.AccessTokenTTL)
+}
+
+// RequestPasswordReset emails a single-use reset link to the owner of email.
+// Unknown emails are not reported, so the endpoint cannot be used to find out
+// who has an account.
+func (s *UserService) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.repo.GetUserByEmail(ctx, NormalizeEmail(email))
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	token, err := auth.RandomToken(32)
+	if err != nil {
+		return err
+	}
+	if err := s.tokens.SaveResetToken(ctx, token, user.ID, s.cfg.ResetTokenTTL); err != nil {
+		return err
+	}
+
+	return s.events.PublishPasswordResetRequested(ctx, nats.PasswordResetRequested{
+		UserID:    user.ID,
+		Email:     user.Email,
+		Username:  user.Username,
+		Locale:    user.Locale,
+		ResetURL:  s.cfg.ResetURL + "?token=" + url.QueryEscape(token),
+		ExpiresAt: time.Now().Add(s.cfg.ResetTokenTTL).UTC().Format(time.RFC3339),
+	})
+}
+
+// ResetPassword sets a new password with a token from RequestPasswordReset
+// and signs the user out everywhere. The token is only consumed once the new
+// password passes validation.
+func (s *UserService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	v := &ValidationError{}
+	validatePassword(v, "new_password", newPassword)
+	if err := v.orNil(); err != nil {
+		return err
+	}
+
+	userID, err := s.tokens.TakeResetToken(ctx, token)
+	if errors.Is(err, dao.ErrResetTokenNotFound) {
+		return ErrInvalidToken
+	}
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.UpdatePassword(ctx, userID, string(hashedPassword)); err != nil {
+		return err
+	}
+	return s.tokens.RevokeUserSessions(ctx, userID, auth.AccessTokenTTL)
+}
+
+// account returns user id for caller to manage. Callers acting on their own
+// account confirm it with their password; admins managing another user's
+// account do not know it.
+func (s *UserService) account(ctx context.Context, caller identity.Caller, id, password string) (*model.User, error) {
+	if caller.IsAdmin() && caller.UserID != id {
+		return s.repo.GetUserByID(ctx, id)
+	}
+	return s.checkPassword(ctx, id, password)
+}
+
+func (s *UserService) checkPassword(ctx context.Context, id, password string) (*model.User, error) {
+	user, err := s.repo.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
+		return nil, ErrWrongPassword
+	}
+	return user, nil
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+	"user/internal/model"
+)
+
+var (
+	ErrEmailTaken    = errors.New("email is already registered")
+	ErrWrongPassword = errors.New("password is incorrect")
+)
+
+const maxAddresses = 5
+
+// Locales are the languages emails can be sent in.
+var Locales = []string{"en", "ru"}
+
+var phonePattern = regexp.MustCompile(`^\+?[0-9][0-9 ()-]{5,18}[0-9]$`)
+
+type FieldViolation struct {
+	Field  string
+	Reason string
+}
+
+// ValidationError lists every invalid field of a request at once.
+type ValidationError struct {
+	Violations []FieldViolation
+}
+
+func (e *ValidationError) Error() string {
+	reasons := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		reasons = append(reasons, v.Field+": "+v.Reason)
+	}
+	return "invalid request: " + strings.Join(reasons, "; ")
+}
+
+func (e *ValidationError) add(field, reason string) {
+	e.Violations = append(e.Violations, FieldViolation{Field: field, Reason: reason})
+}
+
+func (e *ValidationError) orNil() error {
+	if len(e.Violations) == 0 {
+		return nil
+	}
+	return e
+}
+
+// NormalizeEmail is applied to every email before it is stored or looked up.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func validateUsername(v *ValidationError, username string) {
+	n := utf8.RuneCountInString(strings.TrimSpace(username))
+	if n < 2 || n > 50 {
+		v.add("username", "must be between 2 and 50 characters")
+	}
+}
+
+func validateEmail(v *ValidationError, email string) {
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || !strings.Contains(email[strings.LastIndex(email, "@")+1:], ".") {
+		v.add("email", "must be a valid email address")
+	}
+}
+
+func validatePassword(v *ValidationError, field, password string) {
+	var hasLetter, hasDigit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+	switch {
+	case utf8.RuneCountInString(password) < 8:
+		v.add(field, "must be at least 8 characters")
+	case len(password) > 72:
+		// bcrypt ignores everything after 72 bytes.
+		v.add(field, "must be at most 72 bytes")
+	case !hasLetter || !hasDigit:
+		v.add(field, "must contain both letters and digits")
+	}
+}
+
+func validatePhone(v *ValidationError, phone string) {
+	if phone != "" && !phonePattern.MatchString(phone) {
+		v.add("phone", "must be a phone number like +7 701 123 45 67")
+	}
+}
+
+func validateLocale(v *ValidationError, locale string) {
+	if locale != "" && !slices.Contains(Locales, locale) {
+		v.add("locale", "must be one of "+strings.Join(Locales, ", "))
+	}
+}
+
+func validateAddresses(v *ValidationError, addresses []model.Address) {
+	if len(addresses) > maxAddresses {
+		v.add("addresses", fmt.Sprintf("at most %d addresses can be saved", maxAddresses))
+		return
+	}
+	for i, a := range addresses {
+		if a.Street == "" {
+			v.add(fmt.Sprintf("addresses[%d].street", i), "is required")
+		}
+		if a.City == "" {
+			v.add(fmt.Sprintf("addresses[%d].city", i), "is required")
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"user/internal/model"
+	"user/internal/service"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	if got := service.NormalizeEmail("  John.Doe@Example.COM "); got != "john.doe@example.com" {
+		t.Errorf("NormalizeEmail: got %q", got)
+	}
+}
+
+func TestRegister_Validation(t *testing.T) {
+	svc := service.NewUserService(nil, nil, nil, nil, nil, service.Config{})
+
+	tests := []struct {
+		name   string
+		user   model.User
+		fields []string
+	}{
+		{"bad email", model.User{Username: "john", Email: "john@", Password: "secret123"}, []string{"email"}},
+		{"email without domain dot", model.User{Username: "john", Email: "john@localhost", Password: "secret123"}, []string{"email"}},
+		{"display name email", model.User{Username: "john", Email: "John <john@example.com>", Password: "secret123"}, []string{"email"}},
+		{"short password", model.User{Username: "john", Email: "john@example.com", Password: "abc1"}, []string{"password"}},
+		{"password without digits", model.User{Username: "john", Email: "john@example.com", Password: "secretsecret"}, []string{"password"}},
+		{"everything wrong", model.User{Username: " ", Email: "nope", Password: "1"}, []string{"username", "email", "password"}},
+		{"unknown locale", model.User{Username: "john", Email: "john@example.com", Password: "secret123", Locale: "de"}, []string{"locale"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.Register(context.Background(), tt.user)
+
+			var validationErr *service.ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("expected ValidationError, got %v", err)
+			}
+			var fields []string
+			for _, v := range validationErr.Violations {
+				fields = append(fields, v.Field)
+			}
+			if len(fields) != len(tt.fields) {
+				t.Fatalf("violations: got %v, want %v", fields, tt.fields)
+			}
+			for i := range fields {
+				if fields[i] != tt.fields[i] {
+					t.Errorf("violations: got %v, want %v", fields, tt.fields)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateProfile_Validation(t *testing.T) {
+	svc := service.NewUserService(nil, nil, nil, nil, nil, service.Config{})
+
+	_, err := svc.UpdateProfile(context.Background(), model.User{
+		ID:       "665f1c2b9a1e4b3c2d1e0f01",
+		Username: "john",
+		Email:    "john@example.com",
+		Phone:    "call me",
+		Addresses: []model.Address{
+			{Label: "Home", Street: "Abay 10", City: "Almaty"},
+			{Label: "Work", Street: " "},
+		},
+		Locale: "de",
+	})
+
+	var validationErr *service.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	var fields []string
+	for _, v := range validationErr.Violations {
+		fields = append(fields, v.Field)
+	}
+	want := []string{"phone", "addresses[1].street", "addresses[1].city", "locale"}
+	if strings.Join(fields, ",") != strings.Join(want, ",") {
+		t.Errorf("violations: got %v, want %v", fields, want)
+	}
+}
@@ -0,0 +1,302 @@
+package events_test
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"foodstore/events"
+)
+
+var update = flag.Bool("update", false, "rewrite the testdata of the current version")
+
+var (
+	createdAt  = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	capturedAt = time.Date(2026, 1, 1, 12, 5, 0, 0, time.UTC)
+	refundedAt = time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)
+)
+
+// fixtures are the events stored in testdata, in every version.
+var fixtures = []struct {
+	eventType  string
+	occurredAt time.Time
+	msg        proto.Message
+	// v0 is what the version 0 payload decodes to when it lacks fields of
+	// msg; noV0 marks types that had no version 0.
+	v0   proto.Message
+	noV0 bool
+}{
+	{
+		eventType:  events.TypeOrderCreated,
+		occurredAt: createdAt,
+		msg: &events.OrderCreated{
+			OrderId: "665f1c2b9a1e4b3c2d1e0f10",
+			UserId:  "665f1c2b9a1e4b3c2d1e0f01",
+			ItemIds: []string{"m1", "m1", "m2"},
+			Lines: []*events.OrderLine{
+				{MenuItemId: "m1", Name: "Burger", Quantity: 2, UnitPrice: 7.99, LineTotal: 15.98},
+				{MenuItemId: "m2", Name: "Fries", Quantity: 1, UnitPrice: 7.99, LineTotal: 7.99},
+			},
+			Total:     23.97,
+			CreatedAt: timestamppb.New(createdAt),
+		},
+		v0: &events.OrderCreated{
+			OrderId:   "665f1c2b9a1e4b3c2d1e0f10",
+			UserId:    "665f1c2b9a1e4b3c2d1e0f01",
+			ItemIds:   []string{"m1", "m1", "m2"},
+			Total:     23.97,
+			CreatedAt: timestamppb.New(createdAt),
+		},
+	},
+	{
+		eventType:  events.TypeOrderStatusChanged,
+		occurredAt: capturedAt,
+		msg: &events.OrderStatusChanged{
+			OrderId:    "665f1c2b9a1e4b3c2d1e0f10",
+			UserId:     "665f1c2b9a1e4b3c2d1e0f01",
+			FromStatus: "Pending",
+			ToStatus:   "Confirmed",
+			ChangedBy:  "payment-service",
+			Total:      23.97,
+		},
+		noV0: true,
+	},
+	{
+		eventType:  events.TypeOrderUpdated,
+		occurredAt: capturedAt,
+		msg: &events.OrderUpdated{
+			OrderId: "665f1c2b9a1e4b3c2d1e0f10",
+			UserId:  "665f1c2b9a1e4b3c2d1e0f01",
+			Before: &events.OrderSnapshot{
+				Status:  "Pending",
+				ItemIds: []string{"m1"},
+				Lines:   []*events.OrderLine{{MenuItemId: "m1", Name: "Burger", Quantity: 1, UnitPrice: 7.99, LineTotal: 7.99}},
+				Total:   7.99,
+			},
+			After: &events.OrderSnapshot{
+				Status:  "Pending",
+				ItemIds: []string{"m1", "m1"},
+				Lines:   []*events.OrderLine{{MenuItemId: "m1", Name: "Burger", Quantity: 2, UnitPrice: 7.99, LineTotal: 15.98}},
+				Total:   15.98,
+			},
+			UpdatedBy: "admin1",
+		},
+		noV0: true,
+	},
+	{
+		eventType:  events.TypeOrderCancelled,
+		occurredAt: capturedAt,
+		msg: &events.OrderCancelled{
+			OrderId:        "665f1c2b9a1e4b3c2d1e0f10",
+			UserId:         "665f1c2b9a1e4b3c2d1e0f01",
+			PreviousStatus: "Pending",
+			CancelledBy:    "665f1c2b9a1e4b3c2d1e0f01",
+			Total:          23.97,
+		},
+		noV0: true,
+	},
+	{
+		eventType:  events.TypePaymentCaptured,
+		occurredAt: capturedAt,
+		msg: &events.PaymentCaptured{
+			PaymentId: "pay1",
+			OrderId:   "665f1c2b9a1e4b3c2d1e0f10",
+			UserId:    "665f1c2b9a1e4b3c2d1e0f01",
+			Amount:    2397,
+			Currency:  "USD",
+		},
+	},
+	{
+		eventType:  events.TypePaymentRefunded,
+		occurredAt: refundedAt,
+		msg: &events.PaymentRefunded{
+			PaymentId:     "pay1",
+			OrderId:       "665f1c2b9a1e4b3c2d1e0f10",
+			UserId:        "665f1c2b9a1e4b3c2d1e0f01",
+			Amount:        500,
+			TotalRefunded: 500,
+			Currency:      "USD",
+			Reason:        "cold food",
+		},
+	},
+}
+
+func newMessage(t *testing.T, like proto.Message) proto.Message {
+	t.Helper()
+	return like.ProtoReflect().New().Interface()
+}
+
+func TestDecode_Version0(t *testing.T) {
+	for _, f := range fixtures {
+		if f.noV0 {
+			continue
+		}
+		want := f.v0
+		if want == nil {
+			want = f.msg
+		}
+		t.Run(f.eventType, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", "v0", f.eventType+".json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			env, err := events.Decode(f.eventType, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if env.GetVersion() != 0 || env.GetType() != f.eventType {
+				t.Errorf("envelope = %v", env)
+			}
+			if !env.GetOccurredAt().AsTime().Equal(f.occurredAt) {
+				t.Errorf("occurred at = %v, want %v", env.GetOccurredAt().AsTime(), f.occurredAt)
+			}
+			got := newMessage(t, f.msg)
+			if err := env.Open(got); err != nil {
+				t.Fatal(err)
+			}
+			if !proto.Equal(got, want) {
+				t.Errorf("event = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+// TestDecode_Version1 reads envelopes written by version 1. They must keep
+// decoding after the schema changes; run with -update only when adding a
+// version, into a new directory.
+func TestDecode_Version1(t *testing.T) {
+	for _, f := range fixtures {
+		t.Run(f.eventType, func(t *testing.T) {
+			path := filepath.Join("testdata", "v1", f.eventType+".pb")
+			if *update {
+				writeFixture(t, path, f.eventType, f.msg, f.occurredAt)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			env, err := events.Decode(f.eventType, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if env.GetVersion() != 1 || env.GetEventId() != "evt-"+f.eventType || env.GetCorrelationId() != "req-1" {
+				t.Errorf("envelope = %v", env)
+			}
+			if !env.GetOccurredAt().AsTime().Equal(f.occurredAt) {
+				t.Errorf("occurred at = %v, want %v", env.GetOccurredAt().AsTime(), f.occurredAt)
+			}
+			got := newMessage(t, f.msg)
+			if err := env.Open(got); err != nil {
+				t.Fatal(err)
+			}
+			if !proto.Equal(got, f.msg) {
+				t.Errorf("event = %v, want %v", got, f.msg)
+			}
+		})
+	}
+}
+
+func writeFixture(t *testing.T, path, eventType string, msg proto.Message, occurredAt time.Time) {
+	t.Helper()
+	env, err := events.New(eventType, msg, "req-1", occurredAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.EventId = "evt-" + eventType
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMarshal_RoundTrip(t *testing.T) {
+	for _, f := range fixtures {
+		data, err := events.Marshal(f.eventType, f.msg, "req-1", f.occurredAt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		env, err := events.Decode(f.eventType, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if env.GetEventId() == "" || env.GetVersion() != events.Version {
+			t.Errorf("%s: envelope = %v", f.eventType, env)
+		}
+		got := newMessage(t, f.msg)
+		if err := env.Open(got); err != nil {
+			t.Fatal(err)
+		}
+		if !proto.Equal(got, f.msg) {
+			t.Errorf("%s: event = %v, want %v", f.eventType, got, f.msg)
+		}
+	}
+}
+
+// A newer publisher may add fields; older consumers skip them.
+func TestDecode_IgnoresFieldsFromNewerVersions(t *testing.T) {
+	f := fixtures[0]
+	env, err := events.New(f.eventType, f.msg, "req-1", f.occurredAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.Version = 2
+	env.Data = protowire.AppendString(protowire.AppendTag(env.Data, 99, protowire.BytesType), "new field")
+	data, err := proto.Marshal(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = protowire.AppendVarint(protowire.AppendTag(data, 42, protowire.VarintType), 7)
+
+	decoded, err := events.Decode(f.eventType, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got events.OrderCreated
+	if err := decoded.Open(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.GetOrderId() != "665f1c2b9a1e4b3c2d1e0f10" || len(got.GetLines()) != 2 {
+		t.Errorf("event = %v", &got)
+	}
+}
+
+func TestDecode_RejectsMismatchedTypes(t *testing.T) {
+	data, err := events.Marshal(events.TypePaymentCaptured, &events.PaymentCaptured{OrderId: "o1"}, "", capturedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := events.Decode(events.TypeOrderCreated, data); !errors.Is(err, events.ErrTypeMismatch) {
+		t.Errorf("decode on wrong subject: err = %v", err)
+	}
+
+	env, err := events.Decode(events.TypePaymentCaptured, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := env.Open(&events.OrderCreated{}); !errors.Is(err, events.ErrTypeMismatch) {
+		t.Errorf("open as wrong message: err = %v", err)
+	}
+
+	if _, err := events.New(events.TypeOrderCreated, &events.PaymentCaptured{}, "", capturedAt); !errors.Is(err, events.ErrTypeMismatch) {
+		t.Errorf("new with wrong message: err = %v", err)
+	}
+}
+
+func TestDecode_Malformed(t *testing.T) {
+	if _, err := events.Decode(events.TypeOrderCreated, []byte(`{"orderId":`)); err == nil {
+		t.Error("truncated JSON decoded")
+	}
+	if _, err := events.Decode(events.TypeOrderCreated, []byte{0x0a, 0xff}); err == nil {
+		t.Error("truncated envelope decoded")
+	}
+}
@@ -0,0 +1,5 @@
+module foodstore/events
+
+go 1.23.4
+
+require google.golang.org/protobuf v1.36.6
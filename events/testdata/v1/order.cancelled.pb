@@ -0,0 +1,3 @@
+
+evt-order.cancelledorder.cancelled"*req-12`
+665f1c2b9a1e4b3c2d1e0f10665f1c2b9a1e4b3c2d1e0f01Pending"665f1c2b9a1e4b3c2d1e0f011Q7@
\ No newline at end of file
@@ -0,0 +1,5 @@
+
+evt-order.createdorder.created"*req-12
+665f1c2b9a1e4b3c2d1e0f10665f1c2b9a1e4b3c2d1e0f01m1m1m2" 
+m1Burger!(\@)(\/@"
+m2Fries!(\@)(\@)Q7@2
\ No newline at end of file
@@ -0,0 +1,3 @@
+
+evt-order.status_changedorder.status_changed"*req-12b
+665f1c2b9a1e4b3c2d1e0f10665f1c2b9a1e4b3c2d1e0f01Pending"	Confirmed*payment-service1Q7@
\ No newline at end of file
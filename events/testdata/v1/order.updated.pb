@@ -0,0 +1,7 @@
+
+evt-order.updatedorder.updated"*req-12
+665f1c2b9a1e4b3c2d1e0f10665f1c2b9a1e4b3c2d1e0f018
+Pendingm1 
+m1Burger!(\@)(\@!(\@"<
+Pendingm1m1 
+m1Burger!(\@)(\/@!(\/@*admin1
\ No newline at end of file
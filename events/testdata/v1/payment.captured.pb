@@ -0,0 +1,3 @@
+
+evt-payment.capturedpayment.captured"*req-12B
+pay1665f1c2b9a1e4b3c2d1e0f10665f1c2b9a1e4b3c2d1e0f01 *USD
\ No newline at end of file
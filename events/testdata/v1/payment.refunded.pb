@@ -0,0 +1,3 @@
+
+evt-payment.refundedpayment.refunded"*req-12P
+pay1665f1c2b9a1e4b3c2d1e0f10665f1c2b9a1e4b3c2d1e0f01 (2USD:	cold food
\ No newline at end of file
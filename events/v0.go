@@ -0,0 +1,112 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// The version 0 payloads, as OrderService and Payment_service sent them as
+// JSON before envelopes existed.
+
+type orderCreatedV0 struct {
+	OrderID   string   `json:"orderId"`
+	UserID    string   `json:"userId"`
+	Items     []string `json:"items"`
+	Total     float64  `json:"total"`
+	CreatedAt string   `json:"createdAt"`
+}
+
+type paymentCapturedV0 struct {
+	PaymentID  string `json:"paymentId"`
+	OrderID    string `json:"orderId"`
+	UserID     string `json:"userId"`
+	Amount     int64  `json:"amount"`
+	Currency   string `json:"currency"`
+	CapturedAt string `json:"capturedAt"`
+}
+
+type paymentRefundedV0 struct {
+	PaymentID     string `json:"paymentId"`
+	OrderID       string `json:"orderId"`
+	UserID        string `json:"userId"`
+	Amount        int64  `json:"amount"`
+	TotalRefunded int64  `json:"totalRefunded"`
+	Currency      string `json:"currency"`
+	Reason        string `json:"reason"`
+	RefundedAt    string `json:"refundedAt"`
+}
+
+// decodeV0 converts a version 0 payload to an envelope. Such payloads carry
+// no event id, and their correlation id only travelled in a message header.
+func decodeV0(subject string, data []byte) (*Envelope, error) {
+	var (
+		msg        proto.Message
+		occurredAt string
+	)
+	switch subject {
+	case TypeOrderCreated:
+		var v0 orderCreatedV0
+		if err := json.Unmarshal(data, &v0); err != nil {
+			return nil, fmt.Errorf("decode %s v0: %w", subject, err)
+		}
+		evt := &OrderCreated{
+			OrderId: v0.OrderID,
+			UserId:  v0.UserID,
+			ItemIds: v0.Items,
+			Total:   v0.Total,
+		}
+		if t, ok := parseTime(v0.CreatedAt); ok {
+			evt.CreatedAt = timestamppb.New(t)
+		}
+		msg, occurredAt = evt, v0.CreatedAt
+	case TypePaymentCaptured:
+		var v0 paymentCapturedV0
+		if err := json.Unmarshal(data, &v0); err != nil {
+			return nil, fmt.Errorf("decode %s v0: %w", subject, err)
+		}
+		msg = &PaymentCaptured{
+			PaymentId: v0.PaymentID,
+			OrderId:   v0.OrderID,
+			UserId:    v0.UserID,
+			Amount:    v0.Amount,
+			Currency:  v0.Currency,
+		}
+		occurredAt = v0.CapturedAt
+	case TypePaymentRefunded:
+		var v0 paymentRefundedV0
+		if err := json.Unmarshal(data, &v0); err != nil {
+			return nil, fmt.Errorf("decode %s v0: %w", subject, err)
+		}
+		msg = &PaymentRefunded{
+			PaymentId:     v0.PaymentID,
+			OrderId:       v0.OrderID,
+			UserId:        v0.UserID,
+			Amount:        v0.Amount,
+			TotalRefunded: v0.TotalRefunded,
+			Currency:      v0.Currency,
+			Reason:        v0.Reason,
+		}
+		occurredAt = v0.RefundedAt
+	default:
+		return nil, fmt.Errorf("no version 0 payload is defined for %s", subject)
+	}
+
+	payload, err := proto.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	env := &Envelope{Type: subject, Version: 0, Data: payload}
+	if t, ok := parseTime(occurredAt); ok {
+		env.OccurredAt = timestamppb.New(t)
+	}
+	return env, nil
+}
+
+func parseTime(s string) (time.Time, bool) {
+	t, err := time.Parse(time.RFC3339, s)
+	return t, err == nil
+}
@@ -0,0 +1,101 @@
+package logging
+
+import (
+	"encoding/json"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// Attribute keys whose values are always dropped, compared without case,
+// underscores or dashes.
+var secretKeys = []string{"password", "passwd", "secret", "token", "authorization", "cookie", "apikey", "reseturl", "smtppass"}
+
+var (
+	jwtPattern    = regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+	bcryptPattern = regexp.MustCompile(`\$2[abxy]?\$\d{2}\$[./A-Za-z0-9]{53}`)
+	bearerPattern = regexp.MustCompile(`(?i)\bbearer\s+[^\s"]+`)
+	labelled      = regexp.MustCompile(`(?i)\b(password|passwd|secret|token)(\s*[:=]\s*)[^\s,;"]+`)
+	tokenParam    = regexp.MustCompile(`(?i)([?&](?:token|access_token|refresh_token)=)[^&\s"]+`)
+	emailPattern  = regexp.MustCompile(`([A-Za-z0-9._%+-])[A-Za-z0-9._%+-]*@([A-Za-z0-9.-]+\.[A-Za-z]{2,})`)
+)
+
+func isSecretKey(key string) bool {
+	k := strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(key))
+	for _, s := range secretKeys {
+		if strings.Contains(k, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Scrub removes secrets that can be recognised by their shape from s: JWTs,
+// bcrypt hashes, bearer credentials, token query parameters and values
+// written as "password: ..." or "token=...". Emails keep
+// their first letter and domain only.
+func Scrub(s string) string {
+	s = jwtPattern.ReplaceAllString(s, redacted)
+	s = bcryptPattern.ReplaceAllString(s, redacted)
+	s = bearerPattern.ReplaceAllString(s, "Bearer "+redacted)
+	s = tokenParam.ReplaceAllString(s, "${1}"+redacted)
+	s = labelled.ReplaceAllString(s, "${1}${2}"+redacted)
+	return emailPattern.ReplaceAllString(s, "${1}***@${2}")
+}
+
+func redactAttr(groups []string, a slog.Attr) slog.Attr {
+	if isSecretKey(a.Key) {
+		return slog.String(a.Key, redacted)
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, Scrub(a.Value.String()))
+	case slog.KindAny:
+		v := a.Value.Any()
+		if err, ok := v.(error); ok {
+			return slog.String(a.Key, Scrub(err.Error()))
+		}
+		return slog.Any(a.Key, redactValue(v))
+	}
+	return a
+}
+
+// redactValue applies the key and value rules to the fields of structs,
+// maps and slices by going through their JSON form, which is how the handler
+// would print them anyway.
+func redactValue(v any) any {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return redacted
+	}
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return redacted
+	}
+	return redactJSON(generic)
+}
+
+func redactJSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, field := range v {
+			if isSecretKey(k) {
+				v[k] = redacted
+			} else {
+				v[k] = redactJSON(field)
+			}
+		}
+		return v
+	case []any:
+		for i := range v {
+			v[i] = redactJSON(v[i])
+		}
+		return v
+	case string:
+		return Scrub(v)
+	}
+	return v
+}